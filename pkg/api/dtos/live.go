@@ -1,6 +1,10 @@
 package dtos
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
 
 type LivePublishCmd struct {
 	Channel string          `json:"channel"`
@@ -9,3 +13,7 @@ type LivePublishCmd struct {
 
 type LivePublishResponse struct {
 }
+
+type LiveChannelRuleCmd struct {
+	Settings *simplejson.Json `json:"settings"`
+}
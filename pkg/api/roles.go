@@ -23,6 +23,7 @@ const (
 	ScopeProvisionersPlugins       = "provisioners:plugins"
 	ScopeProvisionersDatasources   = "provisioners:datasources"
 	ScopeProvisionersNotifications = "provisioners:notifications"
+	ScopeProvisionersAlerting      = "provisioners:alerting"
 
 	ScopeDatasourcesAll = `datasources:*`
 	ScopeDatasourceID   = `datasources:id:{{ index . ":id" }}`
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/ruletest"
+)
+
+func (cmd Command) testAlertRules(c utils.CommandLine) error {
+	filenames := c.Args().Slice()
+	if len(filenames) == 0 {
+		return errors.New("missing test file argument")
+	}
+
+	failed := 0
+	for _, filename := range filenames {
+		result, err := ruletest.RunFile(filename)
+		if err != nil {
+			logger.Errorf("%s %s: %v\n\n", color.RedString("✗"), filename, err)
+			failed++
+			continue
+		}
+		if result.Passed() {
+			logger.Infof("%s %s\n", color.GreenString("✔"), filename)
+			continue
+		}
+		logger.Infof("%s %s\n", color.RedString("✗"), filename)
+		for _, f := range result.Failures {
+			logger.Infof("  %s\n", f)
+		}
+		logger.Info("\n")
+		failed++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test files failed", failed, len(filenames))
+	}
+	return nil
+}
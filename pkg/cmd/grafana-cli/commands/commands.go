@@ -61,6 +61,12 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
+func runAlertingCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		return command(&utils.ContextCommandLine{Context: context})
+	}
+}
+
 func runCueCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		return command(&utils.ContextCommandLine{Context: context})
@@ -133,6 +139,31 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "import-legacy-alerts",
+				Usage:  "Imports the legacy dashboard alerts of the selected dashboards and folders into unified alerting. Alerts already imported are skipped. Safe to execute multiple times.",
+				Action: runDbCommand(datamigrations.ImportLegacyAlerts),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization whose alerts are imported",
+						Value: 1,
+					},
+					&cli.StringSliceFlag{
+						Name:  "dashboard",
+						Usage: "UID of a dashboard whose alerts are imported, can be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "folder",
+						Usage: "UID of a folder whose dashboards' alerts are imported, can be repeated. The alerts of every dashboard are imported if there are neither dashboards nor folders",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Report what the import would change, without changing anything",
+						Value: false,
+					},
+				},
+			},
 		},
 	},
 }
@@ -171,6 +202,18 @@ so must be recompiled to validate newly-added CUE files.`,
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:      "test",
+		Usage:     "run unit tests of Grafana managed alert rules",
+		ArgsUsage: "<test file>...",
+		Action:    runAlertingCommand(cmd.testAlertRules),
+		Description: `test evaluates the rule groups of each test file against the synthetic
+input series of its tests, and checks the alerts at the given times. The
+rules are evaluated without their data sources.`,
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -187,4 +230,9 @@ var Commands = []*cli.Command{
 		Usage:       "Cue validation commands",
 		Subcommands: cueCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
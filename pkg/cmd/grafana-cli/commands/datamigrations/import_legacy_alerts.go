@@ -0,0 +1,54 @@
+package datamigrations
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+)
+
+// ImportLegacyAlerts imports the legacy dashboard alerts of the selected dashboards and folders
+// into unified alerting. The alerts already imported are skipped, so it is safe to execute multiple times.
+func ImportLegacyAlerts(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	cmd := ualert.ImportCommand{
+		OrgID:         int64(c.Int("org-id")),
+		DashboardUIDs: c.StringSlice("dashboard"),
+		FolderUIDs:    c.StringSlice("folder"),
+		DryRun:        c.Bool("dry-run"),
+	}
+	report, err := sqlStore.ImportDashboardAlerts(context.Background(), cmd)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("\n")
+	if report.DryRun {
+		logger.Infof("%s Dry run, nothing is changed\n\n", color.YellowString("!"))
+	}
+	for _, r := range report.Rules {
+		switch r.Status {
+		case ualert.ImportStatusCreated:
+			logger.Infof("%s Alert %q (id %d) of dashboard %s imported as rule %s in folder %s\n", color.GreenString("✔"), r.AlertName, r.AlertID, r.DashboardUID, r.RuleUID, r.FolderUID)
+		case ualert.ImportStatusSkipped:
+			logger.Infof("%s Alert %q (id %d) of dashboard %s already imported as rule %s\n", color.GreenString("✔"), r.AlertName, r.AlertID, r.DashboardUID, r.RuleUID)
+		case ualert.ImportStatusFailed:
+			logger.Errorf("%s Alert %q (id %d) of dashboard %s cannot be imported: %s\n", color.RedString("✗"), r.AlertName, r.AlertID, r.DashboardUID, r.Error)
+		}
+	}
+	for _, f := range report.CreatedFolders {
+		logger.Infof("%s Folder %q created\n", color.GreenString("✔"), f)
+	}
+	for _, cp := range report.CreatedContactPoints {
+		logger.Infof("%s Contact point %q created\n", color.GreenString("✔"), cp)
+	}
+
+	logger.Info("\n")
+	logger.Infof("%d alerts imported, %d already imported, %d failed\n",
+		report.Count(ualert.ImportStatusCreated), report.Count(ualert.ImportStatusSkipped), report.Count(ualert.ImportStatusFailed))
+
+	return nil
+}
@@ -2,11 +2,14 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -71,17 +74,30 @@ func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.
 type ReduceCommand struct {
 	Reducer     string
 	VarToReduce string
+	Mapper      mathexp.ReduceMapper
 	refID       string
 }
 
+// ReduceSettings is the JSON model for the optional settings of the reduce command.
+type ReduceSettings struct {
+	// Mode controls how null and NaN values are handled before reducing:
+	// "" (default) leaves them in place, "dropNN" drops them, and "replaceNN"
+	// replaces them with ReplaceWithValue.
+	Mode             string   `json:"mode"`
+	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+}
+
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
-	// TODO: validate reducer here, before execution
+func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	if !mathexp.ValidReduceFunc(reducer) {
+		return nil, fmt.Errorf("reducer '%v' for refId %v is not a valid reducer", reducer, refID)
+	}
 	return &ReduceCommand{
 		Reducer:     reducer,
 		VarToReduce: varToReduce,
+		Mapper:      mapper,
 		refID:       refID,
-	}
+	}, nil
 }
 
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
@@ -105,7 +121,31 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	var mapper mathexp.ReduceMapper
+	if rawSettings, ok := rn.Query["settings"]; ok {
+		settingsJSON, err := json.Marshal(rawSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal reduce settings for refId %v: %w", rn.RefID, err)
+		}
+		var settings ReduceSettings
+		if err := json.Unmarshal(settingsJSON, &settings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reduce settings for refId %v: %w", rn.RefID, err)
+		}
+		switch settings.Mode {
+		case "":
+		case "dropNN":
+			mapper = mathexp.DropNonNumber{}
+		case "replaceNN":
+			if settings.ReplaceWithValue == nil {
+				return nil, fmt.Errorf("reduce mode 'replaceNN' for refId %v requires a replaceWithValue", rn.RefID)
+			}
+			mapper = mathexp.ReplaceNonNumberWithValue{Value: *settings.ReplaceWithValue}
+		default:
+			return nil, fmt.Errorf("reduce mode '%v' for refId %v is not supported", settings.Mode, rn.RefID)
+		}
+	}
+
+	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -123,7 +163,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.Mapper)
 		if err != nil {
 			return newRes, err
 		}
@@ -226,6 +266,151 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// ThresholdCommand is an expression command that compares each number of a
+// NumberSet against a threshold or range. Each output number is 1 when the
+// condition is met, 0 when it is not, and null when the input is null.
+//
+// If a RecoveryEvaluator is set, numbers whose labels are in LoadedDimensions
+// (the series that are currently firing) keep the value 1 until the recovery
+// condition is met, so a value oscillating around the threshold does not flap.
+type ThresholdCommand struct {
+	ReferenceVar      string
+	Evaluator         classic.Evaluator
+	RecoveryEvaluator classic.Evaluator
+	LoadedDimensions  []data.Labels
+	refID             string
+}
+
+// ThresholdCommandJSON is the JSON model for the threshold command.
+type ThresholdCommandJSON struct {
+	Expression        string                     `json:"expression"`
+	Evaluator         classic.ConditionEvalJSON  `json:"evaluator"`
+	RecoveryEvaluator *classic.ConditionEvalJSON `json:"recoveryEvaluator,omitempty"`
+	LoadedDimensions  []data.Labels              `json:"loadedDimensions,omitempty"`
+}
+
+// NewThresholdCommand creates a new ThresholdCommand.
+func NewThresholdCommand(refID, referenceVar string, evaluator, recoveryEvaluator classic.Evaluator, loadedDimensions []data.Labels) *ThresholdCommand {
+	return &ThresholdCommand{
+		ReferenceVar:      referenceVar,
+		Evaluator:         evaluator,
+		RecoveryEvaluator: recoveryEvaluator,
+		LoadedDimensions:  loadedDimensions,
+		refID:             refID,
+	}
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold command for refId %v: %w", rn.RefID, err)
+	}
+	var tj ThresholdCommandJSON
+	if err := json.Unmarshal(jsonFromM, &tj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal threshold command for refId %v: %w", rn.RefID, err)
+	}
+
+	referenceVar := strings.TrimPrefix(tj.Expression, "$")
+	if referenceVar == "" {
+		return nil, fmt.Errorf("no variable specified to apply the threshold to for refId %v", rn.RefID)
+	}
+
+	evaluator, err := newThresholdEvaluator(tj.Evaluator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold evaluator for refId %v: %w", rn.RefID, err)
+	}
+
+	var recoveryEvaluator classic.Evaluator
+	if tj.RecoveryEvaluator != nil {
+		recoveryEvaluator, err = newThresholdEvaluator(*tj.RecoveryEvaluator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold recovery evaluator for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	return NewThresholdCommand(rn.RefID, referenceVar, evaluator, recoveryEvaluator, tj.LoadedDimensions), nil
+}
+
+// newThresholdEvaluator returns the classic condition evaluator for model,
+// limited to the evaluator types that compare a value.
+func newThresholdEvaluator(model classic.ConditionEvalJSON) (classic.Evaluator, error) {
+	switch model.Type {
+	case "gt", "lt", "within_range", "outside_range":
+		return classic.NewAlertEvaluator(model)
+	default:
+		return nil, fmt.Errorf("evaluator type '%v' is not supported, must be one of gt, lt, within_range or outside_range", model.Type)
+	}
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	loaded := make(map[string]struct{}, len(tc.LoadedDimensions))
+	for _, l := range tc.LoadedDimensions {
+		loaded[l.String()] = struct{}{}
+	}
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		var num mathexp.Number
+		switch v := val.(type) {
+		case mathexp.Number:
+			num = v
+		case mathexp.Scalar:
+			num = mathexp.NewNumber("", nil)
+			num.SetValue(v.GetFloat64Value())
+		default:
+			return newRes, fmt.Errorf("can only apply a threshold to numbers, got type %v; reduce the series first", val.Type())
+		}
+
+		var labels data.Labels
+		if num.GetLabels() != nil {
+			labels = num.GetLabels().Copy()
+		}
+		result := mathexp.NewNumber(tc.refID, labels)
+		if num.GetFloat64Value() == nil {
+			result.SetValue(nil)
+			newRes.Values = append(newRes.Values, result)
+			continue
+		}
+
+		firing := tc.Evaluator.Eval(num)
+		if _, ok := loaded[labels.String()]; ok && tc.RecoveryEvaluator != nil {
+			firing = !tc.RecoveryEvaluator.Eval(num)
+		}
+
+		var f float64
+		if firing {
+			f = 1
+		}
+		result.SetValue(&f)
+		newRes.Values = append(newRes.Values, result)
+	}
+	return newRes, nil
+}
+
+// SetLoadedDimensions returns model with the labels of the currently firing
+// series set as the loaded dimensions, if model is a threshold command.
+// Models of other command types, and data source queries, are returned unchanged.
+func SetLoadedDimensions(model json.RawMessage, loadedDimensions []data.Labels) (json.RawMessage, error) {
+	var props map[string]interface{}
+	if err := json.Unmarshal(model, &props); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if t, ok := props["type"].(string); !ok || t != TypeThreshold.String() {
+		return model, nil
+	}
+	props["loadedDimensions"] = loadedDimensions
+	return json.Marshal(props)
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -240,6 +425,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for a threshold expression.
+	TypeThreshold
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +439,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
 	default:
 		return "unknown"
 	}
@@ -268,6 +457,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,126 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdCommand(t *testing.T) {
+	number := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(f)
+		return n
+	}
+	float := func(f float64) *float64 {
+		return &f
+	}
+
+	tests := []struct {
+		name             string
+		query            map[string]interface{}
+		input            mathexp.Values
+		loadedDimensions []data.Labels
+		unmarshalErrIs   require.ErrorAssertionFunc
+		expected         []*float64
+	}{
+		{
+			name: "gt threshold",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"evaluator":  map[string]interface{}{"type": "gt", "params": []float64{80}},
+			},
+			input: mathexp.Values{
+				number(data.Labels{"host": "a"}, float(90)),
+				number(data.Labels{"host": "b"}, float(70)),
+				number(data.Labels{"host": "c"}, nil),
+			},
+			unmarshalErrIs: require.NoError,
+			expected:       []*float64{float(1), float(0), nil},
+		},
+		{
+			name: "outside_range threshold",
+			query: map[string]interface{}{
+				"expression": "A",
+				"evaluator":  map[string]interface{}{"type": "outside_range", "params": []float64{10, 20}},
+			},
+			input: mathexp.Values{
+				number(data.Labels{"host": "a"}, float(15)),
+				number(data.Labels{"host": "b"}, float(25)),
+			},
+			unmarshalErrIs: require.NoError,
+			expected:       []*float64{float(0), float(1)},
+		},
+		{
+			name: "recovery threshold keeps loaded dimensions firing",
+			query: map[string]interface{}{
+				"expression":        "$A",
+				"evaluator":         map[string]interface{}{"type": "gt", "params": []float64{80}},
+				"recoveryEvaluator": map[string]interface{}{"type": "lt", "params": []float64{70}},
+			},
+			input: mathexp.Values{
+				number(data.Labels{"host": "a"}, float(75)),
+				number(data.Labels{"host": "b"}, float(75)),
+				number(data.Labels{"host": "c"}, float(65)),
+			},
+			loadedDimensions: []data.Labels{{"host": "a"}, {"host": "c"}},
+			unmarshalErrIs:   require.NoError,
+			expected:         []*float64{float(1), float(0), float(0)},
+		},
+		{
+			name: "no_value evaluator is not supported",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"evaluator":  map[string]interface{}{"type": "no_value", "params": []float64{}},
+			},
+			unmarshalErrIs: require.Error,
+		},
+		{
+			name: "missing expression",
+			query: map[string]interface{}{
+				"evaluator": map[string]interface{}{"type": "gt", "params": []float64{1}},
+			},
+			unmarshalErrIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if tt.loadedDimensions != nil {
+				query["loadedDimensions"] = tt.loadedDimensions
+			}
+			cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: query})
+			tt.unmarshalErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+
+			res, err := cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: tt.input}})
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tt.expected))
+			for i, v := range res.Values {
+				n, ok := v.(mathexp.Number)
+				require.True(t, ok)
+				require.Equal(t, tt.input[i].GetLabels(), n.GetLabels())
+				require.Equal(t, tt.expected[i], n.GetFloat64Value())
+			}
+		})
+	}
+}
+
+func TestSetLoadedDimensions(t *testing.T) {
+	dims := []data.Labels{{"host": "a"}}
+
+	model, err := SetLoadedDimensions([]byte(`{"type":"threshold","expression":"$A"}`), dims)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"threshold","expression":"$A","loadedDimensions":[{"host":"a"}]}`, string(model))
+
+	model, err = SetLoadedDimensions([]byte(`{"type":"math","expression":"$A > 1"}`), dims)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"math","expression":"$A > 1"}`, string(model))
+}
@@ -0,0 +1,197 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateGroup holds the items of a set that share the same labels
+// once the by() or without() clause of an aggregation is applied.
+type aggregateGroup struct {
+	labels data.Labels
+	values []Value
+}
+
+// walkAggregate executes an aggregation such as sum by(host) ($A) or topk(3, $A).
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	res, err := e.walk(node.Arg)
+	if err != nil {
+		return Results{}, err
+	}
+
+	groups, err := groupValues(node, res)
+	if err != nil {
+		return Results{}, err
+	}
+
+	if node.Param != nil {
+		param, err := e.walk(node.Param)
+		if err != nil {
+			return Results{}, err
+		}
+		k, err := scalarArg(node.Name, param)
+		if err != nil {
+			return Results{}, err
+		}
+		return e.rankGroups(node.Name, groups, int(k))
+	}
+
+	newRes := Results{Values: Values{}}
+	for _, g := range groups {
+		var value Value
+		switch g.values[0].(type) {
+		case Number:
+			value = e.aggregateNumbers(node.Name, g)
+		case Series:
+			value, err = e.aggregateSeries(node.Name, g)
+			if err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, value)
+	}
+	return newRes, nil
+}
+
+// groupValues splits the items of res into groups according to the by() or
+// without() clause of the aggregation. Groups are returned in the order in
+// which their first item appears in res. Without a clause all items are put
+// in a single group with no labels.
+func groupValues(node *parse.AggregateNode, res Results) ([]*aggregateGroup, error) {
+	groups := []*aggregateGroup{}
+	byKey := make(map[string]*aggregateGroup)
+	var valueType parse.ReturnType
+	for i, v := range res.Values {
+		switch v.(type) {
+		case Number, Series:
+		default:
+			return nil, fmt.Errorf("%s: can not aggregate type %v, expected numbers or series", node.Name, v.Type())
+		}
+		if i == 0 {
+			valueType = v.Type()
+		} else if v.Type() != valueType {
+			return nil, fmt.Errorf("%s: can not aggregate a mix of %v and %v", node.Name, valueType, v.Type())
+		}
+
+		labels := data.Labels{}
+		if node.Grouping != nil {
+			labels = filterLabels(v.GetLabels(), node.Grouping, !node.Without)
+		}
+		key := labels.String()
+		g, ok := byKey[key]
+		if !ok {
+			g = &aggregateGroup{labels: labels}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, v)
+	}
+	return groups, nil
+}
+
+func (e *State) aggregateNumbers(op string, g *aggregateGroup) Number {
+	fv := make([]float64, 0, len(g.values))
+	for _, v := range g.values {
+		if f := v.(Number).GetFloat64Value(); f != nil && !math.IsNaN(*f) {
+			fv = append(fv, *f)
+		}
+	}
+	n := NewNumber(e.RefID, g.labels)
+	n.SetValue(aggregateFloats(op, fv))
+	return n
+}
+
+// aggregateSeries aggregates the values of all the series in the group that
+// share the same time. The resulting series has a point for each time that is
+// present in any of the series.
+func (e *State) aggregateSeries(op string, g *aggregateGroup) (Series, error) {
+	pointsByTime := make(map[int64][]float64)
+	times := []time.Time{}
+	for _, v := range g.values {
+		s := v.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			points, ok := pointsByTime[t.UnixNano()]
+			if !ok {
+				times = append(times, t)
+			}
+			if f != nil && !math.IsNaN(*f) {
+				points = append(points, *f)
+			}
+			pointsByTime[t.UnixNano()] = points
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	newSeries := NewSeries(e.RefID, g.labels, len(times))
+	for i, t := range times {
+		if err := newSeries.SetPoint(i, t, aggregateFloats(op, pointsByTime[t.UnixNano()])); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// aggregateFloats applies the aggregation operator to the non-null, non-NaN
+// values of a group. Null is returned if there are no values, except for count.
+func aggregateFloats(op string, fv []float64) *float64 {
+	if op == "count" {
+		f := float64(len(fv))
+		return &f
+	}
+	if len(fv) == 0 {
+		return nil
+	}
+	f := fv[0]
+	for _, v := range fv[1:] {
+		switch op {
+		case "sum", "avg":
+			f += v
+		case "min":
+			f = math.Min(f, v)
+		case "max":
+			f = math.Max(f, v)
+		}
+	}
+	if op == "avg" {
+		f /= float64(len(fv))
+	}
+	return &f
+}
+
+// rankGroups keeps the k largest (topk) or smallest (bottomk) numbers of each
+// group. The numbers keep their own labels. Null and NaN values are dropped.
+func (e *State) rankGroups(op string, groups []*aggregateGroup, k int) (Results, error) {
+	newRes := Results{Values: Values{}}
+	for _, g := range groups {
+		numbers := make([]Number, 0, len(g.values))
+		for _, v := range g.values {
+			n, ok := v.(Number)
+			if !ok {
+				return newRes, fmt.Errorf("%s: can not rank type %v, reduce the series to numbers first", op, v.Type())
+			}
+			if f := n.GetFloat64Value(); f != nil && !math.IsNaN(*f) {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.SliceStable(numbers, func(i, j int) bool {
+			if op == "bottomk" {
+				return *numbers[i].GetFloat64Value() < *numbers[j].GetFloat64Value()
+			}
+			return *numbers[i].GetFloat64Value() > *numbers[j].GetFloat64Value()
+		})
+		for i := 0; i < k && i < len(numbers); i++ {
+			n := NewNumber(e.RefID, numbers[i].GetLabels())
+			n.SetValue(numbers[i].GetFloat64Value())
+			newRes.Values = append(newRes.Values, n)
+		}
+	}
+	return newRes, nil
+}
@@ -0,0 +1,354 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+)
+
+var hostNumbers = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("a", data.Labels{"host": "a", "dc": "east"}, float64Pointer(1)),
+			makeNumber("a", data.Labels{"host": "b", "dc": "east"}, float64Pointer(4)),
+			makeNumber("a", data.Labels{"host": "c", "dc": "west"}, float64Pointer(3)),
+			makeNumber("a", data.Labels{"host": "d", "dc": "west"}, nil),
+		},
+	},
+}
+
+func TestAggregateExpr(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "sum without grouping",
+			expr:      "sum($A)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{}, float64Pointer(8)),
+				},
+			},
+		},
+		{
+			name:      "sum by label",
+			expr:      "sum by(dc) ($A)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"dc": "east"}, float64Pointer(5)),
+					makeNumber("", data.Labels{"dc": "west"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:      "avg without label, grouping after the arguments",
+			expr:      "avg($A) without(host)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"dc": "east"}, float64Pointer(2.5)),
+					makeNumber("", data.Labels{"dc": "west"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:      "count by label counts non-null numbers",
+			expr:      "count by(dc) ($A)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"dc": "east"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"dc": "west"}, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:      "aggregation used in math",
+			expr:      "max by(dc) ($A) * 2",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"dc": "east"}, float64Pointer(8)),
+					makeNumber("", data.Labels{"dc": "west"}, float64Pointer(6)),
+				},
+			},
+		},
+		{
+			name:      "topk keeps the labels of the numbers",
+			expr:      "topk(2, $A)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "b", "dc": "east"}, float64Pointer(4)),
+					makeNumber("", data.Labels{"host": "c", "dc": "west"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:      "bottomk by label",
+			expr:      "bottomk by(dc) (1, $A)",
+			vars:      hostNumbers,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a", "dc": "east"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"host": "c", "dc": "west"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name: "sum and max drop NaN numbers",
+			expr: "sum($A) + max($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("a", data.Labels{"host": "a"}, float64Pointer(1)),
+						makeNumber("a", data.Labels{"host": "b"}, float64Pointer(math.NaN())),
+						makeNumber("a", data.Labels{"host": "c"}, float64Pointer(3)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{}, float64Pointer(7)),
+				},
+			},
+		},
+		{
+			name: "sum by label on series",
+			expr: "sum by(dc) ($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("a", data.Labels{"host": "a", "dc": "east"}, tp{
+							time.Unix(5, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(2),
+						}),
+						makeSeries("a", data.Labels{"host": "b", "dc": "east"}, tp{
+							time.Unix(5, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(15, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"dc": "east"}, tp{
+						time.Unix(5, 0), float64Pointer(4),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(15, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "avg on series drops NaN points",
+			expr: "avg($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("a", data.Labels{"host": "a"}, tp{
+							time.Unix(5, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(math.NaN()),
+						}),
+						makeSeries("a", data.Labels{"host": "b"}, tp{
+							time.Unix(5, 0), float64Pointer(3),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(math.NaN()),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{}, tp{
+						time.Unix(5, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(10, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name:      "topk on series should error",
+			expr:      "topk(1, $A)",
+			vars:      aSeries,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:     "topk without parameter should error",
+			expr:     "topk($A)",
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "aggregating a scalar should error",
+			expr:     "sum(1)",
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "multiple grouping clauses should error",
+			expr:     "sum by(dc) ($A) by(host)",
+			newErrIs: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if diff := cmp.Diff(tt.results, res, data.FrameTestCompareOptions()...); diff != "" {
+					t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingExpr(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name: "on pairs numbers with different label sets",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("a", data.Labels{"host": "a", "job": "node"}, float64Pointer(6)),
+						makeNumber("a", data.Labels{"host": "b", "job": "node"}, float64Pointer(8)),
+					},
+				},
+				"B": Results{
+					[]Value{
+						makeNumber("b", data.Labels{"host": "a", "table": "hosts"}, float64Pointer(2)),
+						makeNumber("b", data.Labels{"host": "b", "table": "hosts"}, float64Pointer(4)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name: "ignoring drops labels from the comparison",
+			expr: "$A > ignoring(instance) $B",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("a", data.Labels{"host": "a", "instance": "a:9100"}, float64Pointer(6)),
+					},
+				},
+				"B": Results{
+					[]Value{
+						makeNumber("b", data.Labels{"host": "a"}, float64Pointer(2)),
+						makeNumber("b", data.Labels{"host": "b"}, float64Pointer(4)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name: "on with several matches on one side should error",
+			expr: "$A + on(dc) $B",
+			vars: Vars{
+				"A": hostNumbers["A"],
+				"B": Results{
+					[]Value{
+						makeNumber("b", data.Labels{"dc": "east"}, float64Pointer(2)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name: "on is ignored when one side is a scalar",
+			expr: "$A + on(host) 1",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("a", data.Labels{"host": "a"}, float64Pointer(1)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if diff := cmp.Diff(tt.results, res, data.FrameTestCompareOptions()...); diff != "" {
+					t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -88,6 +88,8 @@ func (e *State) walk(node parse.Node) (res Results, err error) {
 		res, err = e.walkUnary(node)
 	case *parse.FuncNode:
 		res, err = e.walkFunc(node)
+	case *parse.AggregateNode:
+		res, err = e.walkAggregate(node)
 	default:
 		return res, fmt.Errorf("expr: can not walk node type: %s", node.Type())
 	}
@@ -242,6 +244,71 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchUnion creates Union objects for a binary operation with an on() or
+// ignoring() modifier. Items are paired when their labels are equal after
+// keeping only the labels listed in on(), or after removing the labels listed
+// in ignoring(), and the compared labels become the labels of the Union.
+// Unlike union, each item can be part of a single Union only, so an error
+// is returned if several items on the same side have the same compared labels.
+func matchUnion(aResults, bResults Results, matching *parse.Matching) ([]*Union, error) {
+	if containsScalar(aResults) || containsScalar(bResults) {
+		return union(aResults, bResults), nil
+	}
+	bByKey := make(map[string]Value, len(bResults.Values))
+	for _, b := range bResults.Values {
+		key := filterLabels(b.GetLabels(), matching.Labels, matching.On).String()
+		if _, ok := bByKey[key]; ok {
+			return nil, fmt.Errorf("multiple items on the right side of %s match the labels {%s}", matching, key)
+		}
+		bByKey[key] = b
+	}
+	unions := []*Union{}
+	aKeys := make(map[string]struct{}, len(aResults.Values))
+	for _, a := range aResults.Values {
+		labels := filterLabels(a.GetLabels(), matching.Labels, matching.On)
+		key := labels.String()
+		b, ok := bByKey[key]
+		if !ok {
+			continue
+		}
+		if _, ok := aKeys[key]; ok {
+			return nil, fmt.Errorf("multiple items on the left side of %s match the labels {%s}", matching, key)
+		}
+		aKeys[key] = struct{}{}
+		unions = append(unions, &Union{
+			Labels: labels,
+			A:      a,
+			B:      b,
+		})
+	}
+	return unions, nil
+}
+
+// filterLabels returns a copy of labels that holds only the given names if
+// keep is true, or all labels except the given names if keep is false.
+func filterLabels(labels data.Labels, names []string, keep bool) data.Labels {
+	listed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		listed[name] = struct{}{}
+	}
+	filtered := data.Labels{}
+	for k, v := range labels {
+		if _, ok := listed[k]; ok == keep {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+func containsScalar(res Results) bool {
+	for _, v := range res.Values {
+		if v.Type() == parse.TypeScalar {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -252,7 +319,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = matchUnion(ar, br, node.Matching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -510,6 +585,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 			v, err = e.walkUnary(t)
 		case *parse.BinaryNode:
 			v, err = e.walkBinary(t)
+		case *parse.AggregateNode:
+			v, err = e.walkAggregate(t)
 		default:
 			return res, fmt.Errorf("expr: unknown func arg type: %T", t)
 		}
@@ -1,8 +1,10 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
@@ -29,6 +31,41 @@ var builtins = map[string]parse.Func{
 		Return: parse.TypeScalar,
 		F:      null,
 	},
+	"round": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             round,
+	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"rate": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             rate,
+	},
+	"delta": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             delta,
+	},
+	"cumsum": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             cumsum,
+	},
+	"moving_avg": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingAvg,
+	},
+	"timeshift": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             timeshift,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -74,6 +111,235 @@ func null(e *State) Results {
 	return NewScalarResults(e.RefID, nil)
 }
 
+// round returns the nearest integer, rounding half away from zero, for each result in NumberSet, SeriesSet, or Scalar
+func round(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, math.Round)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clamp limits each result in NumberSet, SeriesSet, or Scalar to the range [min, max].
+func clamp(e *State, varSet Results, minSet Results, maxSet Results) (Results, error) {
+	newRes := Results{}
+	min, err := scalarArg("clamp", minSet)
+	if err != nil {
+		return newRes, err
+	}
+	max, err := scalarArg("clamp", maxSet)
+	if err != nil {
+		return newRes, err
+	}
+	if min > max {
+		return newRes, fmt.Errorf("clamp: min %v must not be greater than max %v", min, max)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(x float64) float64 {
+			return math.Max(min, math.Min(max, x))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// rate returns the per-second rate of change between consecutive points of each series in a SeriesSet.
+// The first point of each series is dropped. Numbers and Scalars have no rate of change, so NaN is returned.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, varSet, func(s Series) (Series, error) {
+		return seriesDiff(e, s, true)
+	}, nanFloat)
+}
+
+// delta returns the difference between consecutive points of each series in a SeriesSet.
+// The first point of each series is dropped. Numbers and Scalars have no difference, so NaN is returned.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, varSet, func(s Series) (Series, error) {
+		return seriesDiff(e, s, false)
+	}, nanFloat)
+}
+
+// cumsum returns the running total of each series in a SeriesSet. Null points stay null and
+// do not contribute to the total. Numbers and Scalars are returned unchanged.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				if err := newSeries.SetPoint(i, t, nil); err != nil {
+					return newSeries, err
+				}
+				continue
+			}
+			sum += *f
+			nF := sum
+			if err := newSeries.SetPoint(i, t, &nF); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	}, identityFloat)
+}
+
+// movingAvg returns, for each point of each series in a SeriesSet, the mean of the non-null
+// points in the trailing window of the given duration (e.g. "5m"). Numbers and Scalars are
+// returned unchanged.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_avg: failed to parse window %q: %w", rawWindow, err)
+	}
+	if window <= 0 {
+		return Results{}, fmt.Errorf("moving_avg: window must be greater than zero, got %q", rawWindow)
+	}
+	return perSeriesResults(e, varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		start := 0
+		var sum float64
+		var count int
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil {
+				sum += *f
+				count++
+			}
+			for ; start < i && !s.GetTime(start).After(t.Add(-window)); start++ {
+				if v := s.GetValue(start); v != nil {
+					sum -= *v
+					count--
+				}
+			}
+			var nF *float64
+			if count > 0 {
+				avg := sum / float64(count)
+				nF = &avg
+			}
+			if err := newSeries.SetPoint(i, t, nF); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	}, identityFloat)
+}
+
+// timeshift moves the timestamps of each series in a SeriesSet forward by the given
+// duration (e.g. "1d"), so the result lines up with the original data that much later.
+// Negative durations move timestamps backward. Numbers and Scalars are returned unchanged.
+func timeshift(e *State, varSet Results, rawShift string) (Results, error) {
+	shift, err := gtime.ParseDuration(rawShift)
+	if err != nil {
+		return Results{}, fmt.Errorf("timeshift: failed to parse duration %q: %w", rawShift, err)
+	}
+	return perSeriesResults(e, varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if err := newSeries.SetPoint(i, t.Add(shift), f); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	}, identityFloat)
+}
+
+// seriesDiff returns the difference between each point and the previous point of s.
+// If perSecond is true, the difference is divided by the seconds elapsed between the points,
+// and the point is null if no time elapsed.
+func seriesDiff(e *State, s Series, perSecond bool) (Series, error) {
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prevF := s.GetPoint(i - 1)
+		t, f := s.GetPoint(i)
+		var nF *float64
+		if prevF != nil && f != nil {
+			d := *f - *prevF
+			nF = &d
+			if perSecond {
+				// Points at the same time or out of order have no rate.
+				if elapsed := t.Sub(prevT).Seconds(); elapsed > 0 {
+					d /= elapsed
+				} else {
+					nF = nil
+				}
+			}
+		}
+		if err := newSeries.SetPoint(i-1, t, nF); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// scalarArg returns the value of a Scalar function argument.
+func scalarArg(funcName string, res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument, got %v values", funcName, len(res.Values))
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected a scalar argument, got %v", funcName, res.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument must not be null", funcName)
+	}
+	return *f, nil
+}
+
+func nanFloat(f *float64) *float64 {
+	nF := math.NaN()
+	return &nF
+}
+
+func identityFloat(f *float64) *float64 {
+	return f
+}
+
+func perSeriesResults(e *State, varSet Results, seriesF func(s Series) (Series, error), singleF func(f *float64) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, seriesF, singleF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perSeries applies seriesF to a Series. Numbers and Scalars have no time dimension,
+// so singleF is applied to their single value instead.
+func perSeries(e *State, val Value, seriesF func(s Series) (Series, error), singleF func(f *float64) *float64) (Value, error) {
+	var newVal Value
+	switch val.Type() {
+	case parse.TypeNumberSet:
+		n := NewNumber(e.RefID, val.GetLabels())
+		n.SetValue(singleF(val.(Number).GetFloat64Value()))
+		newVal = n
+	case parse.TypeScalar:
+		newVal = NewScalar(e.RefID, singleF(val.(Scalar).GetFloat64Value()))
+	case parse.TypeSeriesSet:
+		return seriesF(val.(Series))
+	default:
+		return nil, fmt.Errorf("can not apply the function to type %v", val.Type())
+	}
+
+	return newVal, nil
+}
+
 func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error) {
 	var newVal Value
 	switch val.Type() {
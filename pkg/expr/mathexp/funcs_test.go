@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFunc(t *testing.T) {
@@ -74,6 +77,259 @@ func TestFunc(t *testing.T) {
 			vars:     Vars{},
 			newErrIs: assert.Error,
 		},
+		{
+			name: "round on number",
+			expr: "round($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(-2.5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(-3))}},
+		},
+		{
+			name:      "clamp on scalar",
+			expr:      "clamp(12, 0, 10)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(10))}},
+		},
+		{
+			name: "clamp on series",
+			expr: "clamp($A, -1, 1)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(-2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(0.5),
+						}, tp{
+							time.Unix(15, 0), float64Pointer(3),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(-1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0.5),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name:      "clamp with min greater than max - should error",
+			expr:      "clamp(1, 10, 0)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "rate on series",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), nil,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(40),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(20, 0), nil,
+					}, tp{
+						time.Unix(30, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "rate on series with points at the same time",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(10, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(40),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), nil,
+					}, tp{
+						time.Unix(20, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name: "delta on series",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(25),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(20),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(-5),
+					}),
+				},
+			},
+		},
+		{
+			name: "cumsum on series",
+			expr: "cumsum($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), nil,
+						}, tp{
+							time.Unix(15, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), nil,
+					}, tp{
+						time.Unix(15, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
+		{
+			name: "moving_avg on series",
+			expr: `moving_avg($A, "20s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(4),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(6),
+						}, tp{
+							time.Unix(30, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(6),
+					}),
+				},
+			},
+		},
+		{
+			name:      "moving_avg with invalid window - should error",
+			expr:      `moving_avg($A, "foo")`,
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "timeshift on series",
+			expr: `timeshift($A, "1m")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", data.Labels{"host": "a"}, tp{
+							time.Unix(5, 0), float64Pointer(1),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"}, tp{
+						time.Unix(65, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -87,3 +343,17 @@ func TestFunc(t *testing.T) {
 		})
 	}
 }
+
+// stringValue is a Value of a type the functions do not support.
+type stringValue struct{ Scalar }
+
+func (stringValue) Type() parse.ReturnType { return parse.TypeString }
+
+func TestFuncOnUnsupportedType(t *testing.T) {
+	e, err := New("cumsum($A)")
+	require.NoError(t, err)
+	_, err = e.Execute("", Vars{
+		"A": Results{[]Value{stringValue{NewScalar("", float64Pointer(1))}}},
+	})
+	require.EqualError(t, err, "can not apply the function to type string")
+}
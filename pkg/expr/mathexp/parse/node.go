@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +63,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeAggregate is an aggregation over a set: sum by(host) ($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -77,6 +80,8 @@ func (t NodeType) String() string {
 		return "NodeString"
 	case NodeNumber:
 		return "NodeNumber"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -298,6 +303,23 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	Matching *Matching // Optional on() or ignoring() modifier, nil if not set.
+}
+
+// Matching holds the labels used to pair the items of the two sides of a
+// binary operation, set with on(label, ...) or ignoring(label, ...).
+type Matching struct {
+	On     bool // If true, only Labels are compared. Otherwise all labels except Labels are compared.
+	Labels []string
+}
+
+// String returns the string representation of the Matching.
+func (m *Matching) String() string {
+	name := "ignoring"
+	if m.On {
+		name = "on"
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(m.Labels, ", "))
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,11 +328,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -367,6 +395,84 @@ func (u *UnaryNode) Return() ReturnType {
 	return u.Arg.Return()
 }
 
+// AggregateNode holds an aggregation over the items of a set, for example
+// sum by(host) ($A) or topk(3, $A).
+type AggregateNode struct {
+	NodeType
+	Pos
+	Name     string   // The aggregation operator, e.g. sum or topk.
+	Grouping []string // Labels listed in the by() or without() clause.
+	Without  bool     // If true, items are grouped by all labels except Grouping.
+	Param    Node     // The number of items to keep for topk and bottomk, nil otherwise.
+	Arg      Node
+}
+
+func newAggregate(pos Pos, name string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Name: name}
+}
+
+// IsAggregateOp returns true if name is an aggregation operator.
+func IsAggregateOp(name string) bool {
+	_, ok := aggregateOps[name]
+	return ok
+}
+
+// aggregateOps holds the aggregation operators, mapped to whether they take
+// a parameter before the set to aggregate.
+var aggregateOps = map[string]bool{
+	"sum":     false,
+	"avg":     false,
+	"min":     false,
+	"max":     false,
+	"count":   false,
+	"topk":    true,
+	"bottomk": true,
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	s := a.Name
+	if a.Grouping != nil {
+		clause := "by"
+		if a.Without {
+			clause = "without"
+		}
+		s += fmt.Sprintf(" %s(%s) ", clause, strings.Join(a.Grouping, ", "))
+	}
+	if a.Param != nil {
+		return fmt.Sprintf("%s(%s, %s)", s, a.Param, a.Arg)
+	}
+	return fmt.Sprintf("%s(%s)", s, a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	return a.String()
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	if a.Param != nil {
+		if rt := a.Param.Return(); rt != TypeScalar {
+			return fmt.Errorf("parse: expected %v for the parameter of %s, got %v", TypeScalar, a.Name, rt)
+		}
+		if err := a.Param.Check(t); err != nil {
+			return err
+		}
+	}
+	switch rt := a.Arg.Return(); rt {
+	case TypeNumberSet, TypeSeriesSet, TypeVariantSet:
+	default:
+		return fmt.Errorf("parse: expected %v or %v to aggregate with %s, got %v", TypeNumberSet, TypeSeriesSet, a.Name, rt)
+	}
+	return a.Arg.Check(t)
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
 // Walk invokes f on n and sub-nodes of n.
 func Walk(n Node, f func(Node)) {
 	f(n)
@@ -382,6 +488,11 @@ func Walk(n Node, f func(Node)) {
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
+	case *AggregateNode:
+		if n.Param != nil {
+			Walk(n.Param, f)
+		}
+		Walk(n.Arg, f)
 	default:
 		panic(fmt.Errorf("other type: %T", n))
 	}
@@ -172,9 +172,14 @@ P -> M {( "+" | "-" ) M}
 M -> E {( "*" | "/" ) F}
 E -> F {( "**" ) F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | aggregate(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Aggregate -> name [grouping] "(" [number ","] O ")" [grouping]
+grouping -> ( "by" | "without" ) labels
+labels -> "(" [label {"," label}] ")"
+
+Each binary operator may be followed by ( "on" | "ignoring" ) labels.
 */
 
 // expr:
@@ -185,7 +190,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +203,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +216,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +229,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +242,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +255,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -275,7 +280,26 @@ func (t *Tree) F() Node {
 	return nil
 }
 
-// V is number | func(..) | queryVar in the grammar.
+// binary creates a BinaryNode for the operator, parsing the optional on() or
+// ignoring() modifier that may precede the right hand side.
+func (t *Tree) binary(operator item, left Node, right func() Node) Node {
+	matching := t.matching()
+	b := newBinary(operator, left, right())
+	b.Matching = matching
+	return b
+}
+
+// matching parses an on() or ignoring() modifier if there is one.
+func (t *Tree) matching() *Matching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	return &Matching{On: token.val == "on", Labels: t.labels(token.val)}
+}
+
+// V is number | func(..) | aggregate(..) | queryVar in the grammar.
 func (t *Tree) v() Node {
 	switch token := t.next(); token.typ {
 	case itemNumber:
@@ -286,6 +310,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if IsAggregateOp(token.val) {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -332,6 +359,73 @@ func (t *Tree) Func() (f *FuncNode) {
 		case itemRightParen:
 			return
 		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+			// continue with the next argument
+		case itemRightParen:
+			return
+		default:
+			t.unexpected(token, "func")
+		}
+	}
+}
+
+// Aggregate parses an AggregateNode.
+func (t *Tree) Aggregate() (a *AggregateNode) {
+	token := t.next()
+	a = newAggregate(token.pos, token.val)
+	t.grouping(a)
+	t.expect(itemLeftParen, token.val)
+	if aggregateOps[a.Name] {
+		a.Param = t.O()
+		t.expect(itemComma, token.val)
+	}
+	a.Arg = t.O()
+	t.expect(itemRightParen, token.val)
+	t.grouping(a)
+	return
+}
+
+// grouping parses a by() or without() clause into the AggregateNode if there is one.
+func (t *Tree) grouping(a *AggregateNode) {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "by" && token.val != "without") {
+		return
+	}
+	t.next()
+	if a.Grouping != nil {
+		t.errorf("multiple grouping clauses in %s", a.Name)
+	}
+	a.Without = token.val == "without"
+	a.Grouping = t.labels(token.val)
+}
+
+// labels parses a parenthesized, comma separated list of label names.
+func (t *Tree) labels(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return labels
+	}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		default:
+			t.unexpected(token, context)
+		}
+		if token := t.next(); token.typ == itemRightParen {
+			return labels
+		} else if token.typ != itemComma {
+			t.unexpected(token, context)
+		}
 	}
 }
 
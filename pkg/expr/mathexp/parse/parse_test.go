@@ -0,0 +1,58 @@
+package parse
+
+import (
+	"testing"
+)
+
+var testFuncs = map[string]Func{
+	"abs": {
+		Args:          []ReturnType{TypeVariantSet},
+		VariantReturn: true,
+	},
+	"clamp": {
+		Args:          []ReturnType{TypeVariantSet, TypeScalar, TypeScalar},
+		VariantReturn: true,
+	},
+	"moving_avg": {
+		Args:   []ReturnType{TypeSeriesSet, TypeString},
+		Return: TypeSeriesSet,
+	},
+}
+
+type parseTest struct {
+	name    string
+	input   string
+	output  string // String() of the root node, if no error.
+	wantErr bool
+}
+
+var parseTests = []parseTest{
+	{"single argument", "abs($A)", "abs($A)", false},
+	{"comma separated arguments", "clamp($A, 0, 1)", "clamp($A, 0, 1)", false},
+	{"underscore in name and string argument", `moving_avg($A, "5m")`, `moving_avg($A, "5m")`, false},
+	{"nested calls", "clamp(abs($A), 0, 1) + 1", "clamp(abs($A), 0, 1) + 1", false},
+	{"not enough arguments", "clamp($A, 0)", "", true},
+	{"too many arguments", "abs($A, 1)", "", true},
+	{"missing argument after comma", "clamp($A, 0, )", "", true},
+	{"missing comma", "clamp($A 0 1)", "", true},
+	{"unclosed call", "clamp($A, 0, 1", "", true},
+}
+
+func TestParse(t *testing.T) {
+	for _, test := range parseTests {
+		tree, err := Parse(test.input, testFuncs)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", test.name, tree.Root)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if got := tree.Root.String(); got != test.output {
+			t.Errorf("%s: got %q, expected %q", test.name, got, test.output)
+		}
+	}
+}
@@ -3,10 +3,16 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+// percentileReducerPattern matches percentile reducers such as "p95" or "p99.9".
+var percentileReducerPattern = regexp.MustCompile(`^p(\d+(?:\.\d+)?)$`)
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -69,13 +75,158 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+func First(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	return nonNumberToNaN(fv.GetValue(0))
+}
+
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	return nonNumberToNaN(fv.GetValue(fv.Len() - 1))
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Stddev returns the population standard deviation of the values.
+func Stddev(fv *Float64Field) *float64 {
+	avg := Avg(fv)
+	if math.IsNaN(*avg) {
+		return avg
+	}
+	var sqDiffs float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *avg
+		sqDiffs += d * d
+	}
+	f := math.Sqrt(sqDiffs / float64(fv.Len()))
+	return &f
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values,
+// interpolating linearly between the two closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	nan := math.NaN()
+	if fv.Len() == 0 {
+		return &nan
+	}
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return &nan
+		}
+		values = append(values, *v)
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &f
+}
+
+func nonNumberToNaN(f *float64) *float64 {
+	if f == nil || math.IsNaN(*f) {
+		nan := math.NaN()
+		return &nan
+	}
+	v := *f
+	return &v
+}
+
+// parsePercentileReducer returns the percentile of a reducer such as "p95".
+func parsePercentileReducer(rFunc string) (float64, bool) {
+	m := percentileReducerPattern.FindStringSubmatch(rFunc)
+	if m == nil {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// ValidReduceFunc returns true if rFunc is a reduction function supported by Series.Reduce.
+func ValidReduceFunc(rFunc string) bool {
+	switch rFunc {
+	case "sum", "mean", "min", "max", "count", "count_non_null", "first", "last", "median", "stddev":
+		return true
+	}
+	_, ok := parsePercentileReducer(rFunc)
+	return ok
+}
+
+// ReduceMapper transforms a Series before it is reduced.
+type ReduceMapper interface {
+	MapInput(s Series) Series
+}
+
+// DropNonNumber is a ReduceMapper that removes null and NaN values from the series.
+type DropNonNumber struct{}
+
+// MapInput returns a copy of s without its null and NaN values.
+func (d DropNonNumber) MapInput(s Series) Series {
+	newSeries := NewSeries(s.Frame.Fields[seriesTypeValIdx].Name, s.GetLabels(), 0)
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil || math.IsNaN(*f) {
+			continue
+		}
+		_ = newSeries.AppendPoint(i, t, f)
+	}
+	return newSeries
+}
+
+// ReplaceNonNumberWithValue is a ReduceMapper that replaces null and NaN values with Value.
+type ReplaceNonNumberWithValue struct {
+	Value float64
+}
+
+// MapInput returns a copy of s with its null and NaN values replaced by r.Value.
+func (r ReplaceNonNumberWithValue) MapInput(s Series) Series {
+	newSeries := NewSeries(s.Frame.Fields[seriesTypeValIdx].Name, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil || math.IsNaN(*f) {
+			v := r.Value
+			f = &v
+		}
+		_ = newSeries.SetPoint(i, t, f)
+	}
+	return newSeries
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// If mapper is not nil, the series is transformed by it before it is reduced.
+func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
 	}
 	number := NewNumber(refID, l)
+	if mapper != nil {
+		s = mapper.MapInput(s)
+	}
 	var f *float64
 	fVec := s.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
@@ -90,8 +241,22 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "count_non_null":
+		f = CountNonNull(&floatField)
+	case "first":
+		f = First(&floatField)
+	case "last":
+		f = Last(&floatField)
+	case "median":
+		f = Median(&floatField)
+	case "stddev":
+		f = Stddev(&floatField)
 	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		p, ok := parsePercentileReducer(rFunc)
+		if !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		f = Percentile(&floatField, p)
 	}
 	number.SetValue(f)
 
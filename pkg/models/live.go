@@ -3,9 +3,12 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
 // ChannelPublisher writes data into a channel. Note that permissions are not checked.
@@ -98,3 +101,35 @@ type GetLiveMessageQuery struct {
 	OrgId   int64
 	Channel string
 }
+
+var ErrLiveChannelRuleNotFound = errors.New("live channel rule not found")
+
+// LiveChannelRule configures the processing of the frames pushed to a managed stream channel.
+type LiveChannelRule struct {
+	Id       int64            `json:"-"`
+	OrgId    int64            `json:"-"`
+	Channel  string           `json:"channel"`
+	Settings *simplejson.Json `json:"settings"`
+	Created  time.Time        `json:"created"`
+	Updated  time.Time        `json:"updated"`
+}
+
+type ListLiveChannelRulesQuery struct {
+	OrgId int64
+}
+
+type GetLiveChannelRuleQuery struct {
+	OrgId   int64
+	Channel string
+}
+
+type SaveLiveChannelRuleCommand struct {
+	OrgId    int64
+	Channel  string
+	Settings *simplejson.Json
+}
+
+type DeleteLiveChannelRuleCommand struct {
+	OrgId   int64
+	Channel string
+}
@@ -37,6 +37,9 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+
+	// StatusCode is set to the status code of the response once the webhook is sent.
+	StatusCode int
 }
 
 type SendResetPasswordEmailCommand struct {
@@ -0,0 +1,100 @@
+package live
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type channelRuleListResponse struct {
+	Rules []*models.LiveChannelRule `json:"rules"`
+}
+
+// HandleChannelRulesListHTTP returns the channel rules of the organization.
+func (g *GrafanaLive) HandleChannelRulesListHTTP(c *models.ReqContext) response.Response {
+	rules, err := g.storage.ListChannelRules(&models.ListLiveChannelRulesQuery{OrgId: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get channel rules", err)
+	}
+	return response.JSON(http.StatusOK, channelRuleListResponse{Rules: rules})
+}
+
+// HandleChannelRuleGetHTTP returns the rule of a channel, the channel is in the path.
+func (g *GrafanaLive) HandleChannelRuleGetHTTP(c *models.ReqContext) response.Response {
+	rule, ok, err := g.storage.GetChannelRule(&models.GetLiveChannelRuleQuery{OrgId: c.OrgId, Channel: c.Params("*")})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get channel rule", err)
+	}
+	if !ok {
+		return response.Error(http.StatusNotFound, models.ErrLiveChannelRuleNotFound.Error(), nil)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
+
+// HandleChannelRulePutHTTP creates or replaces the rule of a channel, the channel is in the path.
+func (g *GrafanaLive) HandleChannelRulePutHTTP(c *models.ReqContext, cmd dtos.LiveChannelRuleCmd) response.Response {
+	channel := c.Params("*")
+	addr, isStream, err := parseRuleChannel(channel)
+	if err != nil || addr.Scope != live.ScopeStream {
+		return response.Error(http.StatusBadRequest, "Channel rules can only be set on stream channels", nil)
+	}
+	if cmd.Settings == nil {
+		cmd.Settings = simplejson.New()
+	}
+	settings, err := pipeline.ParseChannelRuleSettings(cmd.Settings)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if isStream && (len(settings.Processors) > 0 || len(settings.Outputs) > 0) {
+		return response.Error(http.StatusBadRequest, "Rules of streams can only set a converter", nil)
+	}
+	if !isStream && settings.Converter != nil {
+		return response.Error(http.StatusBadRequest, "Only rules of streams can set a converter", nil)
+	}
+
+	rule, err := g.storage.SaveChannelRule(&models.SaveLiveChannelRuleCommand{
+		OrgId:    c.OrgId,
+		Channel:  channel,
+		Settings: cmd.Settings,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save channel rule", err)
+	}
+	g.Pipeline.InvalidateRules(c.OrgId)
+	return response.JSON(http.StatusOK, rule)
+}
+
+// HandleChannelRuleDeleteHTTP deletes the rule of a channel, the channel is in the path.
+func (g *GrafanaLive) HandleChannelRuleDeleteHTTP(c *models.ReqContext) response.Response {
+	err := g.storage.DeleteChannelRule(&models.DeleteLiveChannelRuleCommand{OrgId: c.OrgId, Channel: c.Params("*")})
+	if err != nil {
+		if errors.Is(err, models.ErrLiveChannelRuleNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+	}
+	g.Pipeline.InvalidateRules(c.OrgId)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "Channel rule deleted"})
+}
+
+// parseRuleChannel parses the channel of a rule. Besides channels, rules can be set on
+// streams, e.g. stream/telegraf, to set the converter of the data pushed to them.
+func parseRuleChannel(channel string) (live.Channel, bool, error) {
+	if strings.Count(channel, "/") != 1 {
+		addr, err := live.ParseChannel(channel)
+		return addr, false, err
+	}
+	// Check the stream as the channel of a path of the stream.
+	addr, err := live.ParseChannel(channel + "/path")
+	addr.Path = ""
+	return addr, true, err
+}
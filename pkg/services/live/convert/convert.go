@@ -3,11 +3,38 @@ package convert
 import (
 	"errors"
 	"fmt"
+	"mime"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/jsonobj"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Input formats.
+const (
+	InputFormatInflux     = "influx"
+	InputFormatJSON       = "json"
+	InputFormatPrometheus = "prometheus"
+	InputFormatOTLP       = "otlp"
+)
+
+// Options of a conversion.
+type Options struct {
+	// InputFormat is the format of the data, influx by default.
+	InputFormat string
+	// FrameFormat is the format of the frames: labels_column or wide.
+	FrameFormat string
+	// ContentType is the content type of the data. OTLP data are read as Protobuf unless
+	// it is application/json.
+	ContentType string
+	// TimeField is the field with the time of JSON objects.
+	TimeField string
+	// Name is the name of the frames of JSON objects.
+	Name string
+}
+
 type Converter struct {
 	telegrafConverterWide         *telegraf.Converter
 	telegrafConverterLabelsColumn *telegraf.Converter
@@ -25,19 +52,58 @@ func NewConverter() *Converter {
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
 
-func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
-	var converter telemetry.Converter
-	switch frameFormat {
+// InputFormatFromContentType returns the input format for a content type. Influx line
+// protocol is the default, since Telegraf sends it as text/plain.
+func InputFormatFromContentType(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return InputFormatInflux
+	}
+	switch mediaType {
+	case "application/json":
+		return InputFormatJSON
+	case "application/x-protobuf":
+		return InputFormatOTLP
+	case "text/plain":
+		// Prometheus text exposition format has a version parameter.
+		if params["version"] != "" {
+			return InputFormatPrometheus
+		}
+	}
+	return InputFormatInflux
+}
+
+func (c *Converter) Convert(data []byte, opts Options) ([]telemetry.FrameWrapper, error) {
+	var frames *telegraf.Converter
+	switch opts.FrameFormat {
 	case "wide":
-		converter = c.telegrafConverterWide
+		frames = c.telegrafConverterWide
 	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+		frames = c.telegrafConverterLabelsColumn
 	default:
 		return nil, ErrUnsupportedFrameFormat
 	}
 
+	var converter telemetry.Converter
+	switch opts.InputFormat {
+	case InputFormatInflux, "":
+		converter = frames
+	case InputFormatJSON:
+		converter = jsonobj.NewConverter(frames, jsonobj.WithTimeField(opts.TimeField), jsonobj.WithName(opts.Name))
+	case InputFormatPrometheus:
+		converter = prometheus.NewConverter(frames)
+	case InputFormatOTLP:
+		mediaType, _, _ := mime.ParseMediaType(opts.ContentType)
+		converter = otlp.NewConverter(frames, otlp.WithJSON(mediaType == "application/json"))
+	default:
+		return nil, ErrUnsupportedInputFormat
+	}
+
 	metricFrames, err := converter.Convert(data)
 	if err != nil {
 		return nil, fmt.Errorf("error converting metrics: %w", err)
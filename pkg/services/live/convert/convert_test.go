@@ -0,0 +1,43 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputFormatFromContentType(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		inputFormat string
+	}{
+		{contentType: "", inputFormat: InputFormatInflux},
+		{contentType: "text/plain; charset=utf-8", inputFormat: InputFormatInflux},
+		{contentType: "text/plain; version=0.0.4; charset=utf-8", inputFormat: InputFormatPrometheus},
+		{contentType: "application/json", inputFormat: InputFormatJSON},
+		{contentType: "application/x-protobuf", inputFormat: InputFormatOTLP},
+		{contentType: "application/octet-stream", inputFormat: InputFormatInflux},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.inputFormat, InputFormatFromContentType(tc.contentType), tc.contentType)
+	}
+}
+
+func TestConverter_Convert(t *testing.T) {
+	c := NewConverter()
+
+	frames, err := c.Convert([]byte(`{"time": 1000, "cpu": 1}`), Options{InputFormat: InputFormatJSON, FrameFormat: "wide", Name: "cpu"})
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	require.Equal(t, "cpu", frames[0].Key())
+
+	frames, err = c.Convert([]byte("cpu value=1 1000000000\n"), Options{FrameFormat: "labels_column"})
+	require.NoError(t, err, "Influx line protocol is the default")
+	require.Len(t, frames, 1)
+
+	_, err = c.Convert(nil, Options{InputFormat: "csv", FrameFormat: "wide"})
+	require.ErrorIs(t, err, ErrUnsupportedInputFormat)
+
+	_, err = c.Convert(nil, Options{InputFormat: InputFormatJSON, FrameFormat: "long"})
+	require.ErrorIs(t, err, ErrUnsupportedFrameFormat)
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -64,3 +65,59 @@ func (s *Storage) GetLiveMessage(query *models.GetLiveMessageQuery) (models.Live
 	}
 	return msg, true, nil
 }
+
+func (s *Storage) ListChannelRules(query *models.ListLiveChannelRulesQuery) ([]*models.LiveChannelRule, error) {
+	var rules []*models.LiveChannelRule
+	err := s.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("live_channel_rule").Where("org_id=?", query.OrgId).Asc("channel").Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *Storage) GetChannelRule(query *models.GetLiveChannelRuleQuery) (models.LiveChannelRule, bool, error) {
+	var rule models.LiveChannelRule
+	var exists bool
+	err := s.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Table("live_channel_rule").Where("org_id=? AND channel=?", query.OrgId, query.Channel).Get(&rule)
+		return err
+	})
+	return rule, exists, err
+}
+
+// SaveChannelRule creates the rule of the channel or replaces its settings.
+func (s *Storage) SaveChannelRule(cmd *models.SaveLiveChannelRuleCommand) (models.LiveChannelRule, error) {
+	var rule models.LiveChannelRule
+	err := s.store.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table("live_channel_rule").Where("org_id=? AND channel=?", cmd.OrgId, cmd.Channel).Get(&rule)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		rule.Settings = cmd.Settings
+		rule.Updated = now
+		if exists {
+			_, err = sess.Table("live_channel_rule").ID(rule.Id).Cols("settings", "updated").Update(&rule)
+			return err
+		}
+		rule.OrgId = cmd.OrgId
+		rule.Channel = cmd.Channel
+		rule.Created = now
+		_, err = sess.Table("live_channel_rule").Insert(&rule)
+		return err
+	})
+	return rule, err
+}
+
+func (s *Storage) DeleteChannelRule(cmd *models.DeleteLiveChannelRuleCommand) error {
+	return s.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Exec("DELETE FROM live_channel_rule WHERE org_id=? AND channel=?", cmd.OrgId, cmd.Channel)
+		if err != nil {
+			return err
+		}
+		if rows, _ := affected.RowsAffected(); rows == 0 {
+			return models.ErrLiveChannelRuleNotFound
+		}
+		return nil
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 
 	"github.com/stretchr/testify/require"
@@ -60,3 +61,53 @@ func TestLiveMessage(t *testing.T) {
 	require.Equal(t, json.RawMessage(`{"input": "hello"}`), msg2.Data)
 	require.NotZero(t, msg2.Published)
 }
+
+func TestLiveChannelRules(t *testing.T) {
+	storage := SetupTestStorage(t)
+
+	rules, err := storage.ListChannelRules(&models.ListLiveChannelRulesQuery{OrgId: 1})
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	settings, err := simplejson.NewJson([]byte(`{"processors": [{"type": "keepFields", "keepFields": {"fieldNames": ["usage_user"]}}]}`))
+	require.NoError(t, err)
+	created, err := storage.SaveChannelRule(&models.SaveLiveChannelRuleCommand{
+		OrgId:    1,
+		Channel:  "stream/telegraf/cpu",
+		Settings: settings,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, created.Id)
+	require.NotZero(t, created.Created)
+
+	// save again, the settings should be replaced.
+	updated, err := storage.SaveChannelRule(&models.SaveLiveChannelRuleCommand{
+		OrgId:    1,
+		Channel:  "stream/telegraf/cpu",
+		Settings: simplejson.New(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, created.Id, updated.Id)
+
+	rule, ok, err := storage.GetChannelRule(&models.GetLiveChannelRuleQuery{OrgId: 1, Channel: "stream/telegraf/cpu"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/telegraf/cpu", rule.Channel)
+	require.Equal(t, created.Created.Unix(), rule.Created.Unix())
+	require.Empty(t, rule.Settings.MustMap())
+
+	rules, err = storage.ListChannelRules(&models.ListLiveChannelRulesQuery{OrgId: 1})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	rules, err = storage.ListChannelRules(&models.ListLiveChannelRulesQuery{OrgId: 2})
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	err = storage.DeleteChannelRule(&models.DeleteLiveChannelRuleCommand{OrgId: 1, Channel: "stream/telegraf/cpu"})
+	require.NoError(t, err)
+	_, ok, err = storage.GetChannelRule(&models.GetLiveChannelRuleQuery{OrgId: 1, Channel: "stream/telegraf/cpu"})
+	require.NoError(t, err)
+	require.False(t, ok)
+	err = storage.DeleteChannelRule(&models.DeleteLiveChannelRuleCommand{OrgId: 1, Channel: "stream/telegraf/cpu"})
+	require.ErrorIs(t, err, models.ErrLiveChannelRuleNotFound)
+}
@@ -2,6 +2,7 @@ package live
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -21,15 +22,20 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/liveaccess"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushws"
 	"github.com/grafana/grafana/pkg/services/live/runstream"
+	"github.com/grafana/grafana/pkg/services/live/sqlengine"
 	"github.com/grafana/grafana/pkg/services/live/survey"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -49,7 +55,8 @@ var (
 
 func NewGrafanaLive() *GrafanaLive {
 	return &GrafanaLive{
-		channels: make(map[string]models.ChannelHandler),
+		ChannelAccess: liveaccess.NewChecker(nil),
+		channels:      make(map[string]models.ChannelHandler),
 		GrafanaScope: CoreGrafanaScope{
 			Features: make(map[string]models.ChannelHandlerFactory),
 		},
@@ -66,7 +73,7 @@ type CoreGrafanaScope struct {
 
 func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, routeRegister routing.RouteRegister,
 	logsService *cloudwatch.LogsService, pluginManager *manager.PluginManager, cacheService *localcache.CacheService,
-	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore) (*GrafanaLive, error) {
+	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore, accessControl accesscontrol.AccessControl) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		PluginContextProvider: plugCtxProvider,
@@ -76,6 +83,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		CacheService:          cacheService,
 		DataSourceCache:       dataSourceCache,
 		SQLStore:              sqlStore,
+		AccessControl:         accessControl,
+		ChannelAccess:         liveaccess.NewChecker(accessControl),
 		channels:              make(map[string]models.ChannelHandler),
 		GrafanaScope: CoreGrafanaScope{
 			Features: make(map[string]models.ChannelHandlerFactory),
@@ -84,6 +93,10 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
 
+	if err := g.declareFixedRoles(); err != nil {
+		return nil, err
+	}
+
 	// We use default config here as starting point. Default config contains
 	// reasonable values for available options.
 	scfg := centrifuge.DefaultConfig
@@ -102,7 +115,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	if g.IsHA() {
+	switch g.Cfg.LiveHAEngine {
+	case "redis":
 		// Configure HA with Redis. In this case Centrifuge nodes
 		// will be connected over Redis PUB/SUB. Presence will work
 		// globally since kept inside Redis.
@@ -147,6 +161,12 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
 		}
 		node.SetPresenceManager(presenceManager)
+	case "database":
+		// Configure HA with the Grafana database. In this case Centrifuge nodes
+		// will be connected over a table of messages, notified with LISTEN/NOTIFY
+		// on PostgreSQL. Presence will work globally since kept in another table.
+		node.SetBroker(sqlengine.NewBroker(node, g.SQLStore, sqlengine.BrokerConfig{}))
+		node.SetPresenceManager(sqlengine.NewPresenceManager(g.SQLStore, sqlengine.PresenceManagerConfig{}))
 	}
 
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider)
@@ -164,8 +184,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
 
+	var frameCacheOptions []managedstream.FrameCacheOption
+	if g.Cfg.LiveHistoryMaxFrames > 0 {
+		frameCacheOptions = append(frameCacheOptions, managedstream.WithHistory(g.Cfg.LiveHistoryMaxFrames, g.Cfg.LiveHistoryMaxAge))
+	}
+
 	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
+	switch g.Cfg.LiveHAEngine {
+	case "redis":
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
 		})
@@ -175,16 +201,22 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCache(redisClient, frameCacheOptions...),
 		)
-	} else {
+	case "database":
+		managedStreamRunner = managedstream.NewRunner(
+			g.Publish,
+			managedstream.NewSQLFrameCache(g.SQLStore, frameCacheOptions...),
+		)
+	default:
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(frameCacheOptions...),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
+	g.Pipeline = pipeline.New(g.storage, managedStreamRunner)
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
 	err = g.surveyCaller.SetupHandlers()
 	if err != nil {
@@ -267,7 +299,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		CheckOrigin:     checkOrigin,
 	})
 
-	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, pushws.Config{
+	pushWSHandler := pushws.NewHandler(g.Pipeline, g.ChannelAccess, pushws.Config{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
@@ -300,7 +332,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
 		group.Get("/push/:streamId", g.pushWebsocketHandler)
-	}, middleware.ReqOrgAdmin)
+	}, acmiddleware.Middleware(g.AccessControl)(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(liveaccess.ActionPublish)))
 
 	return g, nil
 }
@@ -319,6 +351,10 @@ type GrafanaLive struct {
 	CacheService          *localcache.CacheService
 	DataSourceCache       datasources.CacheService
 	SQLStore              *sqlstore.SQLStore
+	AccessControl         accesscontrol.AccessControl
+
+	// ChannelAccess checks the permissions of users on channels.
+	ChannelAccess *liveaccess.Checker
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -336,6 +372,9 @@ type GrafanaLive struct {
 
 	ManagedStreamRunner *managedstream.Runner
 
+	// Pipeline processes the frames pushed to managed streams.
+	Pipeline *pipeline.Pipeline
+
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
 	storage          *database.Storage
@@ -477,6 +516,16 @@ func (g *GrafanaLive) handleOnSubscribe(client *centrifuge.Client, e centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.ChannelAccess.CanSubscribe(client.Context(), user, channel)
+	if err != nil {
+		logger.Error("Error checking subscribe permission", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		logger.Info("Error subscribing: permission denied", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
+	}
+
 	handler, addr, err := g.GetChannelHandler(user, channel)
 	if err != nil {
 		if errors.Is(err, live.ErrInvalidChannelID) {
@@ -532,6 +581,16 @@ func (g *GrafanaLive) handleOnPublish(client *centrifuge.Client, e centrifuge.Pu
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.ChannelAccess.CanPublish(client.Context(), user, channel)
+	if err != nil {
+		logger.Error("Error checking publish permission", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		logger.Info("Error publishing: permission denied", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
+		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
+	}
+
 	handler, addr, err := g.GetChannelHandler(user, channel)
 	if err != nil {
 		if errors.Is(err, live.ErrInvalidChannelID) {
@@ -730,6 +789,14 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *models.ReqContext, cmd dtos.LivePub
 
 	logger.Debug("Publish API cmd", "user", ctx.SignedInUser.UserId, "channel", cmd.Channel)
 
+	allowed, err := g.ChannelAccess.CanPublish(ctx.Req.Context(), ctx.SignedInUser, cmd.Channel)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check publish permission", err)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
+	}
+
 	channelHandler, addr, err := g.GetChannelHandler(ctx.SignedInUser, cmd.Channel)
 	if err != nil {
 		logger.Error("Error getting channels handler", "error", err, "channel", cmd.Channel)
@@ -778,6 +845,39 @@ func (g *GrafanaLive) HandleListHTTP(c *models.ReqContext) response.Response {
 	return response.JSONStreaming(200, info)
 }
 
+type channelHistoryResponse struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// HandleHistoryHTTP returns the recent frames of a stream channel merged into one frame, the
+// channel is in the path.
+func (g *GrafanaLive) HandleHistoryHTTP(c *models.ReqContext) response.Response {
+	channel := c.Params("*")
+	addr, err := live.ParseChannel(channel)
+	if err != nil || addr.Scope != live.ScopeStream {
+		return response.Error(http.StatusBadRequest, "History is only kept for stream channels", nil)
+	}
+	allowed, err := g.ChannelAccess.CanSubscribe(c.Req.Context(), c.SignedInUser, channel)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check subscribe permission", err)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
+	}
+	frameJSON, ok, err := g.ManagedStreamRunner.GetRecentFrame(c.SignedInUser.OrgId, channel)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get channel history", err)
+	}
+	if !ok {
+		return response.Error(http.StatusNotFound, "Channel has no frames", nil)
+	}
+	return response.JSONStreaming(http.StatusOK, channelHistoryResponse{
+		Channel: channel,
+		Data:    frameJSON,
+	})
+}
+
 // HandleInfoHTTP special http response for
 func (g *GrafanaLive) HandleInfoHTTP(ctx *models.ReqContext) response.Response {
 	path := ctx.Params("*")
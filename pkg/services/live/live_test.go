@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/stretchr/testify/require"
@@ -157,3 +159,20 @@ func TestCheckOrigin(t *testing.T) {
 		})
 	}
 }
+
+func TestDeclareFixedRoles(t *testing.T) {
+	accessControl := mock.New()
+	g := &GrafanaLive{AccessControl: accessControl}
+	require.NoError(t, g.declareFixedRoles())
+	require.Len(t, accessControl.Calls.DeclareFixedRoles, 1)
+
+	registrations := accessControl.Calls.DeclareFixedRoles[0].([]interface{})[0].([]accesscontrol.RoleRegistration)
+	require.Len(t, registrations, 3)
+	for _, r := range registrations {
+		require.NoError(t, accesscontrol.ValidateFixedRole(r.Role))
+		require.NoError(t, accesscontrol.ValidateBuiltInRoles(r.Grants))
+		for _, p := range r.Role.Permissions {
+			require.True(t, accesscontrol.ValidateScope(p.Scope), p.Scope)
+		}
+	}
+}
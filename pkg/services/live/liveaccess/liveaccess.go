@@ -0,0 +1,80 @@
+package liveaccess
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+)
+
+// Live related actions
+const (
+	// ActionPublish allows to publish to channels, including to push data to managed streams.
+	ActionPublish = "live:publish"
+	// ActionSubscribe allows to subscribe to channels and to read their history.
+	ActionSubscribe = "live:subscribe"
+)
+
+// Live related scopes
+const (
+	ScopeChannelsAll       = "live:channels:*"
+	ScopeChannelsGrafana   = "live:channels:grafana/*"
+	ScopeChannelsPlugin    = "live:channels:plugin/*"
+	ScopeChannelsDS        = "live:channels:ds/*"
+	ScopeChannelsStreamAll = "live:channels:stream/*"
+)
+
+// ScopeChannel returns the scope of a channel without org prefix, e.g.
+// live:channels:stream/telegraf/cpu. Permissions with a scope ending with /* apply
+// to all the channels under a prefix, e.g. live:channels:stream/telegraf/*.
+func ScopeChannel(channel string) string {
+	return accesscontrol.Scope("live", "channels", channel)
+}
+
+// Checker checks the permissions of users on channels. When access control is
+// disabled all checks pass and only the org role checks of channel handlers and
+// routes apply.
+type Checker struct {
+	accessControl accesscontrol.AccessControl
+}
+
+// NewChecker creates a Checker. A nil AccessControl is handled as disabled.
+func NewChecker(accessControl accesscontrol.AccessControl) *Checker {
+	return &Checker{accessControl: accessControl}
+}
+
+// CanPublish returns true if the user can publish to the channel.
+func (c *Checker) CanPublish(ctx context.Context, user *models.SignedInUser, channel string) (bool, error) {
+	return c.check(ctx, user, ActionPublish, channel)
+}
+
+// CanSubscribe returns true if the user can subscribe to the channel.
+func (c *Checker) CanSubscribe(ctx context.Context, user *models.SignedInUser, channel string) (bool, error) {
+	return c.check(ctx, user, ActionSubscribe, channel)
+}
+
+// DeniedPushChannel returns the first channel of the frames pushed to a stream the user
+// can't publish to, or an empty string if the user can publish to all of them.
+func (c *Checker) DeniedPushChannel(ctx context.Context, user *models.SignedInUser, streamID string, frames []telemetry.FrameWrapper) (string, error) {
+	for _, f := range frames {
+		channel := live.Channel{Scope: live.ScopeStream, Namespace: streamID, Path: f.Key()}.String()
+		allowed, err := c.CanPublish(ctx, user, channel)
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			return channel, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Checker) check(ctx context.Context, user *models.SignedInUser, action string, channel string) (bool, error) {
+	if c.accessControl == nil || c.accessControl.IsDisabled() {
+		return true, nil
+	}
+	return c.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, ScopeChannel(channel)))
+}
@@ -0,0 +1,144 @@
+package liveaccess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+	tests := []struct {
+		name          string
+		accessControl accesscontrol.AccessControl
+		channel       string
+		canPublish    bool
+		canSubscribe  bool
+	}{
+		{
+			name:          "nil access control",
+			accessControl: nil,
+			channel:       "stream/telegraf/cpu",
+			canPublish:    true,
+			canSubscribe:  true,
+		},
+		{
+			name:          "disabled access control",
+			accessControl: mock.New().WithDisabled(),
+			channel:       "stream/telegraf/cpu",
+			canPublish:    true,
+			canSubscribe:  true,
+		},
+		{
+			name:          "no permissions",
+			accessControl: mock.New(),
+			channel:       "stream/telegraf/cpu",
+		},
+		{
+			name: "all channels",
+			accessControl: mock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannelsAll},
+				{Action: ActionSubscribe, Scope: ScopeChannelsAll},
+			}),
+			channel:      "stream/telegraf/cpu",
+			canPublish:   true,
+			canSubscribe: true,
+		},
+		{
+			name: "stream pattern",
+			accessControl: mock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/*")},
+				{Action: ActionSubscribe, Scope: ScopeChannelsStreamAll},
+			}),
+			channel:      "stream/telegraf/cpu",
+			canPublish:   true,
+			canSubscribe: true,
+		},
+		{
+			name: "other stream",
+			accessControl: mock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/*")},
+				{Action: ActionSubscribe, Scope: ScopeChannelsStreamAll},
+			}),
+			channel:      "stream/other/cpu",
+			canSubscribe: true,
+		},
+		{
+			name: "exact channel",
+			accessControl: mock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/cpu")},
+			}),
+			channel:    "stream/telegraf/cpu",
+			canPublish: true,
+		},
+		{
+			name: "stream prefix without separator",
+			accessControl: mock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/*")},
+			}),
+			channel: "stream/telegraf2/cpu",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(tt.accessControl)
+
+			canPublish, err := checker.CanPublish(context.Background(), user, tt.channel)
+			require.NoError(t, err)
+			require.Equal(t, tt.canPublish, canPublish)
+
+			canSubscribe, err := checker.CanSubscribe(context.Background(), user, tt.channel)
+			require.NoError(t, err)
+			require.Equal(t, tt.canSubscribe, canSubscribe)
+		})
+	}
+}
+
+type testFrameWrapper struct {
+	key string
+}
+
+func (w testFrameWrapper) Key() string {
+	return w.key
+}
+
+func (w testFrameWrapper) Frame() *data.Frame {
+	return data.NewFrame(w.key)
+}
+
+func TestChecker_DeniedPushChannel(t *testing.T) {
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_EDITOR}
+	checker := NewChecker(mock.New().WithPermissions([]*accesscontrol.Permission{
+		{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/cpu")},
+		{Action: ActionPublish, Scope: ScopeChannel("stream/telegraf/mem")},
+	}))
+
+	denied, err := checker.DeniedPushChannel(context.Background(), user, "telegraf", []telemetry.FrameWrapper{
+		testFrameWrapper{key: "cpu"},
+		testFrameWrapper{key: "mem"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, denied)
+
+	denied, err = checker.DeniedPushChannel(context.Background(), user, "telegraf", []telemetry.FrameWrapper{
+		testFrameWrapper{key: "cpu"},
+		testFrameWrapper{key: "disk"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "stream/telegraf/disk", denied)
+
+	denied, err = checker.DeniedPushChannel(context.Background(), user, "other", []telemetry.FrameWrapper{
+		testFrameWrapper{key: "cpu"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "stream/other/cpu", denied)
+}
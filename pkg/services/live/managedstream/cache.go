@@ -12,6 +12,9 @@ type FrameCache interface {
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
 	// GetFrame returns full JSON frame for a path.
 	GetFrame(orgID int64, channel string) (json.RawMessage, bool, error)
+	// GetHistory returns recent full JSON frames for a path, oldest first. The frames have
+	// the schema of the last frame. It returns no frames if history is disabled.
+	GetHistory(orgID int64, channel string) ([]json.RawMessage, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
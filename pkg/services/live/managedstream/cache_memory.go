@@ -3,20 +3,27 @@ package managedstream
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
+	mu      sync.RWMutex
+	frames  map[int64]map[string]data.FrameJSONCache
+	history map[int64]map[string]*frameHistory
+	limits  historyLimits
+	now     func() time.Time
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(opts ...FrameCacheOption) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string]*frameHistory{},
+		limits:  newHistoryLimits(opts),
+		now:     time.Now,
 	}
 }
 
@@ -41,6 +48,16 @@ func (c *MemoryFrameCache) GetFrame(orgID int64, channel string) (json.RawMessag
 	return cachedFrame.Bytes(data.IncludeAll), ok, nil
 }
 
+func (c *MemoryFrameCache) GetHistory(orgID int64, channel string) ([]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	history, ok := c.history[orgID][channel]
+	if !ok {
+		return nil, nil
+	}
+	return history.since(c.now().Add(-c.limits.maxAge)), nil
+}
+
 func (c *MemoryFrameCache) Update(orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -50,5 +67,18 @@ func (c *MemoryFrameCache) Update(orgID int64, channel string, jsonFrame data.Fr
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+
+	if c.limits.enabled() {
+		if _, ok := c.history[orgID]; !ok {
+			c.history[orgID] = map[string]*frameHistory{}
+		}
+		history, ok := c.history[orgID][channel]
+		if !ok || schemaUpdated {
+			// Frames with another schema cannot be merged with the next ones.
+			history = newFrameHistory(c.limits.maxFrames)
+			c.history[orgID][channel] = history
+		}
+		history.add(c.now(), jsonFrame.Bytes(data.IncludeAll))
+	}
 	return schemaUpdated, nil
 }
@@ -3,6 +3,7 @@ package managedstream
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -67,3 +68,60 @@ func TestMemoryFrameCache(t *testing.T) {
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func testFrameCacheHistory(t *testing.T, c FrameCache, now *time.Time) {
+	push := func(orgID int64, channel string, frame *data.Frame) {
+		t.Helper()
+		frameJsonCache, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		_, err = c.Update(orgID, channel, frameJsonCache)
+		require.NoError(t, err)
+	}
+	cpuFrame := func(v float64) *data.Frame {
+		return data.NewFrame("cpu", data.NewField("value", nil, []float64{v}))
+	}
+	historyValues := func(orgID int64, channel string) []float64 {
+		t.Helper()
+		frames, err := c.GetHistory(orgID, channel)
+		require.NoError(t, err)
+		values := make([]float64, 0, len(frames))
+		for _, frameJSON := range frames {
+			var f data.Frame
+			require.NoError(t, json.Unmarshal(frameJSON, &f))
+			values = append(values, f.Fields[0].At(0).(float64))
+		}
+		return values
+	}
+
+	for i := 1; i <= 4; i++ {
+		push(1, "history", cpuFrame(float64(i)))
+		*now = now.Add(time.Second)
+	}
+	require.Equal(t, []float64{2, 3, 4}, historyValues(1, "history"), "history is limited by count")
+	require.Empty(t, historyValues(2, "history"))
+
+	*now = now.Add(58 * time.Second)
+	require.Equal(t, []float64{4}, historyValues(1, "history"), "history is limited by age")
+
+	push(1, "history", cpuFrame(5))
+	push(1, "history", data.NewFrame("cpu", data.NewField("value", nil, []float64{6}), data.NewField("new_field", nil, []int64{1})))
+	require.Equal(t, []float64{6}, historyValues(1, "history"), "frames with another schema are dropped")
+}
+
+func TestMemoryFrameCache_History(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewMemoryFrameCache(WithHistory(3, time.Minute))
+	c.now = func() time.Time { return now }
+	testFrameCacheHistory(t, c, &now)
+}
+
+func TestMemoryFrameCache_WithoutHistory(t *testing.T) {
+	c := NewMemoryFrameCache()
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu"))
+	require.NoError(t, err)
+	_, err = c.Update(1, "test", frameJsonCache)
+	require.NoError(t, err)
+	frames, err := c.GetHistory(1, "test")
+	require.NoError(t, err)
+	require.Empty(t, frames)
+}
@@ -3,6 +3,8 @@ package managedstream
 import (
 	"encoding/json"
 	"errors"
+	"hash/fnv"
+	"strconv"
 	"sync"
 	"time"
 
@@ -17,13 +19,17 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	limits      historyLimits
+	now         func() time.Time
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, opts ...FrameCacheOption) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		limits:      newHistoryLimits(opts),
+		now:         time.Now,
 	}
 }
 
@@ -54,6 +60,46 @@ func (c *RedisFrameCache) GetFrame(orgID int64, channel string) (json.RawMessage
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+// redisHistoryEntry is a frame in the history list of a channel. Frames are pushed with a
+// hash of their schema, only the last frames with the schema of the last one are returned.
+type redisHistoryEntry struct {
+	Time   int64           `json:"time"`
+	Schema string          `json:"schema"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+func (c *RedisFrameCache) GetHistory(orgID int64, channel string) ([]json.RawMessage, error) {
+	if !c.limits.enabled() {
+		return nil, nil
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	values, err := c.redisClient.LRange(key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	since := c.now().Add(-c.limits.maxAge).UnixNano() / int64(time.Millisecond)
+	var frames []json.RawMessage
+	var schema string
+	for i := len(values) - 1; i >= 0; i-- {
+		var entry redisHistoryEntry
+		if err := json.Unmarshal([]byte(values[i]), &entry); err != nil {
+			return nil, err
+		}
+		if i == len(values)-1 {
+			schema = entry.Schema
+		}
+		if entry.Time <= since || entry.Schema != schema {
+			break
+		}
+		frames = append(frames, entry.Frame)
+	}
+	// Reverse to have the oldest frames first.
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames, nil
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
 )
@@ -79,6 +125,20 @@ func (c *RedisFrameCache) Update(orgID int64, channel string, jsonFrame data.Fra
 		"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
 	})
 	pipe.Expire(key, frameCacheTTL)
+	if c.limits.enabled() {
+		historyKey := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+		entry, err := json.Marshal(redisHistoryEntry{
+			Time:   c.now().UnixNano() / int64(time.Millisecond),
+			Schema: schemaHash(stringSchema),
+			Frame:  jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
+		pipe.RPush(historyKey, string(entry))
+		pipe.LTrim(historyKey, -int64(c.limits.maxFrames), -1)
+		pipe.PExpire(historyKey, c.limits.maxAge)
+	}
 
 	replies, err := pipe.Exec()
 	if err != nil {
@@ -109,3 +169,13 @@ func (c *RedisFrameCache) Update(orgID int64, channel string, jsonFrame data.Fra
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
+
+func schemaHash(schema string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(schema))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
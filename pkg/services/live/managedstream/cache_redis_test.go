@@ -5,6 +5,7 @@ package managedstream
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/redis.v5"
@@ -18,3 +19,14 @@ func TestRedisCacheStorage(t *testing.T) {
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestRedisCacheStorage_History(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, redisClient.Del(getHistoryKey("1/history")).Err())
+	now := time.Now()
+	c := NewRedisFrameCache(redisClient, WithHistory(3, time.Minute))
+	c.now = func() time.Time { return now }
+	testFrameCacheHistory(t, c, &now)
+}
@@ -0,0 +1,189 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// sqlCleanupInterval is how often frames of inactive channels are deleted.
+const sqlCleanupInterval = time.Hour
+
+type sqlFrameRow struct {
+	FrameSchema string
+	Frame       string
+}
+
+type sqlHistoryRow struct {
+	Id    int64
+	Frame string
+}
+
+// SQLFrameCache keeps frames in the live_ha_frame and live_ha_frame_history tables of the
+// Grafana database, so that they are shared by Grafana instances.
+type SQLFrameCache struct {
+	mu     sync.RWMutex
+	store  *sqlstore.SQLStore
+	frames map[int64]map[string]data.FrameJSONCache
+	limits historyLimits
+	now    func() time.Time
+
+	lastCleanup time.Time
+}
+
+// NewSQLFrameCache creates a SQLFrameCache.
+func NewSQLFrameCache(store *sqlstore.SQLStore, opts ...FrameCacheOption) *SQLFrameCache {
+	return &SQLFrameCache{
+		store:  store,
+		frames: map[int64]map[string]data.FrameJSONCache{},
+		limits: newHistoryLimits(opts),
+		now:    time.Now,
+	}
+}
+
+func (c *SQLFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	frames, ok := c.frames[orgID]
+	if !ok {
+		return nil, nil
+	}
+	info := make(map[string]json.RawMessage, len(frames))
+	for k, v := range frames {
+		info[k] = v.Bytes(data.IncludeSchemaOnly)
+	}
+	return info, nil
+}
+
+func (c *SQLFrameCache) GetFrame(orgID int64, channel string) (json.RawMessage, bool, error) {
+	var row sqlFrameRow
+	var exists bool
+	err := c.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Table("live_ha_frame").Cols("frame").Where("org_id = ? AND channel = ?", orgID, channel).Get(&row)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	return json.RawMessage(row.Frame), true, nil
+}
+
+func (c *SQLFrameCache) GetHistory(orgID int64, channel string) ([]json.RawMessage, error) {
+	if !c.limits.enabled() {
+		return nil, nil
+	}
+	since := toMillis(c.now().Add(-c.limits.maxAge))
+	var rows []sqlHistoryRow
+	err := c.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("live_ha_frame_history").Cols("id", "frame").
+			Where("org_id = ? AND channel = ? AND created > ?", orgID, channel, since).
+			Asc("id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		frames = append(frames, json.RawMessage(row.Frame))
+	}
+	return frames, nil
+}
+
+func (c *SQLFrameCache) Update(orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
+	c.mu.Lock()
+	if _, ok := c.frames[orgID]; !ok {
+		c.frames[orgID] = map[string]data.FrameJSONCache{}
+	}
+	c.frames[orgID][channel] = jsonFrame
+	c.mu.Unlock()
+
+	stringSchema := string(jsonFrame.Bytes(data.IncludeSchemaOnly))
+	frame := string(jsonFrame.Bytes(data.IncludeAll))
+	now := toMillis(c.now())
+
+	var schemaUpdated bool
+	err := c.store.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var existing sqlFrameRow
+		exists, err := sess.Table("live_ha_frame").Cols("frame_schema").Where("org_id = ? AND channel = ?", orgID, channel).Get(&existing)
+		if err != nil {
+			return err
+		}
+		schemaUpdated = !exists || existing.FrameSchema != stringSchema
+
+		upsertSQL := c.store.Dialect.UpsertSQL(
+			"live_ha_frame",
+			[]string{"org_id", "channel"},
+			[]string{"org_id", "channel", "frame_schema", "frame", "updated"})
+		if _, err := sess.Exec(upsertSQL, orgID, channel, stringSchema, frame, now); err != nil {
+			return err
+		}
+
+		if !c.limits.enabled() {
+			return nil
+		}
+		if schemaUpdated {
+			// Frames with another schema cannot be merged with the next ones.
+			if _, err := sess.Exec("DELETE FROM live_ha_frame_history WHERE org_id = ? AND channel = ?", orgID, channel); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Exec("INSERT INTO live_ha_frame_history (org_id, channel, frame, created) VALUES (?, ?, ?, ?)", orgID, channel, frame, now); err != nil {
+			return err
+		}
+		return c.trimHistory(sess, orgID, channel, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	return schemaUpdated, c.cleanup()
+}
+
+// trimHistory deletes the frames of a channel beyond history limits.
+func (c *SQLFrameCache) trimHistory(sess *sqlstore.DBSession, orgID int64, channel string, now int64) error {
+	var oldest sqlHistoryRow
+	exists, err := sess.Table("live_ha_frame_history").Cols("id").
+		Where("org_id = ? AND channel = ?", orgID, channel).
+		Desc("id").Limit(1, c.limits.maxFrames).Get(&oldest)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if _, err := sess.Exec("DELETE FROM live_ha_frame_history WHERE org_id = ? AND channel = ? AND id <= ?", orgID, channel, oldest.Id); err != nil {
+			return err
+		}
+	}
+	_, err = sess.Exec("DELETE FROM live_ha_frame_history WHERE org_id = ? AND channel = ? AND created <= ?",
+		orgID, channel, now-c.limits.maxAge.Milliseconds())
+	return err
+}
+
+// cleanup deletes the frames of channels which were not updated for a long time, like keys
+// expire in Redis. It runs at most once per sqlCleanupInterval.
+func (c *SQLFrameCache) cleanup() error {
+	now := c.now()
+	c.mu.Lock()
+	if now.Sub(c.lastCleanup) < sqlCleanupInterval {
+		c.mu.Unlock()
+		return nil
+	}
+	c.lastCleanup = now
+	c.mu.Unlock()
+
+	return c.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM live_ha_frame WHERE updated < ?", toMillis(now.Add(-frameCacheTTL))); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM live_ha_frame_history WHERE created < ?", toMillis(now.Add(-c.limits.maxAge)))
+		return err
+	})
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,53 @@
+//go:build integration
+// +build integration
+
+package managedstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLFrameCache(t *testing.T) {
+	c := NewSQLFrameCache(sqlstore.InitTestDB(t))
+	require.NotNil(t, c)
+	testFrameCache(t, c)
+}
+
+func TestSQLFrameCache_History(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewSQLFrameCache(sqlstore.InitTestDB(t), WithHistory(3, time.Minute))
+	c.now = func() time.Time { return now }
+	testFrameCacheHistory(t, c, &now)
+}
+
+func TestSQLFrameCache_SharedByInstances(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	c1 := NewSQLFrameCache(store, WithHistory(3, time.Minute))
+	c2 := NewSQLFrameCache(store, WithHistory(3, time.Minute))
+
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+	updated, err := c1.Update(1, "test", frameJsonCache)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	// The schema is shared, the other instance does not see a schema change.
+	updated, err = c2.Update(1, "test", frameJsonCache)
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	frameJSON, ok, err := c1.GetFrame(1, "test")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.JSONEq(t, string(frameJsonCache.Bytes(data.IncludeAll)), string(frameJSON))
+
+	frames, err := c1.GetHistory(1, "test")
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+}
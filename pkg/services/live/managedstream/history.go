@@ -0,0 +1,110 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// historyLimits limit the recent frames a FrameCache keeps per channel. History is disabled
+// when maxFrames is zero.
+type historyLimits struct {
+	maxFrames int
+	maxAge    time.Duration
+}
+
+func (l historyLimits) enabled() bool {
+	return l.maxFrames > 0 && l.maxAge > 0
+}
+
+// FrameCacheOption configures the history kept by a FrameCache.
+type FrameCacheOption func(*historyLimits)
+
+// WithHistory makes a FrameCache keep the recent frames of each channel, at most maxFrames
+// frames pushed during the last maxAge.
+func WithHistory(maxFrames int, maxAge time.Duration) FrameCacheOption {
+	return func(l *historyLimits) {
+		l.maxFrames = maxFrames
+		l.maxAge = maxAge
+	}
+}
+
+func newHistoryLimits(opts []FrameCacheOption) historyLimits {
+	var l historyLimits
+	for _, opt := range opts {
+		opt(&l)
+	}
+	return l
+}
+
+type historyEntry struct {
+	time  time.Time
+	frame json.RawMessage
+}
+
+// frameHistory is a ring buffer of the recent frames of a channel.
+type frameHistory struct {
+	entries []historyEntry
+	// next is the index of the next entry to write, and of the oldest entry once full.
+	next int
+	full bool
+}
+
+func newFrameHistory(maxFrames int) *frameHistory {
+	return &frameHistory{entries: make([]historyEntry, maxFrames)}
+}
+
+func (h *frameHistory) add(t time.Time, frame json.RawMessage) {
+	h.entries[h.next] = historyEntry{time: t, frame: frame}
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since returns the frames added after t, oldest first.
+func (h *frameHistory) since(t time.Time) []json.RawMessage {
+	var frames []json.RawMessage
+	start, n := 0, h.next
+	if h.full {
+		start, n = h.next, len(h.entries)
+	}
+	for i := 0; i < n; i++ {
+		e := h.entries[(start+i)%len(h.entries)]
+		if e.time.After(t) {
+			frames = append(frames, e.frame)
+		}
+	}
+	return frames
+}
+
+// mergeFrames merges frames with the same schema into one frame with the rows of all of them.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	if len(frames) == 1 {
+		return frames[0], nil
+	}
+	merged := &data.Frame{}
+	if err := json.Unmarshal(frames[0], merged); err != nil {
+		return nil, err
+	}
+	for _, b := range frames[1:] {
+		frame := &data.Frame{}
+		if err := json.Unmarshal(b, frame); err != nil {
+			return nil, err
+		}
+		if len(frame.Fields) != len(merged.Fields) {
+			return nil, fmt.Errorf("frames have different schemas")
+		}
+		for i, f := range frame.Fields {
+			if f.Type() != merged.Fields[i].Type() {
+				return nil, fmt.Errorf("frames have different schemas")
+			}
+			for j := 0; j < f.Len(); j++ {
+				merged.Fields[i].Append(f.At(j))
+			}
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
@@ -86,6 +86,27 @@ func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
 	return channels, nil
 }
 
+// GetRecentFrame returns the recent frames of a channel merged into one frame, or the last
+// frame of the channel if there is no history.
+func (r *Runner) GetRecentFrame(orgID int64, channel string) (json.RawMessage, bool, error) {
+	return recentFrame(r.frameCache, orgID, channel)
+}
+
+func recentFrame(frameCache FrameCache, orgID int64, channel string) (json.RawMessage, bool, error) {
+	frames, err := frameCache.GetHistory(orgID, channel)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(frames) == 0 {
+		return frameCache.GetFrame(orgID, channel)
+	}
+	frameJSON, err := mergeFrames(frames)
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
+
 // Streams returns a map of active managed streams (per streamID).
 func (r *Runner) Streams(orgID int64) map[string]*ManagedStream {
 	r.mu.RLock()
@@ -221,7 +242,8 @@ func (s *ManagedStream) GetHandlerForPath(_ string) (models.ChannelHandler, erro
 
 func (s *ManagedStream) OnSubscribe(_ context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := models.SubscribeReply{}
-	frameJSON, ok, err := s.frameCache.GetFrame(u.OrgId, e.Channel)
+	// Subscribers get the recent frames, so that they do not start without data.
+	frameJSON, ok, err := recentFrame(s.frameCache, u.OrgId, e.Channel)
 	if err != nil {
 		return reply, 0, err
 	}
@@ -1,11 +1,16 @@
 package managedstream
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type testPublisher struct {
@@ -82,3 +87,28 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 6) // Not affected by other org.
 }
+
+func TestManagedStream_OnSubscribe(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	s := NewManagedStream("a", 1, publisher.publish, NewMemoryFrameCache(WithHistory(10, time.Minute)))
+	for i := 0; i < 3; i++ {
+		err := s.Push("cpu", data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		))
+		require.NoError(t, err)
+	}
+
+	reply, status, err := s.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1}, models.SubscribeEvent{Channel: "stream/a/cpu", Path: "cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 3, frame.Rows(), "subscribers get the recent frames merged")
+	require.Equal(t, []float64{0, 1, 2}, []float64{frame.Fields[1].At(0).(float64), frame.Fields[1].At(1).(float64), frame.Fields[1].At(2).(float64)})
+
+	reply, _, err = s.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1}, models.SubscribeEvent{Channel: "stream/a/memory", Path: "memory"})
+	require.NoError(t, err)
+	require.Nil(t, reply.Data)
+}
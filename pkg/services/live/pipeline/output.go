@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+)
+
+// managedStreamOutput pushes the frames to the managed stream of their channel.
+type managedStreamOutput struct {
+	push func(vars Vars, frame *data.Frame) error
+}
+
+func (o managedStreamOutput) Output(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	return nil, o.push(vars, frame)
+}
+
+// RouteSettings route the fields of the frames to other channels by the value of a label.
+// The fields without the label are not routed. The frames of the other channels are
+// processed by their rules.
+type RouteSettings struct {
+	Label string `json:"label"`
+	// Routes are the channels of values of the label.
+	Routes []Route `json:"routes,omitempty"`
+	// Channel is the channel of the values without a route, where ${value} is replaced by
+	// the value. The fields of these values are not routed if it is empty.
+	Channel string `json:"channel,omitempty"`
+}
+
+// Route is the channel of a value of the label.
+type Route struct {
+	Value   string `json:"value"`
+	Channel string `json:"channel"`
+}
+
+const routeValueVar = "${value}"
+
+type routeOutput struct {
+	label    string
+	channels map[string]string
+	channel  string
+}
+
+func newRouteOutput(s RouteSettings) (*routeOutput, error) {
+	if s.Label == "" {
+		return nil, errors.New("the label is missing")
+	}
+	if len(s.Routes) == 0 && s.Channel == "" {
+		return nil, errors.New("no routes")
+	}
+	o := &routeOutput{label: s.Label, channels: make(map[string]string, len(s.Routes)), channel: s.Channel}
+	for _, r := range s.Routes {
+		if err := validateStreamChannel(r.Channel); err != nil {
+			return nil, err
+		}
+		o.channels[r.Value] = r.Channel
+	}
+	if s.Channel != "" {
+		if err := validateStreamChannel(strings.ReplaceAll(s.Channel, routeValueVar, "value")); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *routeOutput) Output(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	var timeFields []*data.Field
+	var values []string
+	fieldsByValue := map[string][]*data.Field{}
+	for _, f := range frame.Fields {
+		if f.Type().Time() {
+			timeFields = append(timeFields, f)
+			continue
+		}
+		value, ok := f.Labels[o.label]
+		if !ok {
+			continue
+		}
+		if _, ok := fieldsByValue[value]; !ok {
+			values = append(values, value)
+		}
+		fieldsByValue[value] = append(fieldsByValue[value], f)
+	}
+
+	var channelFrames []*ChannelFrame
+	for _, value := range values {
+		channel, ok := o.channels[value]
+		if !ok {
+			if o.channel == "" {
+				continue
+			}
+			channel = strings.ReplaceAll(o.channel, routeValueVar, value)
+		}
+		if err := validateStreamChannel(channel); err != nil {
+			logger.Warn("Cannot route fields", "channel", vars.Channel, "label", o.label, "value", value, "error", err)
+			continue
+		}
+		routed := data.NewFrame(frame.Name, append(append([]*data.Field{}, timeFields...), fieldsByValue[value]...)...)
+		routed.RefID, routed.Meta = frame.RefID, frame.Meta
+		channelFrames = append(channelFrames, &ChannelFrame{Channel: channel, Frame: routed})
+	}
+	return channelFrames, nil
+}
+
+// validateStreamChannel checks that a channel is a valid channel of the stream scope.
+func validateStreamChannel(channel string) error {
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return fmt.Errorf("invalid channel %q: %w", channel, err)
+	}
+	if addr.Scope != live.ScopeStream {
+		return fmt.Errorf("invalid channel %q: frames can only be routed to stream channels", channel)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// defaultResolveTimeout is how long the alerts of threshold outputs fire without frames.
+const defaultResolveTimeout = 5 * time.Minute
+
+// ThresholdSettings send an alert for each series of a numeric field while its last value
+// crosses a threshold, and resolve the alert when the value is back.
+type ThresholdSettings struct {
+	FieldName string `json:"fieldName"`
+	// Operator compares the value with the threshold: >, >=, < or <=.
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	// AlertName is the alertname label of the alerts, the name of the field by default.
+	AlertName   string            `json:"alertName,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ResolveTimeout is how long an alert fires if no frames come, 5m by default.
+	ResolveTimeout string `json:"resolveTimeout,omitempty"`
+}
+
+// Alert is an alert of a threshold output. Alerts have the labels of their series, a channel
+// label and the labels of the output, and a value annotation with the last value.
+type Alert struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	StartsAt    time.Time
+	EndsAt      time.Time
+}
+
+// AlertSender sends the alerts of threshold outputs to alerting.
+type AlertSender interface {
+	SendAlerts(ctx context.Context, orgID int64, alerts []*Alert) error
+}
+
+var operators = map[string]func(v, threshold float64) bool{
+	">":  func(v, threshold float64) bool { return v > threshold },
+	">=": func(v, threshold float64) bool { return v >= threshold },
+	"<":  func(v, threshold float64) bool { return v < threshold },
+	"<=": func(v, threshold float64) bool { return v <= threshold },
+}
+
+type thresholdOutput struct {
+	settings       ThresholdSettings
+	crosses        func(v, threshold float64) bool
+	resolveTimeout time.Duration
+	now            func() time.Time
+	alertSender    func() AlertSender
+
+	mu     sync.Mutex
+	firing map[string]*firingAlert
+}
+
+// firingAlert is the state of the alert of a series.
+type firingAlert struct {
+	startsAt time.Time
+	lastSent time.Time
+}
+
+func newThresholdOutput(s ThresholdSettings, now func() time.Time, alertSender func() AlertSender) (*thresholdOutput, error) {
+	if s.FieldName == "" {
+		return nil, errors.New("the field name is missing")
+	}
+	crosses, ok := operators[s.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q", s.Operator)
+	}
+	resolveTimeout := defaultResolveTimeout
+	if s.ResolveTimeout != "" {
+		var err error
+		if resolveTimeout, err = parseInterval(s.ResolveTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.AlertName == "" {
+		s.AlertName = s.FieldName
+	}
+	return &thresholdOutput{
+		settings:       s,
+		crosses:        crosses,
+		resolveTimeout: resolveTimeout,
+		now:            now,
+		alertSender:    alertSender,
+		firing:         map[string]*firingAlert{},
+	}, nil
+}
+
+func (o *thresholdOutput) Output(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	var alerts []*Alert
+	for _, f := range frame.Fields {
+		if f.Name != o.settings.FieldName || !f.Type().Numeric() {
+			continue
+		}
+		v, ok, err := lastValue(f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		key := f.Labels.String()
+		state, firing := o.firing[key]
+		switch {
+		case o.crosses(v, o.settings.Threshold):
+			if !firing {
+				state = &firingAlert{startsAt: now}
+				o.firing[key] = state
+			} else if now.Sub(state.lastSent) < o.resolveTimeout/2 {
+				// The alert has been sent recently enough not to be resolved.
+				continue
+			}
+			state.lastSent = now
+			alerts = append(alerts, o.alert(vars, f.Labels, v, state.startsAt, now.Add(o.resolveTimeout)))
+		case firing:
+			delete(o.firing, key)
+			alerts = append(alerts, o.alert(vars, f.Labels, v, state.startsAt, now))
+		}
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	sender := o.alertSender()
+	if sender == nil {
+		logger.Debug("Alerts of threshold output dropped, alerting is not available", "channel", vars.Channel)
+		return nil, nil
+	}
+	// The frames are still pushed if the alerts cannot be sent, an Alertmanager problem must
+	// not stop the ingestion of data.
+	if err := sender.SendAlerts(ctx, vars.OrgID, alerts); err != nil {
+		logger.Error("Failed to send the alerts of threshold output", "channel", vars.Channel, "orgId", vars.OrgID, "error", err)
+	}
+	return nil, nil
+}
+
+func (o *thresholdOutput) alert(vars Vars, labels data.Labels, value float64, startsAt, endsAt time.Time) *Alert {
+	alert := &Alert{
+		Labels:      make(map[string]string, len(labels)+len(o.settings.Labels)+2),
+		Annotations: make(map[string]string, len(o.settings.Annotations)+1),
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+	}
+	for k, v := range labels {
+		alert.Labels[k] = v
+	}
+	alert.Labels["channel"] = vars.Channel
+	for k, v := range o.settings.Labels {
+		alert.Labels[k] = v
+	}
+	alert.Labels["alertname"] = o.settings.AlertName
+	for k, v := range o.settings.Annotations {
+		alert.Annotations[k] = v
+	}
+	alert.Annotations["value"] = strconv.FormatFloat(value, 'f', -1, 64)
+	return alert
+}
+
+// lastValue returns the last value of a numeric field that is not null, if any.
+func lastValue(f *data.Field) (float64, bool, error) {
+	for i := f.Len() - 1; i >= 0; i-- {
+		v, err := f.NullableFloatAt(i)
+		if err != nil {
+			return 0, false, err
+		}
+		if v != nil {
+			return *v, true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAlertSender struct {
+	alerts []*Alert
+	err    error
+}
+
+func (s *fakeAlertSender) SendAlerts(_ context.Context, _ int64, alerts []*Alert) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.alerts = append(s.alerts, alerts...)
+	return nil
+}
+
+func TestThresholdOutput(t *testing.T) {
+	now := time.Unix(1000, 0)
+	sender := &fakeAlertSender{}
+	o, err := newThresholdOutput(ThresholdSettings{
+		FieldName:   "usage_user",
+		Operator:    ">",
+		Threshold:   90,
+		AlertName:   "HighCPU",
+		Labels:      map[string]string{"severity": "warning"},
+		Annotations: map[string]string{"summary": "CPU usage is high"},
+	}, func() time.Time { return now }, func() AlertSender { return sender })
+	require.NoError(t, err)
+
+	vars := Vars{OrgID: 1, Channel: "stream/telegraf/cpu"}
+	output := func(a, b float64) {
+		t.Helper()
+		frame := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{now}),
+			data.NewField("usage_user", data.Labels{"host": "a"}, []float64{a}),
+			data.NewField("usage_user", data.Labels{"host": "b"}, []float64{b}),
+		)
+		channelFrames, err := o.Output(context.Background(), vars, frame)
+		require.NoError(t, err)
+		require.Empty(t, channelFrames)
+	}
+
+	output(95, 50)
+	require.Len(t, sender.alerts, 1)
+	alert := sender.alerts[0]
+	require.Equal(t, map[string]string{
+		"alertname": "HighCPU",
+		"channel":   "stream/telegraf/cpu",
+		"host":      "a",
+		"severity":  "warning",
+	}, alert.Labels)
+	require.Equal(t, map[string]string{"summary": "CPU usage is high", "value": "95"}, alert.Annotations)
+	require.Equal(t, now, alert.StartsAt)
+	require.Equal(t, now.Add(defaultResolveTimeout), alert.EndsAt)
+
+	startsAt := now
+	now = now.Add(time.Minute)
+	output(96, 50)
+	require.Len(t, sender.alerts, 1, "firing alerts are not sent again until half the resolve timeout")
+
+	now = now.Add(2 * time.Minute)
+	output(97, 50)
+	require.Len(t, sender.alerts, 2)
+	require.Equal(t, startsAt, sender.alerts[1].StartsAt)
+	require.Equal(t, now.Add(defaultResolveTimeout), sender.alerts[1].EndsAt)
+
+	now = now.Add(time.Minute)
+	output(50, 50)
+	require.Len(t, sender.alerts, 3)
+	require.Equal(t, "a", sender.alerts[2].Labels["host"])
+	require.Equal(t, now, sender.alerts[2].EndsAt, "the alert is resolved")
+
+	output(50, 50)
+	require.Len(t, sender.alerts, 3)
+}
+
+func TestThresholdOutput_AlertsThatCannotBeSent(t *testing.T) {
+	sender := &fakeAlertSender{err: errors.New("no Alertmanager for organisation")}
+	o, err := newThresholdOutput(ThresholdSettings{
+		FieldName: "usage_user",
+		Operator:  ">",
+		Threshold: 90,
+		AlertName: "HighCPU",
+	}, time.Now, func() AlertSender { return sender })
+	require.NoError(t, err)
+
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{time.Now()}),
+		data.NewField("usage_user", data.Labels{"host": "a"}, []float64{95}),
+	)
+	_, err = o.Output(context.Background(), Vars{OrgID: 1, Channel: "stream/telegraf/cpu"}, frame)
+	require.NoError(t, err, "the frames are output even if the alerts cannot be sent")
+}
@@ -0,0 +1,318 @@
+// Package pipeline processes the frames pushed to Grafana Live managed streams on the server,
+// before they are published to their channels.
+//
+// A channel rule of an organization configures the processing of the frames of a channel of
+// the stream scope: the frames go through the processors of the rule in order, e.g. to filter
+// fields or to convert units, then to each of its outputs. The frames of a channel without a
+// rule, or of a rule without outputs, are pushed to the managed stream of the channel.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+)
+
+var (
+	logger = log.New("live.pipeline")
+)
+
+const (
+	// rulesCacheTTL is how long the channel rules of an organization are used before they
+	// are loaded again, so that the changes made on other instances are picked up.
+	rulesCacheTTL = 10 * time.Second
+
+	// maxRouteDepth is the maximum number of times a frame is routed to another channel,
+	// to break routing loops.
+	maxRouteDepth = 4
+)
+
+// Vars are the variables of the processing of a frame.
+type Vars struct {
+	OrgID int64
+	// Channel is the channel of the frame, e.g. stream/telegraf/cpu.
+	Channel  string
+	StreamID string
+	Path     string
+}
+
+// ChannelFrame is a frame output to another channel.
+type ChannelFrame struct {
+	Channel string
+	Frame   *data.Frame
+}
+
+// FrameProcessor transforms frames. A processor returns a nil frame to drop the frame.
+// Processors must not modify the frames they get.
+type FrameProcessor interface {
+	Process(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error)
+}
+
+// FrameOutputter outputs frames. The frames it returns are processed in their channels.
+type FrameOutputter interface {
+	Output(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error)
+}
+
+// RuleStorage gets the channel rules of organizations.
+type RuleStorage interface {
+	ListChannelRules(query *models.ListLiveChannelRulesQuery) ([]*models.LiveChannelRule, error)
+}
+
+// Pipeline processes the frames pushed to managed streams according to the channel rules.
+type Pipeline struct {
+	storage RuleStorage
+	runner  *managedstream.Runner
+	now     func() time.Time
+
+	alertSenderMu sync.RWMutex
+	alertSender   AlertSender
+
+	// mu only guards orgs: the rules of an organization are loaded without holding it, so
+	// that the frames of the other organizations do not wait for them.
+	mu   sync.Mutex
+	orgs map[int64]*orgRules
+}
+
+// orgRules are the channel rules of an organization, by channel. While they are loaded
+// again, the frames of the organization are processed with the previous rules.
+type orgRules struct {
+	mu     sync.Mutex
+	loaded time.Time
+	// loading is true while the rules are loaded.
+	loading bool
+	// version is incremented when the rules are invalidated, so that a load that started
+	// before does not mark them as loaded.
+	version int
+	rules   map[string]*channelRule
+
+	// ready is closed once the rules are loaded for the first time.
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// channelRule is a channel rule with its processors and outputs. They can have a state,
+// e.g. the last time a frame passed, so they are kept until the rule is updated.
+type channelRule struct {
+	updated    time.Time
+	converter  *ConverterSettings
+	processors []FrameProcessor
+	outputters []FrameOutputter
+}
+
+// New creates a Pipeline that pushes the frames to the managed streams of runner.
+func New(storage RuleStorage, runner *managedstream.Runner) *Pipeline {
+	return &Pipeline{
+		storage: storage,
+		runner:  runner,
+		now:     time.Now,
+		orgs:    map[int64]*orgRules{},
+	}
+}
+
+// SetAlertSender sets where the alerts of threshold outputs are sent to. The alerts are
+// discarded until it is set.
+func (p *Pipeline) SetAlertSender(sender AlertSender) {
+	p.alertSenderMu.Lock()
+	defer p.alertSenderMu.Unlock()
+	p.alertSender = sender
+}
+
+func (p *Pipeline) getAlertSender() AlertSender {
+	p.alertSenderMu.RLock()
+	defer p.alertSenderMu.RUnlock()
+	return p.alertSender
+}
+
+// InvalidateRules makes the next frame of the organization load its channel rules.
+func (p *Pipeline) InvalidateRules(orgID int64) {
+	p.mu.Lock()
+	org, ok := p.orgs[orgID]
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	org.mu.Lock()
+	defer org.mu.Unlock()
+	org.loaded = time.Time{}
+	org.version++
+}
+
+// ProcessFrame processes a frame pushed to the path of the managed stream.
+func (p *Pipeline) ProcessFrame(ctx context.Context, orgID int64, streamID string, path string, frame *data.Frame) error {
+	channel := live.Channel{Scope: live.ScopeStream, Namespace: streamID, Path: path}.String()
+	return p.processChannelFrame(ctx, orgID, channel, frame, 0)
+}
+
+// StreamConverter returns the converter settings of the rule of the channel of the stream,
+// or nil if there are none.
+func (p *Pipeline) StreamConverter(orgID int64, streamID string) *ConverterSettings {
+	rule := p.getChannelRule(orgID, live.Channel{Scope: live.ScopeStream, Namespace: streamID}.String())
+	if rule == nil {
+		return nil
+	}
+	return rule.converter
+}
+
+func (p *Pipeline) processChannelFrame(ctx context.Context, orgID int64, channel string, frame *data.Frame, depth int) error {
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return fmt.Errorf("invalid channel %q: %w", channel, err)
+	}
+	if addr.Scope != live.ScopeStream {
+		return fmt.Errorf("frames can only be pushed to stream channels: %s", channel)
+	}
+	vars := Vars{OrgID: orgID, Channel: channel, StreamID: addr.Namespace, Path: addr.Path}
+
+	rule := p.getChannelRule(orgID, channel)
+	if rule == nil {
+		return p.pushToStream(vars, frame)
+	}
+
+	for _, processor := range rule.processors {
+		frame, err = processor.Process(ctx, vars, frame)
+		if err != nil {
+			return fmt.Errorf("failed to process frame of channel %s: %w", channel, err)
+		}
+		if frame == nil {
+			return nil
+		}
+	}
+
+	if len(rule.outputters) == 0 {
+		return p.pushToStream(vars, frame)
+	}
+	for _, outputter := range rule.outputters {
+		channelFrames, err := outputter.Output(ctx, vars, frame)
+		if err != nil {
+			return fmt.Errorf("failed to output frame of channel %s: %w", channel, err)
+		}
+		for _, cf := range channelFrames {
+			if depth >= maxRouteDepth {
+				return fmt.Errorf("frame of channel %s routed more than %d times", channel, maxRouteDepth)
+			}
+			if err := p.processChannelFrame(ctx, orgID, cf.Channel, cf.Frame, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) pushToStream(vars Vars, frame *data.Frame) error {
+	stream, err := p.runner.GetOrCreateStream(vars.OrgID, vars.StreamID)
+	if err != nil {
+		return err
+	}
+	return stream.Push(vars.Path, frame)
+}
+
+// getChannelRule returns the rule of the channel, or nil if it has none.
+func (p *Pipeline) getChannelRule(orgID int64, channel string) *channelRule {
+	org := p.getOrgRules(orgID)
+
+	org.mu.Lock()
+	load := !org.loading && p.now().Sub(org.loaded) >= rulesCacheTTL
+	if load {
+		org.loading = true
+	}
+	org.mu.Unlock()
+
+	if load {
+		p.loadRules(orgID, org)
+	}
+	<-org.ready
+
+	org.mu.Lock()
+	defer org.mu.Unlock()
+	return org.rules[channel]
+}
+
+func (p *Pipeline) getOrgRules(orgID int64) *orgRules {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	org, ok := p.orgs[orgID]
+	if !ok {
+		org = &orgRules{ready: make(chan struct{})}
+		p.orgs[orgID] = org
+	}
+	return org
+}
+
+// loadRules loads the channel rules of the organization and swaps them in. The rules that
+// have not been updated are kept, with their state. The rules are kept as they are if they
+// cannot be loaded.
+func (p *Pipeline) loadRules(orgID int64, org *orgRules) {
+	org.mu.Lock()
+	version, existing := org.version, org.rules
+	org.mu.Unlock()
+
+	rules, err := p.buildRules(orgID, existing)
+	if err != nil {
+		logger.Error("Error loading channel rules", "orgId", orgID, "error", err)
+	}
+
+	org.mu.Lock()
+	if err == nil {
+		org.rules = rules
+	}
+	if org.version == version {
+		org.loaded = p.now()
+	}
+	org.loading = false
+	org.mu.Unlock()
+	org.readyOnce.Do(func() { close(org.ready) })
+}
+
+func (p *Pipeline) buildRules(orgID int64, existing map[string]*channelRule) (map[string]*channelRule, error) {
+	stored, err := p.storage.ListChannelRules(&models.ListLiveChannelRulesQuery{OrgId: orgID})
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]*channelRule, len(stored))
+	for _, r := range stored {
+		if rule, ok := existing[r.Channel]; ok && rule.updated.Equal(r.Updated) {
+			rules[r.Channel] = rule
+			continue
+		}
+		rule, err := p.buildChannelRule(r)
+		if err != nil {
+			// Without its rule the frames of the channel are pushed as they are.
+			logger.Error("Invalid channel rule", "orgId", orgID, "channel", r.Channel, "error", err)
+			continue
+		}
+		rules[r.Channel] = rule
+	}
+	return rules, nil
+}
+
+func (p *Pipeline) buildChannelRule(r *models.LiveChannelRule) (*channelRule, error) {
+	settings, err := ParseChannelRuleSettings(r.Settings)
+	if err != nil {
+		return nil, err
+	}
+	rule := &channelRule{updated: r.Updated, converter: settings.Converter}
+	for _, s := range settings.Processors {
+		processor, err := p.buildProcessor(s)
+		if err != nil {
+			return nil, err
+		}
+		rule.processors = append(rule.processors, processor)
+	}
+	for _, s := range settings.Outputs {
+		outputter, err := p.buildOutputter(s)
+		if err != nil {
+			return nil, err
+		}
+		rule.outputters = append(rule.outputters, outputter)
+	}
+	return rule, nil
+}
@@ -0,0 +1,281 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+)
+
+type fakeRuleStorage struct {
+	mu    sync.Mutex
+	rules []*models.LiveChannelRule
+	calls int
+
+	// The queries of blockedOrg signal started and wait for unblock.
+	blockedOrg int64
+	started    chan struct{}
+	unblock    chan struct{}
+}
+
+func (s *fakeRuleStorage) ListChannelRules(query *models.ListLiveChannelRulesQuery) ([]*models.LiveChannelRule, error) {
+	if query.OrgId == s.blockedOrg {
+		close(s.started)
+		<-s.unblock
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.rules, nil
+}
+
+type publication struct {
+	channel string
+	frame   *data.Frame
+}
+
+func setupPipeline(t *testing.T, rules ...*models.LiveChannelRule) (*Pipeline, *fakeRuleStorage, *[]publication) {
+	t.Helper()
+	var published []publication
+	publisher := func(_ int64, channel string, b []byte) error {
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(b, frame))
+		published = append(published, publication{channel: channel, frame: frame})
+		return nil
+	}
+	storage := &fakeRuleStorage{rules: rules}
+	p := New(storage, managedstream.NewRunner(publisher, managedstream.NewMemoryFrameCache()))
+	return p, storage, &published
+}
+
+func storedRule(t *testing.T, channel string, settings string) *models.LiveChannelRule {
+	t.Helper()
+	js, err := simplejson.NewJson([]byte(settings))
+	require.NoError(t, err)
+	return &models.LiveChannelRule{OrgId: 1, Channel: channel, Settings: js, Updated: time.Unix(1, 0)}
+}
+
+func cpuFrame() *data.Frame {
+	return data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{time.Unix(10, 0)}),
+		data.NewField("usage_user", data.Labels{"host": "a"}, []float64{10}),
+		data.NewField("usage_system", data.Labels{"host": "a"}, []float64{5}),
+		data.NewField("usage_user", data.Labels{"host": "b"}, []float64{20}),
+	)
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestPipeline_ProcessFrame(t *testing.T) {
+	t.Run("frames of channels without rules are pushed as they are", func(t *testing.T) {
+		p, _, published := setupPipeline(t)
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.Len(t, *published, 1)
+		require.Equal(t, "stream/telegraf/cpu", (*published)[0].channel)
+		require.Equal(t, []string{"time", "usage_user", "usage_system", "usage_user"}, fieldNames((*published)[0].frame))
+	})
+
+	t.Run("processors transform frames in order", func(t *testing.T) {
+		p, _, published := setupPipeline(t, storedRule(t, "stream/telegraf/cpu", `{
+			"processors": [
+				{"type": "dropFields", "dropFields": {"fieldNames": ["usage_system"]}},
+				{"type": "renameFields", "renameFields": {"names": {"usage_user": "user"}}}
+			]
+		}`))
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.Len(t, *published, 1)
+		require.Equal(t, []string{"time", "user", "user"}, fieldNames((*published)[0].frame))
+	})
+
+	t.Run("frames dropped by a processor are not output", func(t *testing.T) {
+		p, _, published := setupPipeline(t, storedRule(t, "stream/telegraf/cpu", `{
+			"processors": [{"type": "throttle", "throttle": {"interval": "1m"}}]
+		}`))
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.Len(t, *published, 1)
+	})
+
+	t.Run("routed frames are processed by the rules of their channels", func(t *testing.T) {
+		p, _, published := setupPipeline(t,
+			storedRule(t, "stream/telegraf/cpu", `{
+				"outputs": [{"type": "route", "route": {"label": "host", "channel": "stream/hosts/${value}"}}]
+			}`),
+			storedRule(t, "stream/hosts/b", `{
+				"processors": [{"type": "keepFields", "keepFields": {"fieldNames": ["usage_user"]}}]
+			}`),
+		)
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.Len(t, *published, 2)
+		require.Equal(t, "stream/hosts/a", (*published)[0].channel)
+		require.Equal(t, []string{"time", "usage_user", "usage_system"}, fieldNames((*published)[0].frame))
+		require.Equal(t, "stream/hosts/b", (*published)[1].channel)
+		require.Equal(t, []string{"time", "usage_user"}, fieldNames((*published)[1].frame))
+	})
+
+	t.Run("routing loops are broken", func(t *testing.T) {
+		p, _, _ := setupPipeline(t, storedRule(t, "stream/telegraf/cpu", `{
+			"outputs": [{"type": "route", "route": {"label": "host", "routes": [{"value": "a", "channel": "stream/telegraf/cpu"}]}}]
+		}`))
+		err := p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "routed more than")
+	})
+
+	t.Run("frames of channels with invalid rules are pushed as they are", func(t *testing.T) {
+		p, _, published := setupPipeline(t, storedRule(t, "stream/telegraf/cpu", `{
+			"processors": [{"type": "unknown"}]
+		}`))
+		require.NoError(t, p.ProcessFrame(context.Background(), 1, "telegraf", "cpu", cpuFrame()))
+		require.Len(t, *published, 1)
+		require.Len(t, (*published)[0].frame.Fields, 4)
+	})
+}
+
+func TestPipeline_Rules(t *testing.T) {
+	p, storage, _ := setupPipeline(t)
+	now := time.Unix(100, 0)
+	p.now = func() time.Time { return now }
+
+	require.Nil(t, p.getChannelRule(1, "stream/telegraf/cpu"))
+	require.Equal(t, 1, storage.calls)
+
+	storage.rules = []*models.LiveChannelRule{storedRule(t, "stream/telegraf/cpu", `{}`)}
+	require.Nil(t, p.getChannelRule(1, "stream/telegraf/cpu"), "the rules are cached")
+	require.Equal(t, 1, storage.calls)
+
+	p.InvalidateRules(1)
+	rule := p.getChannelRule(1, "stream/telegraf/cpu")
+	require.NotNil(t, rule)
+	require.Equal(t, 2, storage.calls)
+
+	now = now.Add(rulesCacheTTL)
+	require.Same(t, rule, p.getChannelRule(1, "stream/telegraf/cpu"), "rules that are not updated are kept")
+	require.Equal(t, 3, storage.calls)
+
+	storage.rules = []*models.LiveChannelRule{storedRule(t, "stream/telegraf/cpu", `{}`)}
+	storage.rules[0].Updated = time.Unix(2, 0)
+	now = now.Add(rulesCacheTTL)
+	require.NotSame(t, rule, p.getChannelRule(1, "stream/telegraf/cpu"), "updated rules are built again")
+}
+
+func TestPipeline_RulesAreLoadedWithoutBlockingOtherOrganizations(t *testing.T) {
+	p, storage, _ := setupPipeline(t, storedRule(t, "stream/telegraf/cpu", `{}`))
+	storage.blockedOrg = 2
+	storage.started = make(chan struct{})
+	storage.unblock = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.getChannelRule(2, "stream/telegraf/cpu")
+	}()
+	<-storage.started
+
+	require.NotNil(t, p.getChannelRule(1, "stream/telegraf/cpu"), "the rules of an organization are loaded while another organization's are")
+	close(storage.unblock)
+	<-done
+}
+
+func TestPipeline_StreamConverter(t *testing.T) {
+	p, _, _ := setupPipeline(t,
+		storedRule(t, "stream/metrics", `{"converter": {"type": "json", "json": {"timeField": "ts"}}}`),
+		storedRule(t, "stream/telegraf/cpu", `{}`),
+	)
+	converter := p.StreamConverter(1, "metrics")
+	require.NotNil(t, converter)
+	require.Equal(t, "json", converter.Type)
+	require.Equal(t, "ts", converter.JSON.TimeField)
+	require.Nil(t, p.StreamConverter(1, "telegraf"))
+}
+
+func TestParseChannelRuleSettings(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		settings string
+		err      string
+	}{
+		{
+			desc: "valid settings",
+			settings: `{
+				"processors": [
+					{"type": "keepFields", "keepFields": {"fieldNames": ["usage_user"]}},
+					{"type": "convertUnit", "convertUnit": {"from": "percent", "to": "percentunit"}},
+					{"type": "downsample", "downsample": {"interval": "10s", "reducer": "mean"}}
+				],
+				"outputs": [
+					{"type": "managedStream"},
+					{"type": "threshold", "threshold": {"fieldName": "usage_user", "operator": ">", "threshold": 90}}
+				]
+			}`,
+		},
+		{
+			desc:     "unknown converter type",
+			settings: `{"converter": {"type": "csv"}}`,
+			err:      `unknown converter type "csv"`,
+		},
+		{
+			desc:     "unknown field",
+			settings: `{"processor": []}`,
+			err:      "unknown field",
+		},
+		{
+			desc:     "unknown processor type",
+			settings: `{"processors": [{"type": "sort"}]}`,
+			err:      `unknown processor type "sort"`,
+		},
+		{
+			desc:     "missing processor settings",
+			settings: `{"processors": [{"type": "throttle"}]}`,
+			err:      "throttle processor: settings are missing",
+		},
+		{
+			desc:     "units of different dimensions",
+			settings: `{"processors": [{"type": "convertUnit", "convertUnit": {"from": "celsius", "to": "ms"}}]}`,
+			err:      "cannot convert celsius to ms",
+		},
+		{
+			desc:     "invalid interval",
+			settings: `{"processors": [{"type": "throttle", "throttle": {"interval": "-1s"}}]}`,
+			err:      "the interval must be positive",
+		},
+		{
+			desc:     "route to a channel that is not a stream channel",
+			settings: `{"outputs": [{"type": "route", "route": {"label": "host", "channel": "grafana/dashboard/${value}"}}]}`,
+			err:      "frames can only be routed to stream channels",
+		},
+		{
+			desc:     "unknown threshold operator",
+			settings: `{"outputs": [{"type": "threshold", "threshold": {"fieldName": "usage_user", "operator": "=="}}]}`,
+			err:      `unknown operator "=="`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			js, err := simplejson.NewJson([]byte(tc.settings))
+			require.NoError(t, err)
+			_, err = ParseChannelRuleSettings(js)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
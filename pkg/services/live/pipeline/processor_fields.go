@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// KeepFieldsSettings keep the fields with the given names, and the time fields.
+type KeepFieldsSettings struct {
+	FieldNames []string `json:"fieldNames"`
+}
+
+// DropFieldsSettings drop the fields with the given names.
+type DropFieldsSettings struct {
+	FieldNames []string `json:"fieldNames"`
+}
+
+// RenameFieldsSettings rename fields, by their current names.
+type RenameFieldsSettings struct {
+	Names map[string]string `json:"names"`
+}
+
+type keepFieldsProcessor struct {
+	names map[string]struct{}
+}
+
+func newKeepFieldsProcessor(s KeepFieldsSettings) (*keepFieldsProcessor, error) {
+	if len(s.FieldNames) == 0 {
+		return nil, errors.New("no field names")
+	}
+	return &keepFieldsProcessor{names: fieldNameSet(s.FieldNames)}, nil
+}
+
+func (p *keepFieldsProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	return filterFields(frame, func(f *data.Field) bool {
+		_, ok := p.names[f.Name]
+		return ok || f.Type().Time()
+	}), nil
+}
+
+type dropFieldsProcessor struct {
+	names map[string]struct{}
+}
+
+func newDropFieldsProcessor(s DropFieldsSettings) (*dropFieldsProcessor, error) {
+	if len(s.FieldNames) == 0 {
+		return nil, errors.New("no field names")
+	}
+	return &dropFieldsProcessor{names: fieldNameSet(s.FieldNames)}, nil
+}
+
+func (p *dropFieldsProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	return filterFields(frame, func(f *data.Field) bool {
+		_, ok := p.names[f.Name]
+		return !ok
+	}), nil
+}
+
+type renameFieldsProcessor struct {
+	names map[string]string
+}
+
+func newRenameFieldsProcessor(s RenameFieldsSettings) (*renameFieldsProcessor, error) {
+	if len(s.Names) == 0 {
+		return nil, errors.New("no field names")
+	}
+	for from, to := range s.Names {
+		if from == "" || to == "" {
+			return nil, errors.New("field names cannot be empty")
+		}
+	}
+	return &renameFieldsProcessor{names: s.Names}, nil
+}
+
+func (p *renameFieldsProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	out := data.NewFrame(frame.Name)
+	out.RefID, out.Meta = frame.RefID, frame.Meta
+	for _, f := range frame.Fields {
+		if name, ok := p.names[f.Name]; ok {
+			renamed := *f
+			renamed.Name = name
+			f = &renamed
+		}
+		out.Fields = append(out.Fields, f)
+	}
+	return out, nil
+}
+
+// filterFields returns a frame with the fields of the frame that are kept. The fields are
+// not copied.
+func filterFields(frame *data.Frame, keep func(f *data.Field) bool) *data.Frame {
+	out := data.NewFrame(frame.Name)
+	out.RefID, out.Meta = frame.RefID, frame.Meta
+	for _, f := range frame.Fields {
+		if keep(f) {
+			out.Fields = append(out.Fields, f)
+		}
+	}
+	return out
+}
+
+func fieldNameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[n] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,209 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ThrottleSettings let at most one frame pass per interval, the others are dropped.
+type ThrottleSettings struct {
+	Interval string `json:"interval"`
+}
+
+// DownsampleSettings aggregate the frames of each interval into a frame with one row, at
+// the start of the interval. The frame of an interval is output with the first frame of
+// a later interval.
+type DownsampleSettings struct {
+	Interval string `json:"interval"`
+	// Reducer aggregates the values of numeric fields: last, mean, min, max or sum, last
+	// by default. The other fields have their last value.
+	Reducer string `json:"reducer,omitempty"`
+}
+
+type throttleProcessor struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func newThrottleProcessor(s ThrottleSettings, now func() time.Time) (*throttleProcessor, error) {
+	interval, err := parseInterval(s.Interval)
+	if err != nil {
+		return nil, err
+	}
+	return &throttleProcessor{interval: interval, now: now}, nil
+}
+
+func (p *throttleProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if !p.last.IsZero() && now.Sub(p.last) < p.interval {
+		return nil, nil
+	}
+	p.last = now
+	return frame, nil
+}
+
+var reducers = map[string]func(values []float64) float64{
+	"last": func(values []float64) float64 {
+		return values[len(values)-1]
+	},
+	"mean": func(values []float64) float64 {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	},
+	"min": func(values []float64) float64 {
+		min := math.Inf(1)
+		for _, v := range values {
+			min = math.Min(min, v)
+		}
+		return min
+	},
+	"max": func(values []float64) float64 {
+		max := math.Inf(-1)
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return max
+	},
+	"sum": func(values []float64) float64 {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	},
+}
+
+type downsampleProcessor struct {
+	interval time.Duration
+	reduce   func(values []float64) float64
+	now      func() time.Time
+
+	mu     sync.Mutex
+	bucket time.Time
+	name   string
+	time   string
+	fields []*bucketField
+	byKey  map[string]*bucketField
+}
+
+// bucketField is a field of the frames of an interval.
+type bucketField struct {
+	name   string
+	labels data.Labels
+	config *data.FieldConfig
+	// values are the values of a numeric field, without nulls.
+	values []float64
+	// last is the field with the last value of a field of another type, at lastIdx.
+	last    *data.Field
+	lastIdx int
+}
+
+func newDownsampleProcessor(s DownsampleSettings, now func() time.Time) (*downsampleProcessor, error) {
+	interval, err := parseInterval(s.Interval)
+	if err != nil {
+		return nil, err
+	}
+	reducer := s.Reducer
+	if reducer == "" {
+		reducer = "last"
+	}
+	reduce, ok := reducers[reducer]
+	if !ok {
+		return nil, fmt.Errorf("unknown reducer %q", reducer)
+	}
+	return &downsampleProcessor{interval: interval, reduce: reduce, now: now}, nil
+}
+
+func (p *downsampleProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out *data.Frame
+	if bucket := p.now().Truncate(p.interval); !bucket.Equal(p.bucket) {
+		out = p.flush()
+		p.bucket = bucket
+		p.fields = nil
+		p.byKey = map[string]*bucketField{}
+	}
+	if err := p.add(frame); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (p *downsampleProcessor) add(frame *data.Frame) error {
+	p.name = frame.Name
+	for _, f := range frame.Fields {
+		if f.Type().Time() {
+			if p.time == "" {
+				p.time = f.Name
+			}
+			continue
+		}
+		key := f.Name + f.Labels.String()
+		bf, ok := p.byKey[key]
+		if !ok {
+			bf = &bucketField{name: f.Name, labels: f.Labels}
+			p.byKey[key] = bf
+			p.fields = append(p.fields, bf)
+		}
+		bf.config = f.Config
+		if !f.Type().Numeric() {
+			if f.Len() > 0 {
+				bf.last, bf.lastIdx = f, f.Len()-1
+			}
+			continue
+		}
+		for i := 0; i < f.Len(); i++ {
+			v, err := f.NullableFloatAt(i)
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				bf.values = append(bf.values, *v)
+			}
+		}
+	}
+	return nil
+}
+
+// flush returns the frame of the current interval, or nil if there is none.
+func (p *downsampleProcessor) flush() *data.Frame {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	timeName := p.time
+	if timeName == "" {
+		timeName = "time"
+	}
+	frame := data.NewFrame(p.name, data.NewField(timeName, nil, []time.Time{p.bucket}))
+	for _, bf := range p.fields {
+		var f *data.Field
+		switch {
+		case bf.last != nil:
+			f = data.NewFieldFromFieldType(bf.last.Type(), 1)
+			f.Set(0, bf.last.CopyAt(bf.lastIdx))
+		case len(bf.values) > 0:
+			v := p.reduce(bf.values)
+			f = data.NewField("", nil, []*float64{&v})
+		default:
+			f = data.NewField("", nil, []*float64{nil})
+		}
+		f.Name, f.Labels, f.Config = bf.name, bf.labels, bf.config
+		frame.Fields = append(frame.Fields, f)
+	}
+	return frame
+}
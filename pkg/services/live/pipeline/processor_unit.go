@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ConvertUnitSettings convert the values of numeric fields from a unit to another of the
+// same dimension, e.g. from celsius to fahrenheit. The units are Grafana unit IDs. The
+// unit of the converted fields is set to the new unit.
+type ConvertUnitSettings struct {
+	// FieldNames are the names of the fields to convert. If there are none, the fields with
+	// the unit From are converted.
+	FieldNames []string `json:"fieldNames,omitempty"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+}
+
+// unit converts values to the base unit of its dimension: base = value * factor + offset.
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var units = map[string]unit{
+	"ns": {dimension: "time", factor: 1e-9},
+	"µs": {dimension: "time", factor: 1e-6},
+	"ms": {dimension: "time", factor: 1e-3},
+	"s":  {dimension: "time", factor: 1},
+	"m":  {dimension: "time", factor: 60},
+	"h":  {dimension: "time", factor: 3600},
+	"d":  {dimension: "time", factor: 86400},
+
+	"bits":      {dimension: "data", factor: 1.0 / 8},
+	"bytes":     {dimension: "data", factor: 1},
+	"kbytes":    {dimension: "data", factor: 1 << 10},
+	"mbytes":    {dimension: "data", factor: 1 << 20},
+	"gbytes":    {dimension: "data", factor: 1 << 30},
+	"tbytes":    {dimension: "data", factor: 1 << 40},
+	"decbytes":  {dimension: "data", factor: 1},
+	"deckbytes": {dimension: "data", factor: 1e3},
+	"decmbytes": {dimension: "data", factor: 1e6},
+	"decgbytes": {dimension: "data", factor: 1e9},
+	"dectbytes": {dimension: "data", factor: 1e12},
+
+	"percent":     {dimension: "ratio", factor: 0.01},
+	"percentunit": {dimension: "ratio", factor: 1},
+
+	"celsius":    {dimension: "temperature", factor: 1, offset: 273.15},
+	"fahrenheit": {dimension: "temperature", factor: 5.0 / 9, offset: 459.67 * 5 / 9},
+	"kelvin":     {dimension: "temperature", factor: 1},
+}
+
+type convertUnitProcessor struct {
+	names    map[string]struct{}
+	fromUnit string
+	toUnit   string
+	from, to unit
+}
+
+func newConvertUnitProcessor(s ConvertUnitSettings) (*convertUnitProcessor, error) {
+	from, ok := units[s.From]
+	if !ok {
+		return nil, fmt.Errorf("unsupported unit %q", s.From)
+	}
+	to, ok := units[s.To]
+	if !ok {
+		return nil, fmt.Errorf("unsupported unit %q", s.To)
+	}
+	if from.dimension != to.dimension {
+		return nil, fmt.Errorf("cannot convert %s to %s", s.From, s.To)
+	}
+	return &convertUnitProcessor{
+		names:    fieldNameSet(s.FieldNames),
+		fromUnit: s.From,
+		toUnit:   s.To,
+		from:     from,
+		to:       to,
+	}, nil
+}
+
+func (p *convertUnitProcessor) Process(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	out := data.NewFrame(frame.Name)
+	out.RefID, out.Meta = frame.RefID, frame.Meta
+	for _, f := range frame.Fields {
+		if p.converts(f) {
+			converted, err := p.convert(f)
+			if err != nil {
+				return nil, err
+			}
+			f = converted
+		}
+		out.Fields = append(out.Fields, f)
+	}
+	return out, nil
+}
+
+func (p *convertUnitProcessor) converts(f *data.Field) bool {
+	if !f.Type().Numeric() {
+		return false
+	}
+	if len(p.names) > 0 {
+		_, ok := p.names[f.Name]
+		return ok
+	}
+	return f.Config != nil && f.Config.Unit == p.fromUnit
+}
+
+// convert returns a float64 field, nullable if the field is nullable, with the converted values.
+func (p *convertUnitProcessor) convert(f *data.Field) (*data.Field, error) {
+	var converted *data.Field
+	if f.Nullable() {
+		converted = data.NewField(f.Name, f.Labels, make([]*float64, f.Len()))
+	} else {
+		converted = data.NewField(f.Name, f.Labels, make([]float64, f.Len()))
+	}
+	for i := 0; i < f.Len(); i++ {
+		v, err := f.NullableFloatAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		c := ((*v)*p.from.factor + p.from.offset - p.to.offset) / p.to.factor
+		if f.Nullable() {
+			converted.Set(i, &c)
+		} else {
+			converted.Set(i, c)
+		}
+	}
+	config := data.FieldConfig{}
+	if f.Config != nil {
+		config = *f.Config
+	}
+	config.Unit = p.toUnit
+	converted.Config = &config
+	return converted, nil
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Pointer(f float64) *float64 {
+	return &f
+}
+
+func TestConvertUnitProcessor(t *testing.T) {
+	t.Run("fields are converted by name", func(t *testing.T) {
+		p, err := newConvertUnitProcessor(ConvertUnitSettings{FieldNames: []string{"temp"}, From: "celsius", To: "fahrenheit"})
+		require.NoError(t, err)
+		frame := data.NewFrame("sensors",
+			data.NewField("temp", nil, []int64{0, 100}),
+			data.NewField("humidity", nil, []float64{50, 60}),
+		)
+		out, err := p.Process(context.Background(), Vars{}, frame)
+		require.NoError(t, err)
+		require.InDeltaSlice(t, []float64{32, 212}, []float64{out.Fields[0].At(0).(float64), out.Fields[0].At(1).(float64)}, 1e-9)
+		require.Equal(t, "fahrenheit", out.Fields[0].Config.Unit)
+		require.Same(t, frame.Fields[1], out.Fields[1])
+		require.Equal(t, int64(0), frame.Fields[0].At(0), "the frame is not modified")
+	})
+
+	t.Run("fields are converted by unit, nulls are kept", func(t *testing.T) {
+		p, err := newConvertUnitProcessor(ConvertUnitSettings{From: "ms", To: "s"})
+		require.NoError(t, err)
+		latency := data.NewField("latency", nil, []*float64{float64Pointer(1500), nil})
+		latency.Config = &data.FieldConfig{Unit: "ms"}
+		out, err := p.Process(context.Background(), Vars{}, data.NewFrame("http", latency, data.NewField("count", nil, []float64{3, 4})))
+		require.NoError(t, err)
+		require.Equal(t, []*float64{float64Pointer(1.5), nil}, []*float64{out.Fields[0].At(0).(*float64), out.Fields[0].At(1).(*float64)})
+		require.Equal(t, "s", out.Fields[0].Config.Unit)
+		require.Equal(t, 3.0, out.Fields[1].At(0))
+	})
+}
+
+func TestDownsampleProcessor(t *testing.T) {
+	now := time.Unix(100, 0)
+	p, err := newDownsampleProcessor(DownsampleSettings{Interval: "10s", Reducer: "mean"}, func() time.Time { return now })
+	require.NoError(t, err)
+
+	frame := func(usage float64, status string) *data.Frame {
+		return data.NewFrame("cpu",
+			data.NewField("ts", nil, []time.Time{now}),
+			data.NewField("usage", data.Labels{"host": "a"}, []float64{usage}),
+			data.NewField("status", nil, []string{status}),
+		)
+	}
+
+	out, err := p.Process(context.Background(), Vars{}, frame(10, "ok"))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	now = now.Add(5 * time.Second)
+	out, err = p.Process(context.Background(), Vars{}, frame(20, "degraded"))
+	require.NoError(t, err)
+	require.Nil(t, out, "the frame of the interval is output when it is over")
+
+	now = now.Add(5 * time.Second)
+	out, err = p.Process(context.Background(), Vars{}, frame(100, "ok"))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, "cpu", out.Name)
+	require.Equal(t, 1, out.Rows())
+	require.Equal(t, []string{"ts", "usage", "status"}, fieldNames(out))
+	require.Equal(t, time.Unix(100, 0), out.Fields[0].At(0))
+	require.Equal(t, 15.0, *out.Fields[1].At(0).(*float64))
+	require.Equal(t, data.Labels{"host": "a"}, out.Fields[1].Labels)
+	require.Equal(t, "degraded", out.Fields[2].At(0))
+}
+
+func TestThrottleProcessor(t *testing.T) {
+	now := time.Unix(100, 0)
+	p, err := newThrottleProcessor(ThrottleSettings{Interval: "1s"}, func() time.Time { return now })
+	require.NoError(t, err)
+
+	passed := 0
+	for i := 0; i < 10; i++ {
+		out, err := p.Process(context.Background(), Vars{}, cpuFrame())
+		require.NoError(t, err)
+		if out != nil {
+			passed++
+		}
+		now = now.Add(300 * time.Millisecond)
+	}
+	require.Equal(t, 3, passed)
+}
@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+// Processor types.
+const (
+	ProcessorTypeKeepFields   = "keepFields"
+	ProcessorTypeDropFields   = "dropFields"
+	ProcessorTypeRenameFields = "renameFields"
+	ProcessorTypeConvertUnit  = "convertUnit"
+	ProcessorTypeThrottle     = "throttle"
+	ProcessorTypeDownsample   = "downsample"
+)
+
+// Output types.
+const (
+	OutputTypeManagedStream = "managedStream"
+	OutputTypeRoute         = "route"
+	OutputTypeThreshold     = "threshold"
+)
+
+// ChannelRuleSettings are the settings of a channel rule.
+type ChannelRuleSettings struct {
+	// Processors transform the frames in order.
+	Processors []ProcessorSettings `json:"processors,omitempty"`
+	// Outputs get the processed frames. The frames are pushed to the managed stream of
+	// the channel if there are none.
+	Outputs []OutputSettings `json:"outputs,omitempty"`
+	// Converter sets how the data pushed to a stream are converted to frames. It is used in
+	// the rule of the channel of the stream, e.g. stream/telegraf.
+	Converter *ConverterSettings `json:"converter,omitempty"`
+}
+
+// ConverterSettings are the settings of the converter of a stream, in the field of its type.
+// The input format of a push request overrides them.
+type ConverterSettings struct {
+	// Type is the input format: influx, json, prometheus or otlp.
+	Type string                 `json:"type"`
+	JSON *JSONConverterSettings `json:"json,omitempty"`
+}
+
+// JSONConverterSettings are the settings of the converter of JSON objects.
+type JSONConverterSettings struct {
+	// TimeField is the field with the time of objects, time by default.
+	TimeField string `json:"timeField,omitempty"`
+	// Name is the path of the frames in the stream, json by default.
+	Name string `json:"name,omitempty"`
+}
+
+// ProcessorSettings are the settings of a processor, in the field of its type.
+type ProcessorSettings struct {
+	Type         string                `json:"type"`
+	KeepFields   *KeepFieldsSettings   `json:"keepFields,omitempty"`
+	DropFields   *DropFieldsSettings   `json:"dropFields,omitempty"`
+	RenameFields *RenameFieldsSettings `json:"renameFields,omitempty"`
+	ConvertUnit  *ConvertUnitSettings  `json:"convertUnit,omitempty"`
+	Throttle     *ThrottleSettings     `json:"throttle,omitempty"`
+	Downsample   *DownsampleSettings   `json:"downsample,omitempty"`
+}
+
+// OutputSettings are the settings of an output, in the field of its type.
+type OutputSettings struct {
+	Type      string             `json:"type"`
+	Route     *RouteSettings     `json:"route,omitempty"`
+	Threshold *ThresholdSettings `json:"threshold,omitempty"`
+}
+
+// ParseChannelRuleSettings decodes and validates the settings of a channel rule.
+func ParseChannelRuleSettings(js *simplejson.Json) (*ChannelRuleSettings, error) {
+	if js == nil {
+		return &ChannelRuleSettings{}, nil
+	}
+	b, err := js.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	settings := &ChannelRuleSettings{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(settings); err != nil {
+		return nil, fmt.Errorf("invalid channel rule settings: %w", err)
+	}
+	if settings.Converter != nil {
+		switch settings.Converter.Type {
+		case convert.InputFormatInflux, convert.InputFormatJSON, convert.InputFormatPrometheus, convert.InputFormatOTLP:
+		default:
+			return nil, fmt.Errorf("unknown converter type %q", settings.Converter.Type)
+		}
+	}
+	for _, s := range settings.Processors {
+		if _, err := newProcessor(s, time.Now); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range settings.Outputs {
+		if _, err := newOutputter(s, outputDeps{now: time.Now}); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+func (p *Pipeline) buildProcessor(s ProcessorSettings) (FrameProcessor, error) {
+	return newProcessor(s, p.now)
+}
+
+func (p *Pipeline) buildOutputter(s OutputSettings) (FrameOutputter, error) {
+	return newOutputter(s, outputDeps{now: p.now, alertSender: p.getAlertSender, push: p.pushToStream})
+}
+
+// outputDeps are what outputs need from the pipeline.
+type outputDeps struct {
+	now         func() time.Time
+	alertSender func() AlertSender
+	push        func(vars Vars, frame *data.Frame) error
+}
+
+var errMissingSettings = errors.New("settings are missing")
+
+func newProcessor(s ProcessorSettings, now func() time.Time) (FrameProcessor, error) {
+	var processor FrameProcessor
+	var err error
+	switch s.Type {
+	case ProcessorTypeKeepFields:
+		if s.KeepFields == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newKeepFieldsProcessor(*s.KeepFields)
+	case ProcessorTypeDropFields:
+		if s.DropFields == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newDropFieldsProcessor(*s.DropFields)
+	case ProcessorTypeRenameFields:
+		if s.RenameFields == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newRenameFieldsProcessor(*s.RenameFields)
+	case ProcessorTypeConvertUnit:
+		if s.ConvertUnit == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newConvertUnitProcessor(*s.ConvertUnit)
+	case ProcessorTypeThrottle:
+		if s.Throttle == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newThrottleProcessor(*s.Throttle, now)
+	case ProcessorTypeDownsample:
+		if s.Downsample == nil {
+			return nil, fmt.Errorf("%s processor: %w", s.Type, errMissingSettings)
+		}
+		processor, err = newDownsampleProcessor(*s.Downsample, now)
+	default:
+		return nil, fmt.Errorf("unknown processor type %q", s.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s processor: %w", s.Type, err)
+	}
+	return processor, nil
+}
+
+func newOutputter(s OutputSettings, deps outputDeps) (FrameOutputter, error) {
+	var outputter FrameOutputter
+	var err error
+	switch s.Type {
+	case OutputTypeManagedStream:
+		outputter = managedStreamOutput{push: deps.push}
+	case OutputTypeRoute:
+		if s.Route == nil {
+			return nil, fmt.Errorf("%s output: %w", s.Type, errMissingSettings)
+		}
+		outputter, err = newRouteOutput(*s.Route)
+	case OutputTypeThreshold:
+		if s.Threshold == nil {
+			return nil, fmt.Errorf("%s output: %w", s.Type, errMissingSettings)
+		}
+		outputter, err = newThresholdOutput(*s.Threshold, deps.now, deps.alertSender)
+	default:
+		return nil, fmt.Errorf("unknown output type %q", s.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s output: %w", s.Type, err)
+	}
+	return outputter, nil
+}
+
+// parseInterval parses a positive duration, e.g. 5s.
+func parseInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("the interval is missing")
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("the interval must be positive: %s", s)
+	}
+	return d, nil
+}
@@ -45,16 +45,10 @@ func (g *Gateway) Run(ctx context.Context) error {
 func (g *Gateway) Handle(ctx *models.ReqContext) {
 	streamID := ctx.Params(":streamId")
 
-	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgId, streamID)
-	if err != nil {
-		logger.Error("Error getting stream", "error", err)
-		ctx.Resp.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
 	// TODO Grafana 8: decide which formats to use or keep all.
 	urlValues := ctx.Req.URL.Query()
-	frameFormat := pushurl.FrameFormatFromValues(urlValues)
+	streamConverter := g.GrafanaLive.Pipeline.StreamConverter(ctx.SignedInUser.OrgId, streamID)
+	opts := pushurl.ConvertOptions(urlValues, ctx.Req.Header.Get("Content-Type"), streamConverter)
 
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
@@ -66,13 +60,14 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		"protocol", "http",
 		"streamId", streamID,
 		"bodyLength", len(body),
-		"frameFormat", frameFormat,
+		"inputFormat", opts.InputFormat,
+		"frameFormat", opts.FrameFormat,
 	)
 
-	metricFrames, err := g.converter.Convert(body, frameFormat)
+	metricFrames, err := g.converter.Convert(body, opts)
 	if err != nil {
-		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
-		if errors.Is(err, convert.ErrUnsupportedFrameFormat) {
+		logger.Error("Error converting metrics", "error", err, "inputFormat", opts.InputFormat, "frameFormat", opts.FrameFormat)
+		if errors.Is(err, convert.ErrUnsupportedFrameFormat) || errors.Is(err, convert.ErrUnsupportedInputFormat) {
 			ctx.Resp.WriteHeader(http.StatusBadRequest)
 		} else {
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -80,11 +75,23 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		return
 	}
 
+	denied, err := g.GrafanaLive.ChannelAccess.DeniedPushChannel(ctx.Req.Context(), ctx.SignedInUser, streamID, metricFrames)
+	if err != nil {
+		logger.Error("Error checking publish permission", "error", err, "streamId", streamID)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if denied != "" {
+		logger.Info("Push denied: no publish permission", "user", ctx.SignedInUser.UserId, "channel", denied)
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	// TODO -- make sure all packets are combined together!
 	// interval = "1s" vs flush_interval = "5s"
 
 	for _, mf := range metricFrames {
-		err := stream.Push(mf.Key(), mf.Frame())
+		err := g.GrafanaLive.Pipeline.ProcessFrame(ctx.Req.Context(), ctx.SignedInUser.OrgId, streamID, mf.Key(), mf.Frame())
 		if err != nil {
 			logger.Error("Error pushing frame", "error", err, "data", string(body))
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
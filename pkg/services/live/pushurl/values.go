@@ -3,10 +3,15 @@ package pushurl
 import (
 	"net/url"
 	"strings"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 )
 
 const (
 	frameFormatParam = "gf_live_frame_format"
+	inputFormatParam = "gf_live_input_format"
+	timeFieldParam   = "gf_live_time_field"
 )
 
 // FrameFormatFromValues extracts frame format tip from url values.
@@ -17,3 +22,30 @@ func FrameFormatFromValues(values url.Values) string {
 	}
 	return frameFormat
 }
+
+// ConvertOptions returns the options to convert the data pushed to a stream. The input format
+// is taken from url values, then from the converter settings of the stream, if any, then from
+// the content type.
+func ConvertOptions(values url.Values, contentType string, streamConverter *pipeline.ConverterSettings) convert.Options {
+	opts := convert.Options{
+		FrameFormat: FrameFormatFromValues(values),
+		ContentType: contentType,
+	}
+	if streamConverter != nil {
+		opts.InputFormat = streamConverter.Type
+		if streamConverter.JSON != nil {
+			opts.TimeField = streamConverter.JSON.TimeField
+			opts.Name = streamConverter.JSON.Name
+		}
+	}
+	if inputFormat := strings.ToLower(values.Get(inputFormatParam)); inputFormat != "" {
+		opts.InputFormat = inputFormat
+	}
+	if opts.InputFormat == "" {
+		opts.InputFormat = convert.InputFormatFromContentType(contentType)
+	}
+	if timeField := values.Get(timeFieldParam); timeField != "" {
+		opts.TimeField = timeField
+	}
+	return opts
+}
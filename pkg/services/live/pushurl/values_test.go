@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 )
 
 func TestFrameFormatFromValues(t *testing.T) {
@@ -13,3 +16,28 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+func TestConvertOptions(t *testing.T) {
+	values := url.Values{}
+	opts := ConvertOptions(values, "", nil)
+	require.Equal(t, convert.Options{InputFormat: convert.InputFormatInflux, FrameFormat: "labels_column"}, opts)
+
+	opts = ConvertOptions(values, "application/json", nil)
+	require.Equal(t, convert.InputFormatJSON, opts.InputFormat)
+
+	streamConverter := &pipeline.ConverterSettings{
+		Type: convert.InputFormatJSON,
+		JSON: &pipeline.JSONConverterSettings{TimeField: "ts", Name: "sensors"},
+	}
+	opts = ConvertOptions(values, "text/plain", streamConverter)
+	require.Equal(t, convert.InputFormatJSON, opts.InputFormat, "the converter of the stream is used over the content type")
+	require.Equal(t, "ts", opts.TimeField)
+	require.Equal(t, "sensors", opts.Name)
+
+	values.Set(inputFormatParam, "OTLP")
+	values.Set(timeFieldParam, "timestamp")
+	opts = ConvertOptions(values, "application/json", streamConverter)
+	require.Equal(t, convert.InputFormatOTLP, opts.InputFormat, "the input format of the url is used over the converter of the stream")
+	require.Equal(t, "timestamp", opts.TimeField)
+	require.Equal(t, "application/json", opts.ContentType)
+}
@@ -9,8 +9,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/liveaccess"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
-	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 
 	"github.com/gorilla/websocket"
@@ -22,10 +23,11 @@ var (
 
 // Handler handles WebSocket client connections that push data to Live.
 type Handler struct {
-	managedStreamRunner *managedstream.Runner
-	config              Config
-	upgrade             *websocket.Upgrader
-	converter           *convert.Converter
+	pipeline      *pipeline.Pipeline
+	channelAccess *liveaccess.Checker
+	config        Config
+	upgrade       *websocket.Upgrader
+	converter     *convert.Converter
 }
 
 // Config represents config for Handler.
@@ -52,7 +54,7 @@ type Config struct {
 }
 
 // NewHandler creates new Handler.
-func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
+func NewHandler(pipeline *pipeline.Pipeline, channelAccess *liveaccess.Checker, c Config) *Handler {
 	if c.CheckOrigin == nil {
 		c.CheckOrigin = sameHostOriginCheck()
 	}
@@ -62,10 +64,11 @@ func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
 		CheckOrigin:     c.CheckOrigin,
 	}
 	return &Handler{
-		managedStreamRunner: managedStreamRunner,
-		config:              c,
-		upgrade:             upgrade,
-		converter:           convert.NewConverter(),
+		pipeline:      pipeline,
+		channelAccess: channelAccess,
+		config:        c,
+		upgrade:       upgrade,
+		converter:     convert.NewConverter(),
 	}
 }
 
@@ -165,31 +168,37 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		stream, err := s.managedStreamRunner.GetOrCreateStream(user.OrgId, streamID)
-		if err != nil {
-			logger.Error("Error getting stream", "error", err)
-			continue
-		}
-
 		// TODO Grafana 8: decide which formats to use or keep all.
 		urlValues := r.URL.Query()
-		frameFormat := pushurl.FrameFormatFromValues(urlValues)
+		streamConverter := s.pipeline.StreamConverter(user.OrgId, streamID)
+		opts := pushurl.ConvertOptions(urlValues, r.Header.Get("Content-Type"), streamConverter)
 
 		logger.Debug("Live Push request",
 			"protocol", "http",
 			"streamId", streamID,
 			"bodyLength", len(body),
-			"frameFormat", frameFormat,
+			"inputFormat", opts.InputFormat,
+			"frameFormat", opts.FrameFormat,
 		)
 
-		metricFrames, err := s.converter.Convert(body, frameFormat)
+		metricFrames, err := s.converter.Convert(body, opts)
 		if err != nil {
-			logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
+			logger.Error("Error converting metrics", "error", err, "inputFormat", opts.InputFormat, "frameFormat", opts.FrameFormat)
+			continue
+		}
+
+		denied, err := s.channelAccess.DeniedPushChannel(r.Context(), user, streamID, metricFrames)
+		if err != nil {
+			logger.Error("Error checking publish permission", "error", err, "streamId", streamID)
+			return
+		}
+		if denied != "" {
+			logger.Info("Push denied: no publish permission", "user", user.UserId, "channel", denied)
 			continue
 		}
 
 		for _, mf := range metricFrames {
-			err := stream.Push(mf.Key(), mf.Frame())
+			err := s.pipeline.ProcessFrame(r.Context(), user.OrgId, streamID, mf.Key(), mf.Frame())
 			if err != nil {
 				logger.Error("Error pushing frame", "error", err, "data", string(body))
 				return
@@ -0,0 +1,68 @@
+package live
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/live/liveaccess"
+)
+
+// declareFixedRoles declares to the AccessControl service fixed roles and their
+// grants to organization roles ("Viewer", "Editor", "Admin") that GrafanaLive needs.
+// Grants follow the org role checks that apply when access control is disabled, except
+// that pushing to managed streams requires the Editor role.
+func (g *GrafanaLive) declareFixedRoles() error {
+	registrations := []accesscontrol.RoleRegistration{
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:live:subscriber",
+				Description: "Subscribe to all Live channels and read their history",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: liveaccess.ActionSubscribe,
+						Scope:  liveaccess.ScopeChannelsAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_VIEWER)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:live:publisher",
+				Description: "Publish to Grafana, plugin and data source Live channels",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: liveaccess.ActionPublish,
+						Scope:  liveaccess.ScopeChannelsGrafana,
+					},
+					{
+						Action: liveaccess.ActionPublish,
+						Scope:  liveaccess.ScopeChannelsPlugin,
+					},
+					{
+						Action: liveaccess.ActionPublish,
+						Scope:  liveaccess.ScopeChannelsDS,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_VIEWER)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:live:streams:publisher",
+				Description: "Push data to all Live managed streams",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: liveaccess.ActionPublish,
+						Scope:  liveaccess.ScopeChannelsStreamAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_EDITOR)},
+		},
+	}
+
+	return g.AccessControl.DeclareFixedRoles(registrations...)
+}
@@ -0,0 +1,439 @@
+package sqlengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/lib/pq"
+)
+
+var logger = log.New("live.sqlengine")
+
+const (
+	// DefaultPollInterval is how often instances read new messages when notifications are
+	// not available.
+	DefaultPollInterval = 100 * time.Millisecond
+	// DefaultMessageTTL is how long messages are kept in the database. Instances which are
+	// late by more than that lose messages.
+	DefaultMessageTTL = 30 * time.Second
+
+	// notifyPollInterval is how often instances read new messages when notifications are
+	// available, in case a notification is lost while reconnecting.
+	notifyPollInterval = 5 * time.Second
+	// notifyChannel is the PostgreSQL channel notified of new messages.
+	notifyChannel = "grafana_live"
+	// pollBatchSize is the maximum number of messages read by a query.
+	pollBatchSize = 1000
+	// maxMissingMessages is the maximum number of missing messages an instance waits for.
+	maxMissingMessages = 100
+)
+
+type messageType string
+
+const (
+	messageTypePublication messageType = "publication"
+	messageTypeJoin        messageType = "join"
+	messageTypeLeave       messageType = "leave"
+	messageTypeControl     messageType = "control"
+)
+
+// message is the payload of a row of the live_ha_message table.
+type message struct {
+	Type    messageType            `json:"type"`
+	Channel string                 `json:"channel,omitempty"`
+	Data    []byte                 `json:"data,omitempty"`
+	Info    *centrifuge.ClientInfo `json:"info,omitempty"`
+	// NodeID is the node a control message is sent to, all nodes if empty.
+	NodeID string `json:"nodeId,omitempty"`
+}
+
+type messageRow struct {
+	Id      int64
+	Payload string
+}
+
+// BrokerConfig is a config for Broker.
+type BrokerConfig struct {
+	// PollInterval is how often new messages are read without PostgreSQL notifications.
+	// Zero value means that DefaultPollInterval will be used.
+	PollInterval time.Duration
+	// MessageTTL is how long messages are kept in the database. Zero value means that
+	// DefaultMessageTTL will be used.
+	MessageTTL time.Duration
+}
+
+// Broker is a centrifuge.Broker which passes messages between Grafana instances through the
+// Grafana database. Messages are written to the live_ha_message table, which instances read
+// when notified with PostgreSQL LISTEN/NOTIFY, or poll with other databases.
+//
+// Like the Redis broker, Broker delivers messages at most once. It does not keep the history
+// of channels.
+type Broker struct {
+	node   *centrifuge.Node
+	store  *sqlstore.SQLStore
+	config BrokerConfig
+	notify bool
+	now    func() time.Time
+
+	handler centrifuge.BrokerEventHandler
+	// lastID is the greatest ID of the messages read.
+	lastID int64
+	// missing are the IDs lower than lastID of the messages not read yet, with the time they
+	// have been missed since. IDs are allocated before messages are committed, so messages
+	// can be committed after messages with greater IDs are read.
+	missing map[int64]time.Time
+
+	subscriptionsMu sync.RWMutex
+	subscriptions   map[string]struct{}
+
+	wakeUp    chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	listener  *pq.Listener
+}
+
+var _ centrifuge.Broker = (*Broker)(nil)
+var _ centrifuge.Closer = (*Broker)(nil)
+
+// NewBroker creates a Broker. PostgreSQL notifications are used if the Grafana database is
+// PostgreSQL.
+func NewBroker(node *centrifuge.Node, store *sqlstore.SQLStore, config BrokerConfig) *Broker {
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MessageTTL == 0 {
+		config.MessageTTL = DefaultMessageTTL
+	}
+	return &Broker{
+		node:          node,
+		store:         store,
+		config:        config,
+		notify:        strings.HasPrefix(store.Dialect.DriverName(), migrator.Postgres),
+		now:           time.Now,
+		subscriptions: map[string]struct{}{},
+		missing:       map[int64]time.Time{},
+		wakeUp:        make(chan struct{}, 1),
+		closeCh:       make(chan struct{}),
+	}
+}
+
+// Run - see centrifuge.Broker interface description.
+func (b *Broker) Run(h centrifuge.BrokerEventHandler) error {
+	b.handler = h
+
+	// Messages written before this instance started are not for it.
+	var last messageRow
+	err := b.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("live_ha_message").Cols("id").Desc("id").Limit(1).Get(&last)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error reading last Live message: %w", err)
+	}
+	b.lastID = last.Id
+
+	pollInterval := b.config.PollInterval
+	if b.notify {
+		if err := b.listen(); err != nil {
+			return err
+		}
+		pollInterval = notifyPollInterval
+	}
+
+	go b.runPoll(pollInterval)
+	go b.runCleanup()
+	return nil
+}
+
+// listen listens to PostgreSQL notifications of new messages on a dedicated connection.
+func (b *Broker) listen() error {
+	b.listener = pq.NewListener(b.store.ConnectionString(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Live database listener event", "event", event, "error", err)
+		}
+	})
+	if err := b.listener.Listen(notifyChannel); err != nil {
+		_ = b.listener.Close()
+		return fmt.Errorf("error listening to Live notifications: %w", err)
+	}
+	go func() {
+		for {
+			select {
+			case <-b.closeCh:
+				return
+			// A nil notification is sent after reconnecting, messages may have been missed.
+			case <-b.listener.Notify:
+				b.triggerPoll()
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *Broker) triggerPoll() {
+	select {
+	case b.wakeUp <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Broker) runPoll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.closeCh:
+			return
+		case <-ticker.C:
+		case <-b.wakeUp:
+		}
+		if err := b.poll(); err != nil {
+			logger.Error("Error reading Live messages", "error", err)
+		}
+	}
+}
+
+// poll reads and handles the messages written since the last poll, and the missing messages
+// committed since.
+func (b *Broker) poll() error {
+	for {
+		query, args := b.pollCondition()
+		var rows []messageRow
+		err := b.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Table("live_ha_message").Cols("id", "payload").Where(query, args...).Asc("id").Limit(pollBatchSize).Find(&rows)
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if !b.markRead(row.Id) {
+				continue
+			}
+			var msg message
+			if err := json.Unmarshal([]byte(row.Payload), &msg); err != nil {
+				logger.Error("Error decoding Live message", "id", row.Id, "error", err)
+				continue
+			}
+			if err := b.handle(msg); err != nil {
+				logger.Error("Error handling Live message", "id", row.Id, "type", msg.Type, "error", err)
+			}
+		}
+		if len(rows) < pollBatchSize {
+			return nil
+		}
+	}
+}
+
+// pollCondition returns the condition of the messages to read: the messages after lastID and
+// the missing messages. Messages missing for longer than the message TTL are given up on,
+// since they are deleted by then, if they ever existed: IDs of rolled back transactions are
+// never used.
+func (b *Broker) pollCondition() (string, []interface{}) {
+	expired := b.now().Add(-b.config.MessageTTL)
+	args := []interface{}{b.lastID}
+	for id, since := range b.missing {
+		if since.Before(expired) {
+			delete(b.missing, id)
+			continue
+		}
+		args = append(args, id)
+	}
+	if len(args) == 1 {
+		return "id > ?", args
+	}
+	return "id > ? OR id IN (?" + strings.Repeat(",?", len(args)-2) + ")", args
+}
+
+// markRead records that the message with the ID is read, and returns false if it was read
+// already. The IDs skipped since the last message read are recorded as missing.
+func (b *Broker) markRead(id int64) bool {
+	if id <= b.lastID {
+		if _, ok := b.missing[id]; !ok {
+			return false
+		}
+		delete(b.missing, id)
+		return true
+	}
+
+	// Only the greatest skipped IDs are waited for, there are never that many messages being
+	// written at the same time.
+	from := b.lastID + 1
+	if id-from > maxMissingMessages {
+		from = id - maxMissingMessages
+	}
+	now := b.now()
+	for missingID := from; missingID < id; missingID++ {
+		b.missing[missingID] = now
+	}
+	b.lastID = id
+
+	if len(b.missing) > maxMissingMessages {
+		ids := make([]int64, 0, len(b.missing))
+		for missingID := range b.missing {
+			ids = append(ids, missingID)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for _, missingID := range ids[:len(ids)-maxMissingMessages] {
+			delete(b.missing, missingID)
+		}
+	}
+	return true
+}
+
+func (b *Broker) handle(msg message) error {
+	switch msg.Type {
+	case messageTypePublication:
+		if !b.isSubscribed(msg.Channel) {
+			return nil
+		}
+		return b.handler.HandlePublication(msg.Channel, &centrifuge.Publication{Data: msg.Data, Info: msg.Info}, centrifuge.StreamPosition{})
+	case messageTypeJoin:
+		if !b.isSubscribed(msg.Channel) {
+			return nil
+		}
+		return b.handler.HandleJoin(msg.Channel, msg.Info)
+	case messageTypeLeave:
+		if !b.isSubscribed(msg.Channel) {
+			return nil
+		}
+		return b.handler.HandleLeave(msg.Channel, msg.Info)
+	case messageTypeControl:
+		if msg.NodeID != "" && msg.NodeID != b.node.ID() {
+			return nil
+		}
+		return b.handler.HandleControl(msg.Data)
+	default:
+		return fmt.Errorf("unknown message type: %s", msg.Type)
+	}
+}
+
+func (b *Broker) runCleanup() {
+	ticker := time.NewTicker(b.config.MessageTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.closeCh:
+			return
+		case <-ticker.C:
+		}
+		err := b.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("DELETE FROM live_ha_message WHERE created < ?", toMillis(b.now().Add(-b.config.MessageTTL)))
+			return err
+		})
+		if err != nil {
+			logger.Error("Error deleting expired Live messages", "error", err)
+		}
+	}
+}
+
+func (b *Broker) isSubscribed(ch string) bool {
+	b.subscriptionsMu.RLock()
+	defer b.subscriptionsMu.RUnlock()
+	_, ok := b.subscriptions[ch]
+	return ok
+}
+
+// Subscribe - see centrifuge.Broker interface description.
+func (b *Broker) Subscribe(ch string) error {
+	b.subscriptionsMu.Lock()
+	defer b.subscriptionsMu.Unlock()
+	b.subscriptions[ch] = struct{}{}
+	return nil
+}
+
+// Unsubscribe - see centrifuge.Broker interface description.
+func (b *Broker) Unsubscribe(ch string) error {
+	b.subscriptionsMu.Lock()
+	defer b.subscriptionsMu.Unlock()
+	delete(b.subscriptions, ch)
+	return nil
+}
+
+// Publish - see centrifuge.Broker interface description. History options are ignored.
+func (b *Broker) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, error) {
+	return centrifuge.StreamPosition{}, b.publish(message{
+		Type:    messageTypePublication,
+		Channel: ch,
+		Data:    data,
+		Info:    opts.ClientInfo,
+	})
+}
+
+// PublishJoin - see centrifuge.Broker interface description.
+func (b *Broker) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(message{Type: messageTypeJoin, Channel: ch, Info: info})
+}
+
+// PublishLeave - see centrifuge.Broker interface description.
+func (b *Broker) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(message{Type: messageTypeLeave, Channel: ch, Info: info})
+}
+
+// PublishControl - see centrifuge.Broker interface description.
+func (b *Broker) PublishControl(data []byte, nodeID, _ string) error {
+	return b.publish(message{Type: messageTypeControl, Data: data, NodeID: nodeID})
+}
+
+// publish writes a message for all instances, itself included, and notifies them.
+func (b *Broker) publish(msg message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	err = b.store.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("INSERT INTO live_ha_message (payload, created) VALUES (?, ?)", string(payload), toMillis(b.now())); err != nil {
+			return err
+		}
+		if b.notify {
+			// Notifications are sent when the transaction is committed.
+			_, err := sess.Exec("NOTIFY " + notifyChannel)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !b.notify {
+		// Do not wait for the next poll to handle messages of this instance.
+		b.triggerPoll()
+	}
+	return nil
+}
+
+// History - see centrifuge.Broker interface description. Broker does not keep the history of
+// channels, so it always returns no publications.
+func (b *Broker) History(_ string, _ centrifuge.HistoryFilter) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return nil, centrifuge.StreamPosition{}, nil
+}
+
+// RemoveHistory - see centrifuge.Broker interface description.
+func (b *Broker) RemoveHistory(_ string) error {
+	return nil
+}
+
+// Close stops reading messages.
+func (b *Broker) Close(_ context.Context) error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.closeCh)
+		if b.listener != nil {
+			err = b.listener.Close()
+		}
+	})
+	return err
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
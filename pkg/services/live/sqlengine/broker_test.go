@@ -0,0 +1,212 @@
+//go:build integration
+// +build integration
+
+package sqlengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+type testEventHandler struct {
+	mu           sync.Mutex
+	publications []string
+	joins        []string
+	controls     []string
+}
+
+func (h *testEventHandler) HandlePublication(ch string, pub *centrifuge.Publication, _ centrifuge.StreamPosition) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publications = append(h.publications, ch+":"+string(pub.Data))
+	return nil
+}
+
+func (h *testEventHandler) HandleJoin(ch string, info *centrifuge.ClientInfo) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.joins = append(h.joins, ch+":"+info.ClientID)
+	return nil
+}
+
+func (h *testEventHandler) HandleLeave(string, *centrifuge.ClientInfo) error {
+	return nil
+}
+
+func (h *testEventHandler) HandleControl(data []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.controls = append(h.controls, string(data))
+	return nil
+}
+
+func (h *testEventHandler) get() ([]string, []string, []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.publications...), append([]string(nil), h.joins...), append([]string(nil), h.controls...)
+}
+
+func newTestBroker(t *testing.T, store *sqlstore.SQLStore) (*Broker, *testEventHandler) {
+	t.Helper()
+	node, err := centrifuge.New(centrifuge.DefaultConfig)
+	require.NoError(t, err)
+	b := NewBroker(node, store, BrokerConfig{PollInterval: 10 * time.Millisecond})
+	h := &testEventHandler{}
+	require.NoError(t, b.Run(h))
+	t.Cleanup(func() {
+		require.NoError(t, b.Close(context.Background()))
+	})
+	return b, h
+}
+
+func TestBroker(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	b1, h1 := newTestBroker(t, store)
+	b2, h2 := newTestBroker(t, store)
+
+	require.NoError(t, b1.Subscribe("1/stream/test/cpu"))
+	require.NoError(t, b2.Subscribe("1/stream/test/cpu"))
+	require.NoError(t, b2.Subscribe("1/stream/test/mem"))
+
+	_, err := b1.Publish("1/stream/test/cpu", []byte("1"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	_, err = b2.Publish("1/stream/test/mem", []byte("2"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	require.NoError(t, b2.PublishJoin("1/stream/test/cpu", &centrifuge.ClientInfo{ClientID: "client"}))
+	require.NoError(t, b1.PublishControl([]byte("all"), "", ""))
+	require.NoError(t, b1.PublishControl([]byte("b2"), b2.node.ID(), ""))
+
+	require.Eventually(t, func() bool {
+		publications, joins, controls := h1.get()
+		return len(publications) == 1 && len(joins) == 1 && len(controls) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		publications, joins, controls := h2.get()
+		return len(publications) == 2 && len(joins) == 1 && len(controls) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	publications, joins, controls := h1.get()
+	require.Equal(t, []string{"1/stream/test/cpu:1"}, publications, "publications of channels without subscription are skipped")
+	require.Equal(t, []string{"1/stream/test/cpu:client"}, joins)
+	require.Equal(t, []string{"all"}, controls, "control messages for other nodes are skipped")
+
+	publications, _, controls = h2.get()
+	require.Equal(t, []string{"1/stream/test/cpu:1", "1/stream/test/mem:2"}, publications)
+	require.Equal(t, []string{"all", "b2"}, controls)
+
+	require.NoError(t, b1.Unsubscribe("1/stream/test/cpu"))
+	_, err = b2.Publish("1/stream/test/cpu", []byte("3"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		publications, _, _ := h2.get()
+		return len(publications) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	publications, _, _ = h1.get()
+	require.Len(t, publications, 1)
+}
+
+func TestBroker_SkipsPreviousMessages(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	b1, h1 := newTestBroker(t, store)
+	require.NoError(t, b1.Subscribe("1/stream/test/cpu"))
+	_, err := b1.Publish("1/stream/test/cpu", []byte("1"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		publications, _, _ := h1.get()
+		return len(publications) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	b2, h2 := newTestBroker(t, store)
+	require.NoError(t, b2.Subscribe("1/stream/test/cpu"))
+	_, err = b1.Publish("1/stream/test/cpu", []byte("2"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		publications, _, _ := h2.get()
+		return len(publications) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	publications, _, _ := h2.get()
+	require.Equal(t, []string{"1/stream/test/cpu:2"}, publications)
+}
+
+func TestBroker_ConcurrentPublishers(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	b1, _ := newTestBroker(t, store)
+	b2, _ := newTestBroker(t, store)
+	b3, h3 := newTestBroker(t, store)
+	require.NoError(t, b3.Subscribe("1/stream/test/cpu"))
+
+	const publishers, messages = 4, 50
+	var expected []string
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		b := b1
+		if i%2 == 1 {
+			b = b2
+		}
+		for j := 0; j < messages; j++ {
+			expected = append(expected, fmt.Sprintf("1/stream/test/cpu:%d-%d", i, j))
+		}
+		wg.Add(1)
+		go func(i int, b *Broker) {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				_, err := b.Publish("1/stream/test/cpu", []byte(fmt.Sprintf("%d-%d", i, j)), centrifuge.PublishOptions{})
+				require.NoError(t, err)
+			}
+		}(i, b)
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		publications, _, _ := h3.get()
+		return len(publications) >= len(expected)
+	}, 5*time.Second, 10*time.Millisecond)
+	// Let the broker poll again, in case it would handle messages twice.
+	time.Sleep(50 * time.Millisecond)
+
+	publications, _, _ := h3.get()
+	sort.Strings(publications)
+	sort.Strings(expected)
+	require.Equal(t, expected, publications, "every message is handled exactly once")
+}
+
+func TestBroker_ReadsMessagesCommittedOutOfOrder(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	b, h := newTestBroker(t, store)
+	require.NoError(t, b.Subscribe("1/stream/test/cpu"))
+
+	// The IDs are allocated in one order, and the messages are committed in the other.
+	insert := func(id int64, data string) {
+		t.Helper()
+		payload, err := json.Marshal(message{Type: messageTypePublication, Channel: "1/stream/test/cpu", Data: []byte(data)})
+		require.NoError(t, err)
+		err = store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("INSERT INTO live_ha_message (id, payload, created) VALUES (?, ?, ?)", id, string(payload), toMillis(time.Now()))
+			return err
+		})
+		require.NoError(t, err)
+	}
+	insert(2, "second")
+	require.Eventually(t, func() bool {
+		publications, _, _ := h.get()
+		return len(publications) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	insert(1, "first")
+	require.Eventually(t, func() bool {
+		publications, _, _ := h.get()
+		return len(publications) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	publications, _, _ := h.get()
+	require.Equal(t, []string{"1/stream/test/cpu:second", "1/stream/test/cpu:first"}, publications)
+}
@@ -0,0 +1,134 @@
+package sqlengine
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// DefaultPresenceTTL is a default value for presence TTL in the database.
+const DefaultPresenceTTL = 60 * time.Second
+
+type presenceRow struct {
+	ClientId string
+	Info     string
+}
+
+// PresenceManagerConfig is a config for PresenceManager.
+type PresenceManagerConfig struct {
+	// PresenceTTL is an interval how long to consider presence info valid after
+	// receiving presence ping. Zero value means that DefaultPresenceTTL will be used.
+	PresenceTTL time.Duration
+}
+
+// PresenceManager is a centrifuge.PresenceManager which keeps presence information in the
+// live_ha_presence table of the Grafana database, so that it is shared by Grafana instances.
+type PresenceManager struct {
+	store  *sqlstore.SQLStore
+	config PresenceManagerConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+var _ centrifuge.PresenceManager = (*PresenceManager)(nil)
+
+// NewPresenceManager creates a PresenceManager.
+func NewPresenceManager(store *sqlstore.SQLStore, config PresenceManagerConfig) *PresenceManager {
+	if config.PresenceTTL == 0 {
+		config.PresenceTTL = DefaultPresenceTTL
+	}
+	return &PresenceManager{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Presence - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	var rows []presenceRow
+	err := m.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("live_ha_presence").Cols("client_id", "info").Where("channel = ? AND expires > ?", ch, toMillis(m.now())).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	presence := make(map[string]*centrifuge.ClientInfo, len(rows))
+	for _, row := range rows {
+		var info centrifuge.ClientInfo
+		if err := json.Unmarshal([]byte(row.Info), &info); err != nil {
+			return nil, err
+		}
+		presence[row.ClientId] = &info
+	}
+	return presence, nil
+}
+
+// PresenceStats - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	presence, err := m.Presence(ch)
+	if err != nil {
+		return centrifuge.PresenceStats{}, err
+	}
+	users := map[string]struct{}{}
+	for _, info := range presence {
+		users[info.UserID] = struct{}{}
+	}
+	return centrifuge.PresenceStats{
+		NumClients: len(presence),
+		NumUsers:   len(users),
+	}, nil
+}
+
+// AddPresence - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) AddPresence(ch string, clientID string, info *centrifuge.ClientInfo) error {
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	err = m.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		upsertSQL := m.store.Dialect.UpsertSQL(
+			"live_ha_presence",
+			[]string{"channel", "client_id"},
+			[]string{"channel", "client_id", "info", "expires"})
+		_, err := sess.Exec(upsertSQL, ch, clientID, string(infoJSON), toMillis(now.Add(m.config.PresenceTTL)))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return m.cleanup(now)
+}
+
+// cleanup deletes the expired presence information of all channels, at most once per TTL.
+// Presence information of clients which were not removed expires, but would stay in the
+// database forever otherwise.
+func (m *PresenceManager) cleanup(now time.Time) error {
+	m.mu.Lock()
+	if now.Sub(m.lastCleanup) < m.config.PresenceTTL {
+		m.mu.Unlock()
+		return nil
+	}
+	m.lastCleanup = now
+	m.mu.Unlock()
+	return m.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM live_ha_presence WHERE expires <= ?", toMillis(now))
+		return err
+	})
+}
+
+// RemovePresence - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) RemovePresence(ch string, clientID string) error {
+	return m.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM live_ha_presence WHERE channel = ? AND client_id = ?", ch, clientID)
+		return err
+	})
+}
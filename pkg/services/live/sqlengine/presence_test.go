@@ -0,0 +1,51 @@
+//go:build integration
+// +build integration
+
+package sqlengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceManager(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	now := time.Unix(1000, 0)
+	m1 := NewPresenceManager(store, PresenceManagerConfig{PresenceTTL: time.Minute})
+	m1.now = func() time.Time { return now }
+	m2 := NewPresenceManager(store, PresenceManagerConfig{PresenceTTL: time.Minute})
+	m2.now = func() time.Time { return now }
+
+	require.NoError(t, m1.AddPresence("1/grafana/test", "c1", &centrifuge.ClientInfo{ClientID: "c1", UserID: "1"}))
+	require.NoError(t, m2.AddPresence("1/grafana/test", "c2", &centrifuge.ClientInfo{ClientID: "c2", UserID: "1"}))
+	require.NoError(t, m2.AddPresence("1/grafana/test", "c3", &centrifuge.ClientInfo{ClientID: "c3", UserID: "2"}))
+	require.NoError(t, m2.AddPresence("1/grafana/other", "c3", &centrifuge.ClientInfo{ClientID: "c3", UserID: "2"}))
+
+	presence, err := m1.Presence("1/grafana/test")
+	require.NoError(t, err)
+	require.Len(t, presence, 3)
+	require.Equal(t, "2", presence["c3"].UserID)
+
+	stats, err := m1.PresenceStats("1/grafana/test")
+	require.NoError(t, err)
+	require.Equal(t, centrifuge.PresenceStats{NumClients: 3, NumUsers: 2}, stats)
+
+	require.NoError(t, m1.RemovePresence("1/grafana/test", "c2"))
+	stats, err = m2.PresenceStats("1/grafana/test")
+	require.NoError(t, err)
+	require.Equal(t, centrifuge.PresenceStats{NumClients: 2, NumUsers: 2}, stats)
+
+	// Presence expires unless it is added again.
+	now = now.Add(45 * time.Second)
+	require.NoError(t, m1.AddPresence("1/grafana/test", "c1", &centrifuge.ClientInfo{ClientID: "c1", UserID: "1"}))
+	now = now.Add(30 * time.Second)
+	presence, err = m2.Presence("1/grafana/test")
+	require.NoError(t, err)
+	require.Len(t, presence, 1)
+	require.Contains(t, presence, "c1")
+}
@@ -0,0 +1,143 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	influx "github.com/influxdata/line-protocol"
+)
+
+const (
+	// DefaultTimeField is the field with the time of objects by default.
+	DefaultTimeField = "time"
+	// DefaultName is the name of frames by default.
+	DefaultName = "json"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts plain JSON objects to Grafana frames.
+//
+// The input is an object or an array of objects. Every object is a row of the frame: the
+// time field has the time of the row, as an RFC 3339 string or a number of milliseconds
+// since the epoch, and the other values are fields. The fields of nested objects are named
+// with the keys of the objects joined by underscores. Null values are skipped.
+type Converter struct {
+	frames    *telegraf.Converter
+	timeField string
+	name      string
+	now       func() time.Time
+}
+
+// ConverterOption configures a Converter.
+type ConverterOption func(*Converter)
+
+// WithTimeField sets the field with the time of objects. Objects without it get the time
+// of the conversion.
+func WithTimeField(timeField string) ConverterOption {
+	return func(c *Converter) {
+		if timeField != "" {
+			c.timeField = timeField
+		}
+	}
+}
+
+// WithName sets the name of frames, which is the path of the frames in a managed stream.
+func WithName(name string) ConverterOption {
+	return func(c *Converter) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+// NewConverter creates new Converter from JSON objects to Grafana Data Frames.
+func NewConverter(frames *telegraf.Converter, opts ...ConverterOption) *Converter {
+	c := &Converter{
+		frames:    frames,
+		timeField: DefaultTimeField,
+		name:      DefaultName,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert objects.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var objects []map[string]interface{}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &objects); err != nil {
+			return nil, fmt.Errorf("error parsing objects: %w", err)
+		}
+	} else {
+		var object map[string]interface{}
+		if err := json.Unmarshal(body, &object); err != nil {
+			return nil, fmt.Errorf("error parsing object: %w", err)
+		}
+		objects = append(objects, object)
+	}
+
+	// Objects without time get the same time, so that they end up in the same frame.
+	now := c.now()
+	metrics := make([]influx.Metric, 0, len(objects))
+	for i, object := range objects {
+		t := now
+		if v, ok := object[c.timeField]; ok {
+			var err error
+			if t, err = parseTime(v); err != nil {
+				return nil, fmt.Errorf("object %d: invalid time field %q: %w", i, c.timeField, err)
+			}
+			delete(object, c.timeField)
+		}
+		fields := map[string]interface{}{}
+		if err := flatten("", object, fields); err != nil {
+			return nil, fmt.Errorf("object %d: %w", i, err)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("object %d has no fields", i)
+		}
+		metric, err := influx.New(c.name, nil, fields, t)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", i, err)
+		}
+		metrics = append(metrics, metric)
+	}
+	return c.frames.ConvertMetrics(metrics)
+}
+
+func parseTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case float64:
+		return time.Unix(0, int64(v*float64(time.Millisecond))), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	}
+	return time.Time{}, errors.New("the time must be an RFC 3339 string or a number of milliseconds")
+}
+
+func flatten(prefix string, object map[string]interface{}, fields map[string]interface{}) error {
+	for k, v := range object {
+		name := prefix + k
+		switch v := v.(type) {
+		case nil:
+		case float64, string, bool:
+			fields[name] = v
+		case map[string]interface{}:
+			if err := flatten(name+"_", v, fields); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("field %q: arrays are not supported", name)
+		}
+	}
+	return nil
+}
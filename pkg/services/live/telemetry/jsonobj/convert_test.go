@@ -0,0 +1,91 @@
+package jsonobj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestConverter_Convert(t *testing.T) {
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithFloat64Numbers(true)))
+	frameWrappers, err := converter.Convert([]byte(`{
+		"time": 1395066363000,
+		"cpu": 0.25,
+		"host": "a",
+		"up": true,
+		"memory": {"used": 512, "free": null}
+	}`))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	require.Equal(t, DefaultName, frameWrappers[0].Key())
+
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, []string{"time", "cpu", "host", "memory_used", "up"}, fieldNames(frame))
+	require.Equal(t, time.Unix(1395066363, 0), frame.Fields[0].At(0))
+	require.Equal(t, 0.25, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, "a", *frame.Fields[2].At(0).(*string))
+	require.Equal(t, 512.0, *frame.Fields[3].At(0).(*float64))
+	require.Equal(t, true, *frame.Fields[4].At(0).(*bool))
+}
+
+func TestConverter_Convert_Array(t *testing.T) {
+	converter := NewConverter(
+		telegraf.NewConverter(telegraf.WithUseLabelsColumn(true), telegraf.WithFloat64Numbers(true)),
+		WithTimeField("ts"),
+		WithName("sensors"),
+	)
+	frameWrappers, err := converter.Convert([]byte(`[
+		{"ts": "2021-07-01T10:00:00Z", "temperature": 21.5},
+		{"ts": "2021-07-01T10:00:01.5Z", "temperature": 21.7, "humidity": 40}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	require.Equal(t, "sensors", frameWrappers[0].Key())
+
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, []string{"labels", "time", "temperature", "humidity"}, fieldNames(frame))
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, time.Date(2021, 7, 1, 10, 0, 1, 5e8, time.UTC), frame.Fields[1].At(1))
+	require.Nil(t, frame.Fields[3].At(0))
+}
+
+func TestConverter_Convert_WithoutTime(t *testing.T) {
+	now := time.Unix(1000, 0)
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithFloat64Numbers(true)))
+	converter.now = func() time.Time { return now }
+	frameWrappers, err := converter.Convert([]byte(`{"cpu": 0.25}`))
+	require.NoError(t, err)
+	require.Equal(t, now, frameWrappers[0].Frame().Fields[0].At(0))
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	testCases := []struct {
+		desc string
+		body string
+		err  string
+	}{
+		{desc: "invalid JSON", body: `{"cpu": `, err: "error parsing object"},
+		{desc: "invalid time", body: `{"time": true, "cpu": 1}`, err: `object 0: invalid time field "time"`},
+		{desc: "array value", body: `[{"cpu": 1}, {"cpu": [1, 2]}]`, err: `object 1: field "cpu": arrays are not supported`},
+		{desc: "no fields", body: `{"time": 1000}`, err: "object 0 has no fields"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			converter := NewConverter(telegraf.NewConverter())
+			_, err := converter.Convert([]byte(tc.body))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package otlp
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	influx "github.com/influxdata/line-protocol"
+	otlpcollector "go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metric payloads, as sent to the /v1/metrics endpoint of OTLP/HTTP,
+// to Grafana frames.
+//
+// Metrics are converted as the Prometheus metrics: the metric name is the frame name, the
+// resource attributes and the labels of the data points are the labels of the fields, and
+// the value is in a field named after the metric type: counter for monotonic sums, gauge
+// for gauges and other sums. Summaries and histograms have sum and count fields, and a field
+// for each quantile or bucket bound with the cumulative count of the bucket.
+type Converter struct {
+	frames      *telegraf.Converter
+	unmarshaler pdata.MetricsUnmarshaler
+}
+
+// ConverterOption configures a Converter.
+type ConverterOption func(*Converter)
+
+// WithJSON makes the converter read payloads in the JSON encoding of OTLP instead of Protobuf.
+func WithJSON(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		if enabled {
+			c.unmarshaler = otlpcollector.NewJSONMetricsUnmarshaler()
+		}
+	}
+}
+
+// NewConverter creates new Converter from OTLP metrics to Grafana Data Frames.
+func NewConverter(frames *telegraf.Converter, opts ...ConverterOption) *Converter {
+	c := &Converter{
+		frames:      frames,
+		unmarshaler: otlpcollector.NewProtobufMetricsUnmarshaler(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	md, err := c.unmarshaler.UnmarshalMetrics(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	var metrics []influx.Metric
+	resourceMetrics := md.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		resourceTags := attributesToTags(rm.Resource().Attributes())
+		libraryMetrics := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < libraryMetrics.Len(); j++ {
+			ms := libraryMetrics.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				converted, err := convertMetric(ms.At(k), resourceTags)
+				if err != nil {
+					return nil, err
+				}
+				metrics = append(metrics, converted...)
+			}
+		}
+	}
+	return c.frames.ConvertMetrics(metrics)
+}
+
+// point is a data point of any metric type.
+type point interface {
+	LabelsMap() pdata.StringMap
+	Timestamp() pdata.Timestamp
+}
+
+func convertMetric(m pdata.Metric, resourceTags map[string]string) ([]influx.Metric, error) {
+	var metrics []influx.Metric
+	add := func(p point, fields map[string]interface{}) error {
+		if len(fields) == 0 {
+			return nil
+		}
+		tags := make(map[string]string, len(resourceTags)+p.LabelsMap().Len())
+		for k, v := range resourceTags {
+			tags[k] = v
+		}
+		p.LabelsMap().Range(func(k string, v string) bool {
+			tags[k] = v
+			return true
+		})
+		metric, err := influx.New(m.Name(), tags, fields, p.Timestamp().AsTime())
+		if err != nil {
+			return fmt.Errorf("error converting metric %s: %w", m.Name(), err)
+		}
+		metrics = append(metrics, metric)
+		return nil
+	}
+
+	switch m.DataType() {
+	case pdata.MetricDataTypeGauge:
+		if err := addNumberPoints(m.Gauge().DataPoints(), "gauge", add); err != nil {
+			return nil, err
+		}
+	case pdata.MetricDataTypeSum:
+		fieldName := "gauge"
+		if m.Sum().IsMonotonic() {
+			fieldName = "counter"
+		}
+		if err := addNumberPoints(m.Sum().DataPoints(), fieldName, add); err != nil {
+			return nil, err
+		}
+	case pdata.MetricDataTypeIntGauge:
+		if err := addIntPoints(m.IntGauge().DataPoints(), "gauge", add); err != nil {
+			return nil, err
+		}
+	case pdata.MetricDataTypeIntSum:
+		fieldName := "gauge"
+		if m.IntSum().IsMonotonic() {
+			fieldName = "counter"
+		}
+		if err := addIntPoints(m.IntSum().DataPoints(), fieldName, add); err != nil {
+			return nil, err
+		}
+	case pdata.MetricDataTypeHistogram:
+		points := m.Histogram().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			fields := map[string]interface{}{}
+			telegraf.AddFloatField(fields, "sum", p.Sum())
+			telegraf.AddFloatField(fields, "count", float64(p.Count()))
+			// Bucket counts of OTLP are not cumulative, unlike the ones of Prometheus.
+			var cumulative uint64
+			bounds := p.ExplicitBounds()
+			for j, count := range p.BucketCounts() {
+				cumulative += count
+				bound := "+Inf"
+				if j < len(bounds) {
+					bound = strconv.FormatFloat(bounds[j], 'g', -1, 64)
+				}
+				telegraf.AddFloatField(fields, bound, float64(cumulative))
+			}
+			if err := add(p, fields); err != nil {
+				return nil, err
+			}
+		}
+	case pdata.MetricDataTypeSummary:
+		points := m.Summary().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			fields := map[string]interface{}{}
+			telegraf.AddFloatField(fields, "sum", p.Sum())
+			telegraf.AddFloatField(fields, "count", float64(p.Count()))
+			quantiles := p.QuantileValues()
+			for j := 0; j < quantiles.Len(); j++ {
+				q := quantiles.At(j)
+				telegraf.AddFloatField(fields, strconv.FormatFloat(q.Quantile(), 'g', -1, 64), q.Value())
+			}
+			if err := add(p, fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func addNumberPoints(points pdata.NumberDataPointSlice, fieldName string, add func(point, map[string]interface{}) error) error {
+	for i := 0; i < points.Len(); i++ {
+		p := points.At(i)
+		fields := map[string]interface{}{}
+		switch p.Type() {
+		case pdata.MetricValueTypeInt:
+			telegraf.AddFloatField(fields, fieldName, float64(p.IntVal()))
+		case pdata.MetricValueTypeDouble:
+			telegraf.AddFloatField(fields, fieldName, p.DoubleVal())
+		}
+		if err := add(p, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addIntPoints(points pdata.IntDataPointSlice, fieldName string, add func(point, map[string]interface{}) error) error {
+	for i := 0; i < points.Len(); i++ {
+		p := points.At(i)
+		if err := add(p, map[string]interface{}{fieldName: float64(p.Value())}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attributesToTags converts the attributes of a resource to tags. Attributes that are not
+// scalar values are skipped.
+func attributesToTags(attributes pdata.AttributeMap) map[string]string {
+	tags := make(map[string]string, attributes.Len())
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		switch v.Type() {
+		case pdata.AttributeValueTypeString:
+			tags[k] = v.StringVal()
+		case pdata.AttributeValueTypeInt:
+			tags[k] = strconv.FormatInt(v.IntVal(), 10)
+		case pdata.AttributeValueTypeDouble:
+			tags[k] = strconv.FormatFloat(v.DoubleVal(), 'g', -1, 64)
+		case pdata.AttributeValueTypeBool:
+			tags[k] = strconv.FormatBool(v.BoolVal())
+		}
+		return true
+	})
+	return tags
+}
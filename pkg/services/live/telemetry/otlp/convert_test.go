@@ -0,0 +1,119 @@
+package otlp
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	"github.com/stretchr/testify/require"
+	otlpcollector "go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testMetrics() pdata.Metrics {
+	ts := pdata.TimestampFromTime(time.Unix(1395066363, 0))
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("service.name", "checkout")
+	rm.Resource().Attributes().InsertInt("service.instance", 2)
+	ms := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+
+	m := ms.AppendEmpty()
+	m.SetName("memory_usage")
+	m.SetDataType(pdata.MetricDataTypeGauge)
+	p := m.Gauge().DataPoints().AppendEmpty()
+	p.SetTimestamp(ts)
+	p.SetDoubleVal(0.75)
+
+	m = ms.AppendEmpty()
+	m.SetName("requests")
+	m.SetDataType(pdata.MetricDataTypeSum)
+	m.Sum().SetIsMonotonic(true)
+	for _, r := range []struct {
+		code  string
+		count int64
+	}{{"200", 1027}, {"500", 3}} {
+		p := m.Sum().DataPoints().AppendEmpty()
+		p.SetTimestamp(ts)
+		p.LabelsMap().Insert("code", r.code)
+		p.SetIntVal(r.count)
+	}
+
+	m = ms.AppendEmpty()
+	m.SetName("latency")
+	m.SetDataType(pdata.MetricDataTypeHistogram)
+	hp := m.Histogram().DataPoints().AppendEmpty()
+	hp.SetTimestamp(ts)
+	hp.SetCount(10)
+	hp.SetSum(4.2)
+	hp.SetExplicitBounds([]float64{0.1, 1})
+	hp.SetBucketCounts([]uint64{2, 5, 3})
+
+	m = ms.AppendEmpty()
+	m.SetName("response_size")
+	m.SetDataType(pdata.MetricDataTypeSummary)
+	sp := m.Summary().DataPoints().AppendEmpty()
+	sp.SetTimestamp(ts)
+	sp.SetCount(4)
+	sp.SetSum(1024)
+	q := sp.QuantileValues().AppendEmpty()
+	q.SetQuantile(0.99)
+	q.SetValue(512)
+	return md
+}
+
+func TestConverter_Convert(t *testing.T) {
+	body, err := otlpcollector.NewProtobufMetricsMarshaler().MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithFloat64Numbers(true)))
+	frameWrappers, err := converter.Convert(body)
+	require.NoError(t, err)
+
+	dr := &backend.DataResponse{}
+	for _, w := range frameWrappers {
+		dr.Frames = append(dr.Frames, w.Frame())
+	}
+	err = experimental.CheckGoldenDataResponse(filepath.Join("testdata", "metrics.golden.txt"), dr, *update)
+	require.NoError(t, err)
+
+	requests := dr.Frames[1]
+	require.Equal(t, "requests", requests.Name)
+	require.Len(t, requests.Fields, 3)
+	require.Equal(t, "counter", requests.Fields[1].Name)
+	require.Equal(t, data.Labels{"code": "500", "service.instance": "2", "service.name": "checkout"}, requests.Fields[2].Labels)
+
+	latency := dr.Frames[2]
+	require.Equal(t, []string{"time", "+Inf", "0.1", "1", "count", "sum"}, fieldNames(latency))
+	require.Equal(t, 10.0, *latency.Fields[1].At(0).(*float64), "bucket counts are cumulative")
+	require.Equal(t, 7.0, *latency.Fields[3].At(0).(*float64))
+}
+
+func TestConverter_Convert_JSON(t *testing.T) {
+	body, err := otlpcollector.NewJSONMetricsMarshaler().MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithUseLabelsColumn(true)), WithJSON(true))
+	frameWrappers, err := converter.Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 4)
+	require.Equal(t, 2, frameWrappers[1].Frame().Rows())
+
+	_, err = NewConverter(telegraf.NewConverter()).Convert(body)
+	require.Error(t, err, "payloads are Protobuf by default")
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	influx "github.com/influxdata/line-protocol"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts metrics in Prometheus text exposition format to Grafana frames.
+//
+// Metrics are converted as the prometheus input of Telegraf does: the metric name is the
+// frame name, the metric labels are the labels of the fields, and the value is in a field
+// named after the metric type: counter, gauge or value for untyped metrics. Summaries and
+// histograms have sum and count fields, and a field for each quantile or bucket bound.
+type Converter struct {
+	frames *telegraf.Converter
+	now    func() time.Time
+}
+
+// NewConverter creates new Converter from Prometheus text exposition format to Grafana Data Frames.
+func NewConverter(frames *telegraf.Converter) *Converter {
+	return &Converter{
+		frames: frames,
+		now:    time.Now,
+	}
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	// Families are in a map, sort them to keep the order of frames stable.
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Metrics without timestamp get the same time, so that they end up in the same frame.
+	now := c.now()
+	var metrics []influx.Metric
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.GetMetric() {
+			fields := metricFields(family.GetType(), m)
+			if len(fields) == 0 {
+				continue
+			}
+			tags := make(map[string]string, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				tags[l.GetName()] = l.GetValue()
+			}
+			t := now
+			if m.TimestampMs != nil {
+				t = time.Unix(0, m.GetTimestampMs()*int64(time.Millisecond))
+			}
+			metric, err := influx.New(name, tags, fields, t)
+			if err != nil {
+				return nil, fmt.Errorf("error converting metric %s: %w", name, err)
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return c.frames.ConvertMetrics(metrics)
+}
+
+func metricFields(metricType dto.MetricType, m *dto.Metric) map[string]interface{} {
+	fields := map[string]interface{}{}
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		telegraf.AddFloatField(fields, "counter", m.GetCounter().GetValue())
+	case dto.MetricType_GAUGE:
+		telegraf.AddFloatField(fields, "gauge", m.GetGauge().GetValue())
+	case dto.MetricType_SUMMARY:
+		summary := m.GetSummary()
+		telegraf.AddFloatField(fields, "sum", summary.GetSampleSum())
+		telegraf.AddFloatField(fields, "count", float64(summary.GetSampleCount()))
+		for _, q := range summary.GetQuantile() {
+			telegraf.AddFloatField(fields, formatFloat(q.GetQuantile()), q.GetValue())
+		}
+	case dto.MetricType_HISTOGRAM:
+		histogram := m.GetHistogram()
+		telegraf.AddFloatField(fields, "sum", histogram.GetSampleSum())
+		telegraf.AddFloatField(fields, "count", float64(histogram.GetSampleCount()))
+		for _, b := range histogram.GetBucket() {
+			telegraf.AddFloatField(fields, formatFloat(b.GetUpperBound()), float64(b.GetCumulativeCount()))
+		}
+	default:
+		telegraf.AddFloatField(fields, "value", m.GetUntyped().GetValue())
+	}
+	return fields
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func loadTestData(tb testing.TB, file string) []byte {
+	tb.Helper()
+	// Safe to disable, this is a test.
+	// nolint:gosec
+	content, err := ioutil.ReadFile(filepath.Join("testdata", file+".txt"))
+	require.NoError(tb, err, "expected to be able to read file")
+	require.True(tb, len(content) > 0)
+	return content
+}
+
+func checkGolden(tb testing.TB, file string, frameWrappers []telemetry.FrameWrapper) {
+	tb.Helper()
+	dr := &backend.DataResponse{}
+	for _, w := range frameWrappers {
+		dr.Frames = append(dr.Frames, w.Frame())
+	}
+	err := experimental.CheckGoldenDataResponse(filepath.Join("testdata", file+".golden.txt"), dr, *update)
+	require.NoError(tb, err)
+}
+
+func TestConverter_Convert(t *testing.T) {
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithFloat64Numbers(true)))
+	frameWrappers, err := converter.Convert(loadTestData(t, "metrics"))
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		keys = append(keys, fw.Key())
+	}
+	require.Equal(t, []string{
+		"go_goroutines",
+		"http_request_duration_seconds",
+		"http_requests_total",
+		"metric_without_type",
+		"rpc_duration_seconds",
+	}, keys)
+	checkGolden(t, "metrics_wide", frameWrappers)
+}
+
+func TestConverter_Convert_LabelsColumn(t *testing.T) {
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithUseLabelsColumn(true), telegraf.WithFloat64Numbers(true)))
+	frameWrappers, err := converter.Convert(loadTestData(t, "metrics"))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 5)
+	checkGolden(t, "metrics_labels_column", frameWrappers)
+}
+
+func TestConverter_Convert_WithoutTimestamps(t *testing.T) {
+	now := time.Unix(1000, 0)
+	converter := NewConverter(telegraf.NewConverter(telegraf.WithFloat64Numbers(true)))
+	converter.now = func() time.Time { return now }
+	frameWrappers, err := converter.Convert([]byte("up{job=\"a\"} 1\nup{job=\"b\"} 0\n"))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1, "metrics without timestamp are in the same frame")
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, now, frame.Fields[0].At(0))
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	converter := NewConverter(telegraf.NewConverter())
+	_, err := converter.Convert([]byte("up{job=\"a\" 1\n"))
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package telegraf
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -65,12 +66,27 @@ func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing metrics: %w", err)
 	}
+	return c.ConvertMetrics(metrics)
+}
+
+// ConvertMetrics converts parsed metrics. Converters from other formats use it to build
+// frames the same way as for Telegraf metrics.
+func (c *Converter) ConvertMetrics(metrics []influx.Metric) ([]telemetry.FrameWrapper, error) {
 	if !c.useLabelsColumn {
 		return c.convertWideFields(metrics)
 	}
 	return c.convertWithLabelsColumn(metrics)
 }
 
+// AddFloatField adds a float value to the fields of a metric passed to ConvertMetrics. As in
+// the influx serializer of Telegraf, NaN and infinite values are dropped.
+func AddFloatField(fields map[string]interface{}, name string, v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	fields[name] = v
+}
+
 func (c *Converter) convertWideFields(metrics []influx.Metric) ([]telemetry.FrameWrapper, error) {
 	// maintain the order of frames as they appear in input.
 	var frameKeyOrder []string
@@ -63,6 +63,11 @@ type API struct {
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	StateHistoryStore    store.StateHistoryStore
+	ProvisioningStore    store.ProvisioningStore
+	DeliveryStore        store.NotificationDeliveryStore
+	LegacyImportStore    store.LegacyAlertImportStore
+	SilenceScheduleStore store.SilenceScheduleStore
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -76,7 +81,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, mam: api.MultiOrgAlertmanager, log: logger},
+		AlertmanagerSrv{store: api.AlertingStore, provisioningStore: api.ProvisioningStore, deliveryStore: api.DeliveryStore, silenceScheduleStore: api.SilenceScheduleStore, mam: api.MultiOrgAlertmanager, log: logger},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
@@ -88,7 +93,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, historyStore: api.StateHistoryStore, provisioningStore: api.ProvisioningStore, log: logger},
 	), m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
@@ -98,8 +103,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 		log:             logger,
 	}, m)
 	api.RegisterConfigurationApiEndpoints(AdminSrv{
-		store:     api.AdminConfigStore,
-		log:       logger,
-		scheduler: api.Schedule,
+		store:       api.AdminConfigStore,
+		importStore: api.LegacyImportStore,
+		log:         logger,
+		scheduler:   api.Schedule,
 	}, m)
 }
@@ -10,15 +10,17 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
 	"github.com/grafana/grafana/pkg/util"
 
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 )
 
 type AdminSrv struct {
-	scheduler Scheduler
-	store     store.AdminConfigurationStore
-	log       log.Logger
+	scheduler   Scheduler
+	store       store.AdminConfigurationStore
+	importStore store.LegacyAlertImportStore
+	log         log.Logger
 }
 
 func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Response {
@@ -93,3 +95,48 @@ func (srv AdminSrv) RouteDeleteNGalertConfig(c *models.ReqContext) response.Resp
 
 	return response.JSON(http.StatusOK, util.DynMap{"message": "admin configuration deleted"})
 }
+
+func (srv AdminSrv) RoutePostLegacyAlertImport(c *models.ReqContext, body apimodels.PostableLegacyAlertImport) response.Response {
+	if c.OrgRole != models.ROLE_ADMIN {
+		return accessForbiddenResp()
+	}
+
+	report, err := srv.importStore.ImportDashboardAlerts(c.Req.Context(), ualert.ImportCommand{
+		OrgID:         c.OrgId,
+		DashboardUIDs: body.DashboardUIDs,
+		FolderUIDs:    body.FolderUIDs,
+		DryRun:        body.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, ualert.ErrImportTargetNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		msg := "failed to import the legacy alerts"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	resp := apimodels.LegacyAlertImportReport{
+		DryRun:               report.DryRun,
+		Rules:                make([]apimodels.LegacyAlertImportRule, 0, len(report.Rules)),
+		CreatedFolders:       report.CreatedFolders,
+		CreatedContactPoints: report.CreatedContactPoints,
+	}
+	for _, r := range report.Rules {
+		rule := apimodels.LegacyAlertImportRule{
+			AlertID:      r.AlertID,
+			AlertName:    r.AlertName,
+			DashboardUID: r.DashboardUID,
+			PanelID:      r.PanelID,
+			Status:       r.Status,
+			RuleUID:      r.RuleUID,
+			FolderUID:    r.FolderUID,
+			Receiver:     r.Receiver,
+			Paused:       r.Paused,
+			Error:        r.Error,
+		}
+		resp.Rules = append(resp.Rules, rule)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
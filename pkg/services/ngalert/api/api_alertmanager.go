@@ -27,7 +27,12 @@ const (
 type AlertmanagerSrv struct {
 	mam   *notifier.MultiOrgAlertmanager
 	store store.AlertingStore
-	log   log.Logger
+	// provisioningStore is used to reject changes to provisioned configurations.
+	provisioningStore store.ProvisioningStore
+	deliveryStore     store.NotificationDeliveryStore
+	// silenceScheduleStore stores the recurring silences.
+	silenceScheduleStore store.SilenceScheduleStore
+	log                  log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -131,6 +136,10 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	if errResp := checkAlertmanagerConfigNotProvisioned(srv.provisioningStore, c.OrgId); errResp != nil {
+		return errResp
+	}
+
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
@@ -207,6 +216,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 				Name:                  pr.Name,
 				Type:                  pr.Type,
 				DisableResolveMessage: pr.DisableResolveMessage,
+				RateLimit:             pr.RateLimit,
 				Settings:              pr.Settings,
 				SecureFields:          secureFields,
 			}
@@ -310,11 +320,69 @@ func (srv AlertmanagerSrv) RouteGetSilences(c *models.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, gettableSilences)
 }
 
+func (srv AlertmanagerSrv) RouteGetNotificationDeliveries(c *models.ReqContext) response.Response {
+	q := ngmodels.ListNotificationDeliveriesQuery{
+		OrgID:       c.OrgId,
+		Receiver:    c.Query("receiver"),
+		Integration: c.Query("integration"),
+		Fingerprint: c.Query("fingerprint"),
+	}
+
+	switch status := ngmodels.NotificationDeliveryStatus(c.Query("status")); status {
+	case "", ngmodels.NotificationDeliverySuccess, ngmodels.NotificationDeliveryFailure, ngmodels.NotificationDeliveryDropped, ngmodels.NotificationDeliveryDigested:
+		q.Status = status
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("status must be %s, %s, %s or %s: %s",
+			ngmodels.NotificationDeliverySuccess, ngmodels.NotificationDeliveryFailure, ngmodels.NotificationDeliveryDropped, ngmodels.NotificationDeliveryDigested, status), "")
+	}
+
+	var err error
+	if q.From, err = parseHistoryTime(c.Query("from")); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid from")
+	}
+	if q.To, err = parseHistoryTime(c.Query("to")); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid to")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return ErrResp(http.StatusBadRequest, errors.New("to must not be before from"), "")
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be a positive integer: %s", limit), "")
+		}
+	}
+
+	if err := srv.deliveryStore.ListNotificationDeliveries(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification deliveries")
+	}
+
+	result := apimodels.NotificationDeliveriesResponse{Deliveries: make([]apimodels.NotificationDelivery, 0, len(q.Result))}
+	for _, d := range q.Result {
+		result.Deliveries = append(result.Deliveries, apimodels.NotificationDelivery{
+			Receiver:        d.Receiver,
+			Integration:     d.Integration,
+			IntegrationName: d.IntegrationName,
+			IntegrationUID:  d.IntegrationUID,
+			Fingerprints:    d.Fingerprints,
+			Status:          string(d.Status),
+			StatusCode:      d.StatusCode,
+			Error:           d.Error,
+			DurationMs:      d.DurationMs,
+			SentAt:          d.SentAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body apimodels.PostableUserConfig) response.Response {
 	if !c.HasUserRole(models.ROLE_EDITOR) {
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	if errResp := checkAlertmanagerConfigNotProvisioned(srv.provisioningStore, c.OrgId); errResp != nil {
+		return errResp
+	}
+
 	// Get the last known working configuration
 	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
 	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
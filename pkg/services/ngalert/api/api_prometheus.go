@@ -61,6 +61,16 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		},
 	}
 
+	healthFilter := make(map[string]bool)
+	for _, h := range c.QueryStrings("health") {
+		switch h {
+		case "ok", "error", "nodata":
+			healthFilter[h] = true
+		default:
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown health %q, expected one of ok, error or nodata", h), "")
+		}
+	}
+
 	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
@@ -101,7 +111,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			// so we use this field for passing to the frontend the namespace
 			File:           namespace,
 			LastEvaluation: time.Time{},
-			EvaluationTime: 0, // TODO: see if we are able to pass this along with evaluation results
+			EvaluationTime: 0,
 		}
 
 		for _, rule := range alertRuleQuery.Result {
@@ -144,7 +154,6 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 
 				if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
 					newRule.LastEvaluation = alertState.LastEvaluationTime
-					newGroup.LastEvaluation = alertState.LastEvaluationTime
 				}
 
 				newRule.EvaluationTime = alertState.EvaluationDuration.Seconds()
@@ -161,19 +170,57 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 					newRule.Health = "error"
 				case eval.NoData:
 					newRule.Health = "nodata"
+				case eval.Suppressed:
+					// suppressed instances are listed with their state but
+					// do not make the rule pending or firing
 				}
 
 				if alertState.Error != nil {
 					newRule.LastError = alertState.Error.Error()
 					newRule.Health = "error"
+				} else if alertState.TemplateError != nil && newRule.LastError == "" {
+					// Evaluation errors take precedence over template errors.
+					newRule.LastError = alertState.TemplateError.Error()
+					newRule.Health = "error"
 				}
 				alertingRule.Alerts = append(alertingRule.Alerts, alert)
 			}
 
+			// The health of the evaluations of the rule takes precedence over the states of its instances,
+			// as an evaluation that fails entirely produces no instance.
+			if health, ok := srv.manager.GetRuleHealth(c.OrgId, rule.UID); ok {
+				newRule.LastEvaluation = health.LastEvaluation
+				newRule.EvaluationTime = health.EvaluationDuration.Seconds()
+				newRule.ConsecutiveFailures = health.ConsecutiveFailures
+				if health.LastError != nil {
+					newRule.Health = "error"
+					newRule.LastError = health.LastError.Error()
+				}
+			}
+
+			if rule.IsPausedAt(time.Now()) {
+				newRule.Paused = true
+				if !rule.PausedUntil.IsZero() {
+					pausedUntil := rule.PausedUntil
+					newRule.PausedUntil = &pausedUntil
+				}
+			}
+
+			if len(healthFilter) > 0 && !healthFilter[newRule.Health] {
+				continue
+			}
+			if newRule.LastEvaluation.After(newGroup.LastEvaluation) {
+				newGroup.LastEvaluation = newRule.LastEvaluation
+			}
+			newGroup.EvaluationTime += newRule.EvaluationTime
+
 			alertingRule.Rule = newRule
 			newGroup.Rules = append(newGroup.Rules, alertingRule)
 			newGroup.Interval = float64(rule.IntervalSeconds)
 		}
+		if len(healthFilter) > 0 && len(newGroup.Rules) == 0 {
+			continue
+		}
 		ruleResponse.Data.RuleGroups = append(ruleResponse.Data.RuleGroups, newGroup)
 	}
 	return response.JSON(http.StatusOK, ruleResponse)
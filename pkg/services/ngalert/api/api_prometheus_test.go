@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+// fakeRuleGroupStore returns the rules of the groups of a single namespace.
+type fakeRuleGroupStore struct {
+	store.RuleStore
+	groups map[string][]*ngmodels.AlertRule
+}
+
+func (f fakeRuleGroupStore) GetNamespaces(int64, *models.SignedInUser) (map[string]*models.Folder, error) {
+	return map[string]*models.Folder{"namespace-uid": {Uid: "namespace-uid", Title: "namespace"}}, nil
+}
+
+func (f fakeRuleGroupStore) GetOrgRuleGroups(q *ngmodels.ListOrgRuleGroupsQuery) error {
+	for group := range f.groups {
+		q.Result = append(q.Result, []string{group, "namespace-uid", "namespace"})
+	}
+	return nil
+}
+
+func (f fakeRuleGroupStore) GetRuleGroupAlertRules(q *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	q.Result = f.groups[q.RuleGroup]
+	return nil
+}
+
+func TestRouteGetRuleStatusesHealthFilter(t *testing.T) {
+	newRule := func(uid, group string) *ngmodels.AlertRule {
+		return &ngmodels.AlertRule{
+			OrgID:           1,
+			UID:             uid,
+			Title:           uid,
+			NamespaceUID:    "namespace-uid",
+			RuleGroup:       group,
+			IntervalSeconds: 60,
+		}
+	}
+	healthy, broken, noData := newRule("healthy", "group-a"), newRule("broken", "group-a"), newRule("no-data", "group-a")
+	noData.NoDataState = ngmodels.NoData
+	otherHealthy := newRule("other-healthy", "group-b")
+
+	now := time.Now()
+	manager := state.NewDryRunManager(log.New("test"))
+	manager.RecordEvaluation(1, healthy.UID, now, time.Second, nil)
+	manager.RecordEvaluation(1, otherHealthy.UID, now, time.Second, nil)
+	manager.RecordEvaluation(1, broken.UID, now, time.Second, errors.New("query failed"))
+	manager.ProcessEvalResults(noData, eval.Results{{Instance: data.Labels{}, State: eval.NoData, EvaluatedAt: now}})
+
+	srv := PrometheusSrv{
+		log:     log.New("test"),
+		manager: manager,
+		store: fakeRuleGroupStore{groups: map[string][]*ngmodels.AlertRule{
+			"group-a": {healthy, broken, noData},
+			"group-b": {otherHealthy},
+		}},
+	}
+
+	getRuleStatuses := func(t *testing.T, query string) (int, map[string][]string) {
+		req, err := http.NewRequest(http.MethodGet, "/api/prometheus/grafana/api/v1/rules?"+query, nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{
+			Context:      &macaron.Context{Req: req},
+			SignedInUser: &models.SignedInUser{OrgId: 1},
+		}
+		resp := srv.RouteGetRuleStatuses(c)
+		if resp.Status() != http.StatusOK {
+			return resp.Status(), nil
+		}
+
+		var body apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &body))
+		rulesByGroup := make(map[string][]string)
+		for _, g := range body.Data.RuleGroups {
+			rulesByGroup[g.Name] = []string{}
+			for _, r := range g.Rules {
+				rulesByGroup[g.Name] = append(rulesByGroup[g.Name], r.Name+": "+r.Health)
+			}
+		}
+		return resp.Status(), rulesByGroup
+	}
+
+	t.Run("all the rules are returned without filter", func(t *testing.T) {
+		status, rules := getRuleStatuses(t, "")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, map[string][]string{
+			"group-a": {"healthy: ok", "broken: error", "no-data: nodata"},
+			"group-b": {"other-healthy: ok"},
+		}, rules)
+	})
+
+	t.Run("only the rules with the health are returned, without empty groups", func(t *testing.T) {
+		status, rules := getRuleStatuses(t, "health=error")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, map[string][]string{
+			"group-a": {"broken: error"},
+		}, rules)
+	})
+
+	t.Run("the health can be repeated", func(t *testing.T) {
+		status, rules := getRuleStatuses(t, "health=error&health=nodata")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, map[string][]string{
+			"group-a": {"broken: error", "no-data: nodata"},
+		}, rules)
+	})
+
+	t.Run("an unknown health is a bad request", func(t *testing.T) {
+		status, _ := getRuleStatuses(t, "health=broken")
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -18,6 +19,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/common/model"
 )
 
@@ -26,7 +28,10 @@ type RulerSrv struct {
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
-	log             log.Logger
+	historyStore    store.StateHistoryStore
+	// provisioningStore is used to reject changes to provisioned rule groups.
+	provisioningStore store.ProvisioningStore
+	log               log.Logger
 }
 
 func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) response.Response {
@@ -36,6 +41,10 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	if errResp := checkNamespaceNotProvisioned(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
@@ -55,6 +64,10 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := c.Params(":Groupname")
+	if errResp := checkRuleGroupNotProvisioned(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid, ruleGroup); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -212,6 +225,66 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 	return response.JSON(http.StatusAccepted, result)
 }
 
+func (srv RulerSrv) RouteGetRuleStateHistory(c *models.ReqContext) response.Response {
+	q := ngmodels.ListAlertStateHistoryQuery{
+		RuleOrgID: c.SignedInUser.OrgId,
+		RuleUID:   c.Query("ruleUID"),
+	}
+
+	for _, m := range c.QueryStrings("matcher") {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid matcher %q", m)
+		}
+		q.Matchers = append(q.Matchers, matcher)
+	}
+
+	var err error
+	if q.From, err = parseHistoryTime(c.Query("from")); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid from")
+	}
+	if q.To, err = parseHistoryTime(c.Query("to")); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid to")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return ErrResp(http.StatusBadRequest, errors.New("to must not be before from"), "")
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be a positive integer: %s", limit), "")
+		}
+	}
+
+	if err := srv.historyStore.ListAlertStateHistory(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert state history")
+	}
+
+	result := apimodels.StateHistoryResponse{History: make([]apimodels.StateHistoryEntry, 0, len(q.Result))}
+	for _, e := range q.Result {
+		result.History = append(result.History, apimodels.StateHistoryEntry{
+			RuleUID:       e.RuleUID,
+			Labels:        e.Labels,
+			PreviousState: string(e.PreviousState),
+			State:         string(e.State),
+			Values:        e.Values,
+			EvaluatedAt:   e.EvaluatedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// parseHistoryTime parses a RFC 3339 timestamp or a number of seconds since
+// the epoch. The zero time is returned for an empty string.
+func parseHistoryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
 func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
 	namespaceTitle := c.Params(":Namespace")
 	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
@@ -243,6 +316,13 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		}
 	}
 
+	if errResp := checkRuleGroupNotProvisioned(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name); errResp != nil {
+		return errResp
+	}
+	if errResp := checkRulesNotProvisioned(srv.provisioningStore, srv.store, c.SignedInUser.OrgId, alertRuleUIDs); errResp != nil {
+		return errResp
+	}
+
 	numOfNewRules := len(ruleGroupConfig.Rules) - len(alertRuleUIDs)
 	if numOfNewRules > 0 {
 		// quotas are checked in advanced
@@ -295,8 +375,18 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			Record:          r.Record,
+			DependsOn:       r.DependsOn,
+			Evaluation:      r.Evaluation,
 		},
 	}
+	if r.IsPausedAt(time.Now()) {
+		gettableExtendedRuleNode.GrafanaManagedAlert.IsPaused = true
+		if !r.PausedUntil.IsZero() {
+			pausedUntil := r.PausedUntil
+			gettableExtendedRuleNode.GrafanaManagedAlert.PausedUntil = &pausedUntil
+		}
+	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         model.Duration(r.For),
 		Annotations: r.Annotations,
@@ -305,6 +395,60 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 	return gettableExtendedRuleNode
 }
 
+func (srv RulerSrv) RoutePostRulePause(c *models.ReqContext, body apimodels.PostableRulePause) response.Response {
+	cmd := store.SetAlertRulePausedCmd{OrgID: c.SignedInUser.OrgId, UID: c.Params(":RuleUID"), Paused: true}
+	switch {
+	case body.Until != nil && body.For != 0:
+		return ErrResp(http.StatusBadRequest, errors.New("until and for cannot be set together"), "")
+	case body.Until != nil:
+		if !body.Until.After(time.Now()) {
+			return ErrResp(http.StatusBadRequest, errors.New("until must be in the future"), "")
+		}
+		cmd.Until = *body.Until
+	case body.For < 0:
+		return ErrResp(http.StatusBadRequest, errors.New("for must be positive"), "")
+	case body.For > 0:
+		cmd.Until = time.Now().Add(time.Duration(body.For))
+	}
+	return srv.setRulePaused(c, cmd)
+}
+
+func (srv RulerSrv) RoutePostRuleResume(c *models.ReqContext) response.Response {
+	return srv.setRulePaused(c, store.SetAlertRulePausedCmd{OrgID: c.SignedInUser.OrgId, UID: c.Params(":RuleUID"), Paused: false})
+}
+
+// setRulePaused pauses or resumes an alert rule if the user can edit the rules of its namespace.
+func (srv RulerSrv) setRulePaused(c *models.ReqContext, cmd store.SetAlertRulePausedCmd) response.Response {
+	q := ngmodels.GetAlertRuleByUIDQuery{OrgID: cmd.OrgID, UID: cmd.UID}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+
+	namespaces, err := srv.store.GetNamespaces(cmd.OrgID, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	namespace, ok := namespaces[q.Result.NamespaceUID]
+	if !ok {
+		// the user cannot see the rule
+		return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+	}
+	if _, err := srv.store.GetNamespaceByTitle(namespace.Title, cmd.OrgID, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	if err := srv.store.SetAlertRulePaused(cmd); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to update alert rule")
+	}
+	if cmd.Paused {
+		return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule paused"})
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule resumed"})
+}
+
 func toNamespaceErrorResponse(err error) response.Response {
 	if errors.Is(err, ngmodels.ErrCannotEditNamespace) {
 		return ErrResp(http.StatusForbidden, err, err.Error())
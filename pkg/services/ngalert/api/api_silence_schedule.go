@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// maxSilenceScheduleNextWindows is the maximum number of next windows returned with a silence schedule.
+const maxSilenceScheduleNextWindows = 10
+
+func (srv AlertmanagerSrv) RouteGetSilenceSchedules(c *models.ReqContext) response.Response {
+	schedules, err := srv.silenceScheduleStore.ListSilenceSchedules(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence schedules")
+	}
+	result := make(apimodels.GettableSilenceSchedules, 0, len(schedules))
+	for _, s := range schedules {
+		result = append(result, toGettableSilenceSchedule(s, time.Now()))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetSilenceSchedule(c *models.ReqContext) response.Response {
+	s, err := srv.silenceScheduleStore.GetSilenceSchedule(c.OrgId, c.Params(":ScheduleUID"))
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceScheduleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence schedule")
+	}
+	return response.JSON(http.StatusOK, toGettableSilenceSchedule(s, time.Now()))
+}
+
+func (srv AlertmanagerSrv) RouteCreateSilenceSchedule(c *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	s := fromPostableSilenceSchedule(body)
+	s.OrgID = c.OrgId
+	s.CreatedBy = c.Login
+	return srv.saveSilenceSchedule(s, http.StatusCreated)
+}
+
+func (srv AlertmanagerSrv) RouteUpdateSilenceSchedule(c *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	uid := c.Params(":ScheduleUID")
+	if _, err := srv.silenceScheduleStore.GetSilenceSchedule(c.OrgId, uid); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceScheduleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get silence schedule")
+	}
+
+	s := fromPostableSilenceSchedule(body)
+	s.OrgID = c.OrgId
+	s.UID = uid
+	return srv.saveSilenceSchedule(s, http.StatusOK)
+}
+
+func (srv AlertmanagerSrv) saveSilenceSchedule(s *ngmodels.SilenceSchedule, status int) response.Response {
+	if err := s.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err := srv.silenceScheduleStore.SaveSilenceSchedule(s); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save silence schedule")
+	}
+	return response.JSON(status, toGettableSilenceSchedule(s, time.Now()))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteSilenceSchedule(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	uid := c.Params(":ScheduleUID")
+	if err := srv.silenceScheduleStore.DeleteSilenceSchedule(c.OrgId, uid); err != nil {
+		if errors.Is(err, ngmodels.ErrSilenceScheduleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete silence schedule")
+	}
+
+	// The silences of the schedule are expired right away rather than by the next synchronization.
+	if am, errResp := srv.AlertmanagerFor(c.OrgId); errResp == nil {
+		silences, err := am.ListSilences(nil)
+		if err != nil {
+			srv.log.Error("failed to list the silences of deleted silence schedule", "uid", uid, "err", err)
+		}
+		for _, s := range silences {
+			if s.CreatedBy == nil || *s.CreatedBy != notifier.SilenceScheduleCreatedBy(uid) || s.Status == nil || s.Status.State == nil || *s.Status.State == "expired" {
+				continue
+			}
+			if err := am.DeleteSilence(*s.ID); err != nil && !errors.Is(err, notifier.ErrSilenceNotFound) {
+				srv.log.Error("failed to expire silence of deleted silence schedule", "uid", uid, "id", *s.ID, "err", err)
+			}
+		}
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence schedule deleted"})
+}
+
+func fromPostableSilenceSchedule(body apimodels.PostableSilenceSchedule) *ngmodels.SilenceSchedule {
+	return &ngmodels.SilenceSchedule{
+		Comment:       body.Comment,
+		Matchers:      body.Matchers,
+		Cron:          body.Cron,
+		Duration:      time.Duration(body.Duration),
+		TimeIntervals: body.TimeIntervals,
+		Location:      body.Location,
+	}
+}
+
+func toGettableSilenceSchedule(s *ngmodels.SilenceSchedule, now time.Time) apimodels.GettableSilenceSchedule {
+	result := apimodels.GettableSilenceSchedule{
+		PostableSilenceSchedule: apimodels.PostableSilenceSchedule{
+			Comment:       s.Comment,
+			Matchers:      s.Matchers,
+			Cron:          s.Cron,
+			Duration:      model.Duration(s.Duration),
+			TimeIntervals: s.TimeIntervals,
+			Location:      s.Location,
+		},
+		UID:         s.UID,
+		CreatedBy:   s.CreatedBy,
+		Created:     time.Unix(s.Created, 0).UTC(),
+		Updated:     time.Unix(s.Updated, 0).UTC(),
+		NextWindows: make([]apimodels.SilenceScheduleWindow, 0),
+	}
+	windows, err := s.Windows(now, now.Add(7*24*time.Hour))
+	if err != nil {
+		return result
+	}
+	for i, w := range windows {
+		if i == maxSilenceScheduleNextWindows {
+			break
+		}
+		result.NextWindows = append(result.NextWindows, apimodels.SilenceScheduleWindow{StartsAt: w.Start, EndsAt: w.End})
+	}
+	return result
+}
@@ -1,18 +1,23 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util"
@@ -98,3 +103,129 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+const (
+	// maxBacktestEvaluations is the maximum number of evaluations done by a single backtest.
+	maxBacktestEvaluations = 100
+	// backtestTimeout is the maximum duration of a backtest, all of its evaluations included.
+	backtestTimeout = 30 * time.Second
+)
+
+// RouteBacktestConfig evaluates a rule at each interval between from and to, and feeds the results
+// to a state manager that only lives for the duration of the request, so that the For duration and
+// the NoData and Error states of the rule are applied like they would be by the scheduler.
+func (srv TestingApiSrv) RouteBacktestConfig(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	rule := cmd.Rule.GrafanaManagedAlert
+	cond := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     c.SignedInUser.OrgId,
+		Data:      rule.Data,
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	interval := time.Duration(cmd.Interval)
+	if evaluations := int64(cmd.To.Sub(cmd.From)/interval) + 1; evaluations > maxBacktestEvaluations {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("backtest would evaluate the rule %d times, the limit is %d", evaluations, maxBacktestEvaluations), "")
+	}
+
+	alertRule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           rule.Title,
+		Condition:       rule.Condition,
+		Data:            rule.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		UID:             rule.UID,
+		NoDataState:     ngmodels.NoDataState(rule.NoDataState),
+		ExecErrState:    ngmodels.ExecutionErrorState(rule.ExecErrState),
+	}
+	if cmd.Rule.ApiRuleNode != nil {
+		alertRule.For = time.Duration(cmd.Rule.ApiRuleNode.For)
+		alertRule.Labels = cmd.Rule.ApiRuleNode.Labels
+		alertRule.Annotations = cmd.Rule.ApiRuleNode.Annotations
+	}
+
+	ctx, cancel := context.WithTimeout(c.Req.Context(), backtestTimeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	timedOut := func(now time.Time) response.Response {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("backtest did not finish within %s, it stopped at %s", backtestTimeout, now), "backtest a shorter time range or with a longer interval")
+	}
+
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	manager := state.NewDryRunManager(srv.log)
+	transitions := newStateTransitions()
+	for now := cmd.From; !now.After(cmd.To); now = now.Add(interval) {
+		// Each evaluation only has the time left to the backtest.
+		cond.Timeout = time.Until(deadline)
+		if ctx.Err() != nil || cond.Timeout <= 0 {
+			return timedOut(now)
+		}
+		cond.LoadedDimensions = manager.GetLoadedDimensions(alertRule)
+		results, err := evaluator.ConditionEval(&cond, now, srv.DataService)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "Failed to evaluate conditions at %s", now)
+		}
+		if ctx.Err() != nil {
+			// The results of an evaluation cut short are errors.
+			return timedOut(now)
+		}
+		transitions.add(now, manager.ProcessEvalResults(alertRule, results))
+	}
+
+	return response.JSONStreaming(http.StatusOK, apimodels.BacktestResponse{
+		Instances: transitions.frames(),
+	})
+}
+
+// stateTransitions records when the state of each alert instance changes during a backtest.
+type stateTransitions struct {
+	order     []string
+	instances map[string]*instanceTransitions
+}
+
+type instanceTransitions struct {
+	labels data.Labels
+	times  []time.Time
+	states []string
+}
+
+func newStateTransitions() *stateTransitions {
+	return &stateTransitions{
+		instances: make(map[string]*instanceTransitions),
+	}
+}
+
+// add records the states of the alert instances after the evaluation at the given time,
+// for the instances that are new or whose state is different from the last one recorded.
+func (t *stateTransitions) add(evaluatedAt time.Time, states []*state.State) {
+	for _, s := range states {
+		it, ok := t.instances[s.CacheId]
+		if !ok {
+			it = &instanceTransitions{labels: s.Labels.Copy()}
+			t.instances[s.CacheId] = it
+			t.order = append(t.order, s.CacheId)
+		}
+		current := s.State.String()
+		if n := len(it.states); n > 0 && it.states[n-1] == current {
+			continue
+		}
+		it.times = append(it.times, evaluatedAt)
+		it.states = append(it.states, current)
+	}
+}
+
+// frames returns a data frame for each alert instance, in the order in which they first
+// appeared, with the time and the new state of each transition.
+func (t *stateTransitions) frames() []*data.Frame {
+	frames := make([]*data.Frame, 0, len(t.order))
+	for _, id := range t.order {
+		it := t.instances[id]
+		frames = append(frames, data.NewFrame(id,
+			data.NewField("Time", nil, it.times),
+			data.NewField("State", it.labels, it.states),
+		))
+	}
+	return frames
+}
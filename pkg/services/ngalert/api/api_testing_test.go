@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateTransitions(t *testing.T) {
+	alertRule := &ngmodels.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 60,
+		For:             time.Minute,
+	}
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	evalStates := []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal}
+
+	manager := state.NewDryRunManager(log.New("test_backtest"))
+	transitions := newStateTransitions()
+	for i, s := range evalStates {
+		evaluatedAt := start.Add(time.Duration(i) * time.Minute)
+		results := eval.Results{
+			{
+				Instance:    data.Labels{"host": "a"},
+				State:       s,
+				EvaluatedAt: evaluatedAt,
+			},
+		}
+		transitions.add(evaluatedAt, manager.ProcessEvalResults(alertRule, results))
+	}
+
+	frames := transitions.frames()
+	require.Len(t, frames, 1)
+
+	expected := data.NewFrame(frames[0].Name,
+		data.NewField("Time", nil, []time.Time{start, start.Add(2 * time.Minute), start.Add(3 * time.Minute)}),
+		data.NewField("State", data.Labels{
+			"host":                         "a",
+			"alertname":                    "test_title",
+			"__alert_rule_uid__":           "test_alert_rule_uid",
+			"__alert_rule_namespace_uid__": "test_namespace_uid",
+		}, []string{"Pending", "Alerting", "Normal"}),
+	)
+	require.Equal(t, expected, frames[0])
+}
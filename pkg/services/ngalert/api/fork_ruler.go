@@ -69,6 +69,21 @@ func (r *ForkedRuler) RouteGetNamespaceRulesConfig(ctx *models.ReqContext) respo
 	}
 }
 
+func (r *ForkedRuler) RouteGetRuleStateHistory(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RouteGetRuleStateHistory(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RouteGetRuleStateHistory(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
 func (r *ForkedRuler) RouteGetRulegGroupConfig(ctx *models.ReqContext) response.Response {
 	t, err := backendType(ctx, r.DatasourceCache)
 	if err != nil {
@@ -119,3 +134,33 @@ func (r *ForkedRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apim
 		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", backendType), "")
 	}
 }
+
+func (r *ForkedRuler) RoutePostRulePause(ctx *models.ReqContext, conf apimodels.PostableRulePause) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RoutePostRulePause(ctx, conf)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RoutePostRulePause(ctx, conf)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
+
+func (r *ForkedRuler) RoutePostRuleResume(ctx *models.ReqContext) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return r.GrafanaRuler.RoutePostRuleResume(ctx)
+	case apimodels.LoTexRulerBackend:
+		return r.LotexRuler.RoutePostRuleResume(ctx)
+	default:
+		return ErrResp(400, fmt.Errorf("unexpected backend type (%v)", t), "")
+	}
+}
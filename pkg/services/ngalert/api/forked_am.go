@@ -111,6 +111,15 @@ func (am *ForkedAMSvc) RouteGetSilence(ctx *models.ReqContext) response.Response
 	return s.RouteGetSilence(ctx)
 }
 
+func (am *ForkedAMSvc) RouteGetNotificationDeliveries(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetNotificationDeliveries(ctx)
+}
+
 func (am *ForkedAMSvc) RouteGetSilences(ctx *models.ReqContext) response.Response {
 	s, err := am.getService(ctx)
 	if err != nil {
@@ -155,3 +164,48 @@ func (am *ForkedAMSvc) RoutePostTestReceivers(ctx *models.ReqContext, body apimo
 
 	return s.RoutePostTestReceivers(ctx, body)
 }
+
+func (am *ForkedAMSvc) RouteGetSilenceSchedules(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetSilenceSchedules(ctx)
+}
+
+func (am *ForkedAMSvc) RouteGetSilenceSchedule(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteGetSilenceSchedule(ctx)
+}
+
+func (am *ForkedAMSvc) RouteCreateSilenceSchedule(ctx *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteCreateSilenceSchedule(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteUpdateSilenceSchedule(ctx *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteUpdateSilenceSchedule(ctx, body)
+}
+
+func (am *ForkedAMSvc) RouteDeleteSilenceSchedule(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RouteDeleteSilenceSchedule(ctx)
+}
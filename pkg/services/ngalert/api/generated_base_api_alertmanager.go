@@ -21,17 +21,23 @@ import (
 
 type AlertmanagerApiService interface {
 	RouteCreateSilence(*models.ReqContext, apimodels.PostableSilence) response.Response
+	RouteCreateSilenceSchedule(*models.ReqContext, apimodels.PostableSilenceSchedule) response.Response
 	RouteDeleteAlertingConfig(*models.ReqContext) response.Response
 	RouteDeleteSilence(*models.ReqContext) response.Response
+	RouteDeleteSilenceSchedule(*models.ReqContext) response.Response
 	RouteGetAMAlertGroups(*models.ReqContext) response.Response
 	RouteGetAMAlerts(*models.ReqContext) response.Response
 	RouteGetAMStatus(*models.ReqContext) response.Response
 	RouteGetAlertingConfig(*models.ReqContext) response.Response
+	RouteGetNotificationDeliveries(*models.ReqContext) response.Response
 	RouteGetSilence(*models.ReqContext) response.Response
+	RouteGetSilenceSchedule(*models.ReqContext) response.Response
+	RouteGetSilenceSchedules(*models.ReqContext) response.Response
 	RouteGetSilences(*models.ReqContext) response.Response
 	RoutePostAMAlerts(*models.ReqContext, apimodels.PostableAlerts) response.Response
 	RoutePostAlertingConfig(*models.ReqContext, apimodels.PostableUserConfig) response.Response
 	RoutePostTestReceivers(*models.ReqContext, apimodels.TestReceiversConfigParams) response.Response
+	RouteUpdateSilenceSchedule(*models.ReqContext, apimodels.PostableSilenceSchedule) response.Response
 }
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *metrics.Metrics) {
@@ -46,6 +52,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence-schedules"),
+			binding.Bind(apimodels.PostableSilenceSchedule{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/api/v2/silence-schedules",
+				srv.RouteCreateSilenceSchedule,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
 			metrics.Instrument(
@@ -64,6 +80,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}",
+				srv.RouteDeleteSilenceSchedule,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/alerts/groups"),
 			metrics.Instrument(
@@ -100,6 +125,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/notifications"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/notifications",
+				srv.RouteGetNotificationDeliveries,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
 			metrics.Instrument(
@@ -109,6 +143,24 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}",
+				srv.RouteGetSilenceSchedule,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence-schedules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/{Recipient}/api/v2/silence-schedules",
+				srv.RouteGetSilenceSchedules,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silences"),
 			metrics.Instrument(
@@ -148,5 +200,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}"),
+			binding.Bind(apimodels.PostableSilenceSchedule{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID}",
+				srv.RouteUpdateSilenceSchedule,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
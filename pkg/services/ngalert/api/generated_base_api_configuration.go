@@ -23,6 +23,7 @@ type ConfigurationApiService interface {
 	RouteDeleteNGalertConfig(*models.ReqContext) response.Response
 	RouteGetAlertmanagers(*models.ReqContext) response.Response
 	RouteGetNGalertConfig(*models.ReqContext) response.Response
+	RoutePostLegacyAlertImport(*models.ReqContext, apimodels.PostableLegacyAlertImport) response.Response
 	RoutePostNGalertConfig(*models.ReqContext, apimodels.PostableNGalertConfig) response.Response
 }
 
@@ -55,6 +56,16 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/legacy_alerts/import"),
+			binding.Bind(apimodels.PostableLegacyAlertImport{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/legacy_alerts/import",
+				srv.RoutePostLegacyAlertImport,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			binding.Bind(apimodels.PostableNGalertConfig{}),
@@ -23,9 +23,12 @@ type RulerApiService interface {
 	RouteDeleteNamespaceRulesConfig(*models.ReqContext) response.Response
 	RouteDeleteRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*models.ReqContext) response.Response
+	RouteGetRuleStateHistory(*models.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*models.ReqContext) response.Response
 	RouteGetRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameRulesConfig(*models.ReqContext, apimodels.PostableRuleGroupConfig) response.Response
+	RoutePostRulePause(*models.ReqContext, apimodels.PostableRulePause) response.Response
+	RoutePostRuleResume(*models.ReqContext) response.Response
 }
 
 func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metrics) {
@@ -57,6 +60,15 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/{Recipient}/api/v1/history",
+				srv.RouteGetRuleStateHistory,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
 			metrics.Instrument(
@@ -85,5 +97,24 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/pause"),
+			binding.Bind(apimodels.PostableRulePause{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/pause",
+				srv.RoutePostRulePause,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/resume"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/resume",
+				srv.RoutePostRuleResume,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -20,6 +20,7 @@ import (
 )
 
 type TestingApiService interface {
+	RouteBacktestConfig(*models.ReqContext, apimodels.BacktestConfig) response.Response
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
 	RouteTestReceiverConfig(*models.ReqContext, apimodels.ExtendedReceiver) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
@@ -37,6 +38,16 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest"),
+			binding.Bind(apimodels.BacktestConfig{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest",
+				srv.RouteBacktestConfig,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/receiver/test/{Recipient}"),
 			binding.Bind(apimodels.ExtendedReceiver{}),
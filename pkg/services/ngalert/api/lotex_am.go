@@ -196,3 +196,33 @@ func (am *LotexAM) RoutePostAMAlerts(ctx *models.ReqContext, alerts apimodels.Po
 func (am *LotexAM) RoutePostTestReceivers(ctx *models.ReqContext, config apimodels.TestReceiversConfigParams) response.Response {
 	return NotImplementedResp
 }
+
+// RouteGetNotificationDeliveries is not supported by external Alertmanagers.
+func (am *LotexAM) RouteGetNotificationDeliveries(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+// RouteGetSilenceSchedules is not supported by external Alertmanagers.
+func (am *LotexAM) RouteGetSilenceSchedules(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+// RouteGetSilenceSchedule is not supported by external Alertmanagers.
+func (am *LotexAM) RouteGetSilenceSchedule(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+// RouteCreateSilenceSchedule is not supported by external Alertmanagers.
+func (am *LotexAM) RouteCreateSilenceSchedule(ctx *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	return NotImplementedResp
+}
+
+// RouteUpdateSilenceSchedule is not supported by external Alertmanagers.
+func (am *LotexAM) RouteUpdateSilenceSchedule(ctx *models.ReqContext, body apimodels.PostableSilenceSchedule) response.Response {
+	return NotImplementedResp
+}
+
+// RouteDeleteSilenceSchedule is not supported by external Alertmanagers.
+func (am *LotexAM) RouteDeleteSilenceSchedule(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
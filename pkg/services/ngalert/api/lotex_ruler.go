@@ -94,6 +94,21 @@ func (r *LotexRuler) RouteGetNamespaceRulesConfig(ctx *models.ReqContext) respon
 	)
 }
 
+// RouteGetRuleStateHistory is not supported by Cortex and Loki rulers.
+func (r *LotexRuler) RouteGetRuleStateHistory(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
+// RoutePostRulePause is not supported by Cortex and Loki rulers.
+func (r *LotexRuler) RoutePostRulePause(ctx *models.ReqContext, conf apimodels.PostableRulePause) response.Response {
+	return NotImplementedResp
+}
+
+// RoutePostRuleResume is not supported by Cortex and Loki rulers.
+func (r *LotexRuler) RoutePostRuleResume(ctx *models.ReqContext) response.Response {
+	return NotImplementedResp
+}
+
 func (r *LotexRuler) RouteGetRulegGroupConfig(ctx *models.ReqContext) response.Response {
 	legacyRulerPrefix, err := r.getPrefix(ctx)
 	if err != nil {
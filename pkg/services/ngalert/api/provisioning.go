@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// errProvisioned is returned when an alerting object that is provisioned from a file is changed.
+var errProvisioned = errors.New("the object is provisioned from a file and cannot be changed through the API")
+
+// checkRuleGroupNotProvisioned returns an error response if the rule group is provisioned.
+func checkRuleGroupNotProvisioned(ps store.ProvisioningStore, orgID int64, namespaceUID, ruleGroup string) response.Response {
+	if ps == nil {
+		return nil
+	}
+	p, err := ps.GetAlertProvisioning(orgID, ngmodels.ProvisionedRuleGroup, ngmodels.RuleGroupProvisioningKey(namespaceUID, ruleGroup))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to check whether the rule group is provisioned")
+	}
+	if p != nil {
+		return ErrResp(http.StatusBadRequest, errProvisioned, "rule group %q is provisioned by %s", ruleGroup, p.Provisioner)
+	}
+	return nil
+}
+
+// checkNamespaceNotProvisioned returns an error response if any rule group of the namespace is provisioned.
+func checkNamespaceNotProvisioned(ps store.ProvisioningStore, orgID int64, namespaceUID string) response.Response {
+	if ps == nil {
+		return nil
+	}
+	provisioned, err := ps.ListAlertProvisioning(orgID, ngmodels.ProvisionedRuleGroup)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to check whether the namespace is provisioned")
+	}
+	prefix := ngmodels.RuleGroupProvisioningKey(namespaceUID, "")
+	for _, p := range provisioned {
+		if strings.HasPrefix(p.ObjectKey, prefix) {
+			return ErrResp(http.StatusBadRequest, errProvisioned, "the namespace contains rule groups provisioned by %s", p.Provisioner)
+		}
+	}
+	return nil
+}
+
+// checkRulesNotProvisioned returns an error response if any of the rules belongs to a provisioned rule group.
+func checkRulesNotProvisioned(ps store.ProvisioningStore, rs store.RuleStore, orgID int64, ruleUIDs map[string]struct{}) response.Response {
+	if ps == nil || len(ruleUIDs) == 0 {
+		return nil
+	}
+	provisioned, err := ps.ListAlertProvisioning(orgID, ngmodels.ProvisionedRuleGroup)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to check whether the rules are provisioned")
+	}
+	if len(provisioned) == 0 {
+		return nil
+	}
+	groups := make(map[string]*ngmodels.AlertProvisioning, len(provisioned))
+	for _, p := range provisioned {
+		groups[p.ObjectKey] = p
+	}
+
+	for uid := range ruleUIDs {
+		q := ngmodels.GetAlertRuleByUIDQuery{UID: uid, OrgID: orgID}
+		if err := rs.GetAlertRuleByUID(&q); err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				continue
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to check whether the rules are provisioned")
+		}
+		if p, ok := groups[ngmodels.RuleGroupProvisioningKey(q.Result.NamespaceUID, q.Result.RuleGroup)]; ok {
+			return ErrResp(http.StatusBadRequest, errProvisioned, "alert rule %q is provisioned by %s", q.Result.Title, p.Provisioner)
+		}
+	}
+	return nil
+}
+
+// checkAlertmanagerConfigNotProvisioned returns an error response if the Alertmanager configuration
+// of the organisation is provisioned.
+func checkAlertmanagerConfigNotProvisioned(ps store.ProvisioningStore, orgID int64) response.Response {
+	if ps == nil {
+		return nil
+	}
+	p, err := ps.GetAlertProvisioning(orgID, ngmodels.ProvisionedAlertmanagerConfig, "")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to check whether the Alertmanager configuration is provisioned")
+	}
+	if p != nil {
+		return ErrResp(http.StatusBadRequest, errProvisioned, "the Alertmanager configuration is provisioned by %s", p.Provisioner)
+	}
+	return nil
+}
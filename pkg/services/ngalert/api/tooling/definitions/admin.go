@@ -35,6 +35,22 @@ import v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 //       201: Ack
 //       400: ValidationError
 
+// swagger:route POST /api/v1/ngalert/legacy_alerts/import configuration RoutePostLegacyAlertImport
+//
+// Imports the legacy dashboard alerts of the user's organization into unified alerting, with the same
+// translation as the migration to unified alerting. The alerts already imported are skipped.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: LegacyAlertImportReport
+//       404: Failure
+//       500: Failure
+
 // swagger:route DELETE /api/v1/ngalert/admin_config configuration RouteDeleteNGalertConfig
 //
 // Deletes the NGalert configuration of the user's organization.
@@ -67,3 +83,44 @@ type GettableAlertmanagers struct {
 	Status string                 `json:"status"`
 	Data   v1.AlertManagersResult `json:"data"`
 }
+
+// swagger:parameters RoutePostLegacyAlertImport
+type LegacyAlertImport struct {
+	// in:body
+	Body PostableLegacyAlertImport
+}
+
+// swagger:model
+type PostableLegacyAlertImport struct {
+	// The UIDs of the dashboards whose alerts are imported.
+	DashboardUIDs []string `json:"dashboardUids"`
+	// The UIDs of the folders whose dashboards' alerts are imported.
+	// The alerts of every dashboard are imported if there are neither dashboards nor folders.
+	FolderUIDs []string `json:"folderUids"`
+	// DryRun reports what the import would change, without changing anything.
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:model
+type LegacyAlertImportReport struct {
+	DryRun               bool                    `json:"dryRun"`
+	Rules                []LegacyAlertImportRule `json:"rules"`
+	CreatedFolders       []string                `json:"createdFolders"`
+	CreatedContactPoints []string                `json:"createdContactPoints"`
+}
+
+// LegacyAlertImportRule is the outcome of the import of a legacy dashboard alert.
+type LegacyAlertImportRule struct {
+	AlertID      int64  `json:"alertId"`
+	AlertName    string `json:"alertName"`
+	DashboardUID string `json:"dashboardUid"`
+	PanelID      int64  `json:"panelId"`
+	// Status is created, skipped if the alert is already imported, or failed.
+	Status    string `json:"status"`
+	RuleUID   string `json:"ruleUid,omitempty"`
+	FolderUID string `json:"folderUid,omitempty"`
+	Receiver  string `json:"receiver,omitempty"`
+	// Paused is true if the legacy alert is paused, the rule is then imported paused.
+	Paused bool   `json:"paused,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
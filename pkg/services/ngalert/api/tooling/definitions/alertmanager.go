@@ -11,9 +11,12 @@ import (
 	"github.com/pkg/errors"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -117,6 +120,54 @@ import (
 //       200: Ack
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/notifications alertmanager RouteGetNotificationDeliveries
+//
+// list the deliveries of notifications by the integrations of contact points, most recent first
+//
+//     Responses:
+//       200: NotificationDeliveriesResponse
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/silence-schedules alertmanager RouteGetSilenceSchedules
+//
+// list the recurring silences
+//
+//     Responses:
+//       200: GettableSilenceSchedules
+
+// swagger:route POST /api/alertmanager/{Recipient}/api/v2/silence-schedules alertmanager RouteCreateSilenceSchedule
+//
+// create a recurring silence
+//
+//     Responses:
+//       201: GettableSilenceSchedule
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID} alertmanager RouteGetSilenceSchedule
+//
+// get a recurring silence
+//
+//     Responses:
+//       200: GettableSilenceSchedule
+//       404: NotFound
+
+// swagger:route PUT /api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID} alertmanager RouteUpdateSilenceSchedule
+//
+// update a recurring silence, its silences that are no longer planned are expired
+//
+//     Responses:
+//       200: GettableSilenceSchedule
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /api/alertmanager/{Recipient}/api/v2/silence-schedule/{ScheduleUID} alertmanager RouteDeleteSilenceSchedule
+//
+// delete a recurring silence, its silences are expired
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+
 // swagger:model
 type TestReceiversConfig struct {
 	Receivers []*PostableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
@@ -169,6 +220,107 @@ type GetSilencesParams struct {
 	Filter []string `json:"filter"`
 }
 
+// swagger:parameters RouteGetNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// only return the deliveries of the contact point with this name
+	// in: query
+	Receiver string `json:"receiver"`
+	// only return the deliveries of this type of integration, e.g. slack
+	// in: query
+	Integration string `json:"integration"`
+	// only return the deliveries with this status: success, failure, dropped or digested
+	// in: query
+	Status string `json:"status"`
+	// only return the deliveries of notifications that contain the alert with this fingerprint
+	// in: query
+	Fingerprint string `json:"fingerprint"`
+	// start of the time range, as a RFC 3339 timestamp or unix seconds
+	// in: query
+	From string `json:"from"`
+	// end of the time range, as a RFC 3339 timestamp or unix seconds
+	// in: query
+	To string `json:"to"`
+	// maximum number of deliveries to return
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type NotificationDeliveriesResponse struct {
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationDelivery is an attempt of an integration of a contact point to deliver a notification.
+type NotificationDelivery struct {
+	Receiver        string    `json:"receiver"`
+	Integration     string    `json:"integration"`
+	IntegrationName string    `json:"integrationName"`
+	IntegrationUID  string    `json:"integrationUid"`
+	Fingerprints    []string  `json:"fingerprints"`
+	Status          string    `json:"status"`
+	StatusCode      int       `json:"statusCode,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	DurationMs      int64     `json:"durationMs"`
+	SentAt          time.Time `json:"sentAt"`
+}
+
+// swagger:parameters RouteCreateSilenceSchedule
+type CreateSilenceScheduleParams struct {
+	// in:body
+	Body PostableSilenceSchedule
+}
+
+// swagger:parameters RouteUpdateSilenceSchedule
+type UpdateSilenceScheduleParams struct {
+	// in:path
+	ScheduleUID string
+	// in:body
+	Body PostableSilenceSchedule
+}
+
+// swagger:parameters RouteGetSilenceSchedule RouteDeleteSilenceSchedule
+type GetDeleteSilenceScheduleParams struct {
+	// in:path
+	ScheduleUID string
+}
+
+// PostableSilenceSchedule is a recurring silence. Its windows are either the occurrences of the
+// cron expression for the duration, or the times that are in one of the time intervals.
+// swagger:model
+type PostableSilenceSchedule struct {
+	Comment string `json:"comment,omitempty"`
+	// matchers of the silences, e.g. env="prod"
+	// required: true
+	Matchers []string `json:"matchers"`
+	// cron expression with 5 fields of the starts of the windows
+	Cron string `json:"cron,omitempty"`
+	// duration of the windows of the cron expression
+	Duration model.Duration `json:"duration,omitempty"`
+	// time intervals with the syntax of mute time intervals, used instead of the cron expression
+	TimeIntervals []timeinterval.TimeInterval `json:"timeIntervals,omitempty"`
+	// time zone of the schedule, e.g. Europe/Paris, UTC if empty
+	Location string `json:"location,omitempty"`
+}
+
+// swagger:model
+type GettableSilenceSchedule struct {
+	PostableSilenceSchedule
+	UID       string    `json:"uid"`
+	CreatedBy string    `json:"createdBy"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	// the next windows of the schedule, those that start in the next 24 hours are materialized into silences
+	NextWindows []SilenceScheduleWindow `json:"nextWindows"`
+}
+
+type SilenceScheduleWindow struct {
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+// swagger:model
+type GettableSilenceSchedules []GettableSilenceSchedule
+
 // swagger:model
 type GettableStatus struct {
 	// cluster
@@ -718,21 +870,23 @@ func AllReceivers(route *config.Route) (res []string) {
 }
 
 type GettableGrafanaReceiver struct {
-	UID                   string           `json:"uid"`
-	Name                  string           `json:"name"`
-	Type                  string           `json:"type"`
-	DisableResolveMessage bool             `json:"disableResolveMessage"`
-	Settings              *simplejson.Json `json:"settings"`
-	SecureFields          map[string]bool  `json:"secureFields"`
+	UID                   string                        `json:"uid"`
+	Name                  string                        `json:"name"`
+	Type                  string                        `json:"type"`
+	DisableResolveMessage bool                          `json:"disableResolveMessage"`
+	RateLimit             *models.NotificationRateLimit `json:"rateLimit,omitempty"`
+	Settings              *simplejson.Json              `json:"settings"`
+	SecureFields          map[string]bool               `json:"secureFields"`
 }
 
 type PostableGrafanaReceiver struct {
-	UID                   string            `json:"uid"`
-	Name                  string            `json:"name"`
-	Type                  string            `json:"type"`
-	DisableResolveMessage bool              `json:"disableResolveMessage"`
-	Settings              *simplejson.Json  `json:"settings"`
-	SecureSettings        map[string]string `json:"secureSettings"`
+	UID                   string                        `json:"uid"`
+	Name                  string                        `json:"name"`
+	Type                  string                        `json:"type"`
+	DisableResolveMessage bool                          `json:"disableResolveMessage"`
+	RateLimit             *models.NotificationRateLimit `json:"rateLimit,omitempty"`
+	Settings              *simplejson.Json              `json:"settings"`
+	SecureSettings        map[string]string             `json:"secureSettings"`
 }
 
 func (r *PostableGrafanaReceiver) GetDecryptedSecret(key string) (string, error) {
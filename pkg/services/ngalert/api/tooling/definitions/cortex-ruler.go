@@ -64,6 +64,57 @@ import (
 //     Responses:
 //       202: Ack
 
+// swagger:route Get /api/ruler/{Recipient}/api/v1/history ruler RouteGetRuleStateHistory
+//
+// List the changes of state of alert instances, most recent first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryResponse
+
+// swagger:route POST /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/pause ruler RoutePostRulePause
+//
+// Pause the evaluation of an alert rule, optionally until a time
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       404: NotFound
+
+// swagger:route POST /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/resume ruler RoutePostRuleResume
+//
+// Resume the evaluation of a paused alert rule
+//
+//     Responses:
+//       202: Ack
+//       404: NotFound
+
+// swagger:parameters RoutePostRulePause
+type RulePauseParams struct {
+	// in:path
+	RuleUID string
+	// in:body
+	Body PostableRulePause
+}
+
+// swagger:parameters RoutePostRuleResume
+type RuleResumeParams struct {
+	// in:path
+	RuleUID string
+}
+
+// PostableRulePause sets the end of the pause of an alert rule, with either a time or a duration.
+// The rule is paused until it is resumed if neither is set.
+// swagger:model
+type PostableRulePause struct {
+	Until *time.Time     `json:"until,omitempty"`
+	For   model.Duration `json:"for,omitempty"`
+}
+
 // swagger:parameters RoutePostNameRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -86,6 +137,42 @@ type PathRouleGroupConfig struct {
 	Groupname string
 }
 
+// swagger:parameters RouteGetRuleStateHistory
+type StateHistoryParams struct {
+	// only return the changes of state of the alert rule with this UID
+	// in: query
+	RuleUID string `json:"ruleUID"`
+	// only return the changes of state of alert instances whose labels
+	// match the matcher, e.g. host="a" or env=~"prod.*"
+	// in: query
+	// collection format: multi
+	Matcher []string `json:"matcher"`
+	// start of the time range, as a RFC 3339 timestamp or unix seconds
+	// in: query
+	From string `json:"from"`
+	// end of the time range, as a RFC 3339 timestamp or unix seconds
+	// in: query
+	To string `json:"to"`
+	// maximum number of changes of state to return
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryResponse struct {
+	History []StateHistoryEntry `json:"history"`
+}
+
+// StateHistoryEntry is a change of the state of an alert instance.
+type StateHistoryEntry struct {
+	RuleUID       string              `json:"ruleUID"`
+	Labels        map[string]string   `json:"labels"`
+	PreviousState string              `json:"previousState"`
+	State         string              `json:"state"`
+	Values        map[string]*float64 `json:"values,omitempty"`
+	EvaluatedAt   time.Time           `json:"evaluatedAt"`
+}
+
 // swagger:model
 type RuleGroupConfigResponse struct {
 	GettableRuleGroupConfig
@@ -299,22 +386,33 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record is the name of the metric a recording rule writes the result of its condition to.
+	Record string `json:"record,omitempty" yaml:"record,omitempty"`
+	// DependsOn are the rules whose firing instances suppress the instances of this rule.
+	DependsOn []models.RuleDependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// Evaluation overrides the settings of the scheduler for the evaluation of the rule.
+	Evaluation *models.EvaluationSettings `json:"evaluation,omitempty" yaml:"evaluation,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ID              int64                      `json:"id" yaml:"id"`
+	OrgID           int64                      `json:"orgId" yaml:"orgId"`
+	Title           string                     `json:"title" yaml:"title"`
+	Condition       string                     `json:"condition" yaml:"condition"`
+	Data            []models.AlertQuery        `json:"data" yaml:"data"`
+	Updated         time.Time                  `json:"updated" yaml:"updated"`
+	IntervalSeconds int64                      `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version         int64                      `json:"version" yaml:"version"`
+	UID             string                     `json:"uid" yaml:"uid"`
+	NamespaceUID    string                     `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID     int64                      `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup       string                     `json:"rule_group" yaml:"rule_group"`
+	NoDataState     NoDataState                `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState    ExecutionErrorState        `json:"exec_err_state" yaml:"exec_err_state"`
+	Record          string                     `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn       []models.RuleDependency    `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Evaluation      *models.EvaluationSettings `json:"evaluation,omitempty" yaml:"evaluation,omitempty"`
+	IsPaused        bool                       `json:"is_paused,omitempty" yaml:"is_paused,omitempty"`
+	PausedUntil     *time.Time                 `json:"paused_until,omitempty" yaml:"paused_until,omitempty"`
 }
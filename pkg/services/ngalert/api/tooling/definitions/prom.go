@@ -13,6 +13,16 @@ import (
 //     Responses:
 //       200: RuleResponse
 
+// swagger:parameters RouteGetRuleStatuses
+type RuleStatusesParams struct {
+	// Only return the rules with one of these healths: ok, error or nodata.
+	// The health is kept in memory by the Grafana instance that evaluates the rules, so in a
+	// high availability or sharded setup it depends on the instance that answers the request.
+	// in: query
+	// required: false
+	Health []string `json:"health"`
+}
+
 // swagger:route GET /api/prometheus/{Recipient}/api/v1/alerts prometheus RouteGetAlertStatuses
 //
 // gets the current alerts
@@ -109,6 +119,11 @@ type Rule struct {
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
 	EvaluationTime float64     `json:"evaluationTime"`
+	// ConsecutiveFailures is the number of evaluations of the rule that failed in a row.
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+	// Paused is set while the evaluation of the rule is paused, until PausedUntil if it is set.
+	Paused      bool       `json:"paused,omitempty"`
+	PausedUntil *time.Time `json:"pausedUntil,omitempty"`
 }
 
 // Alert has info for an alert.
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -53,6 +55,19 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestConfig
+//
+// Replay the evaluations of a rule over a time range in the past
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResponse
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -85,6 +100,55 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestConfig
+type BacktestRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// From is the time of the first evaluation.
+	From time.Time `json:"from"`
+	// To is the time after which no more evaluations are done.
+	To time.Time `json:"to"`
+	// Interval is the time between two evaluations, like the interval of a rule group.
+	Interval model.Duration `json:"interval"`
+	// Rule is the Grafana managed rule to evaluate.
+	Rule PostableExtendedRuleNode `json:"rule"`
+}
+
+func (c *BacktestConfig) UnmarshalJSON(b []byte) error {
+	type plain BacktestConfig
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+
+	return c.validate()
+}
+
+func (c *BacktestConfig) validate() error {
+	if c.Rule.GrafanaManagedAlert == nil {
+		return fmt.Errorf("only Grafana managed rules can be backtested")
+	}
+
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+
+	if !c.From.Before(c.To) {
+		return fmt.Errorf("from must be before to")
+	}
+
+	return nil
+}
+
+// swagger:model
+type BacktestResponse struct {
+	// Instances holds a data frame for each alert instance, with a row for each change of its state.
+	Instances []*data.Frame `json:"instances"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
@@ -3,8 +3,10 @@ package definitions
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,3 +69,57 @@ func TestRulePayloadMarshaling(t *testing.T) {
 		})
 	}
 }
+
+func TestBacktestConfigMarshaling(t *testing.T) {
+	from := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	rule := PostableExtendedRuleNode{
+		GrafanaManagedAlert: &PostableGrafanaRule{
+			Title:     "backtest",
+			Condition: "A",
+		},
+	}
+
+	for _, tc := range []struct {
+		desc  string
+		input BacktestConfig
+		err   bool
+	}{
+		{
+			desc:  "success",
+			input: BacktestConfig{From: from, To: to, Interval: model.Duration(time.Minute), Rule: rule},
+		},
+		{
+			desc: "failure lotex rule",
+			input: BacktestConfig{From: from, To: to, Interval: model.Duration(time.Minute), Rule: PostableExtendedRuleNode{
+				ApiRuleNode: &ApiRuleNode{Expr: "up == 0"},
+			}},
+			err: true,
+		},
+		{
+			desc:  "failure missing interval",
+			input: BacktestConfig{From: from, To: to, Rule: rule},
+			err:   true,
+		},
+		{
+			desc:  "failure from after to",
+			input: BacktestConfig{From: to, To: from, Interval: model.Duration(time.Minute), Rule: rule},
+			err:   true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			encoded, err := json.Marshal(tc.input)
+			require.Nil(t, err)
+
+			var out BacktestConfig
+			err = json.Unmarshal(encoded, &out)
+
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.input, out)
+			}
+		})
+	}
+}
@@ -13,8 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
-	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -103,10 +103,15 @@ const (
 	// Error is the eval state for an alert rule condition
 	// that evaluated to Error.
 	Error
+
+	// Suppressed is the state for an alert instance condition
+	// that evaluated to true (Alerting) while a firing instance
+	// of a rule the alert rule depends on suppresses it.
+	Suppressed
 )
 
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}[s]
+	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error", "Suppressed"}[s]
 }
 
 // AlertExecCtx is the context provided for executing an alert condition.
@@ -115,6 +120,9 @@ type AlertExecCtx struct {
 	ExpressionsEnabled bool
 	Log                log.Logger
 
+	// LoadedDimensions are the labels of the currently firing instances of the rule.
+	LoadedDimensions []data.Labels
+
 	Ctx context.Context
 }
 
@@ -134,6 +142,12 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model: %w", err)
 		}
+		if isExpr, _ := q.IsExpression(); isExpr && len(ctx.LoadedDimensions) > 0 {
+			model, err = expr.SetLoadedDimensions(model, ctx.LoadedDimensions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set loaded dimensions on query model: %w", err)
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from the model: %w", err)
@@ -166,7 +180,7 @@ type NumberValueCapture struct {
 	Value  *float64
 }
 
-func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, dataService *tsdb.Service) ExecutionResults {
+func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, dataService plugins.DataRequestHandler) ExecutionResults {
 	result := ExecutionResults{}
 
 	execResp, err := executeQueriesAndExpressions(ctx, c.Data, now, dataService)
@@ -233,7 +247,7 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	return result
 }
 
-func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (resp *backend.QueryDataResponse, err error) {
+func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, dataService plugins.DataRequestHandler) (resp *backend.QueryDataResponse, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			ctx.Log.Error("alert rule panic", "error", e, "stack", string(debug.Stack()))
@@ -431,12 +445,20 @@ func (evalResults Results) AsDataFrame() data.Frame {
 	return *frame
 }
 
+// evaluationTimeout returns the timeout of the evaluation of the condition.
+func evaluationTimeout(condition *models.Condition) time.Duration {
+	if condition.Timeout > 0 {
+		return condition.Timeout
+	}
+	return alertingEvaluationTimeout
+}
+
 // ConditionEval executes conditions and evaluates the result.
-func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService plugins.DataRequestHandler) (Results, error) {
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), evaluationTimeout(condition))
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, LoadedDimensions: condition.LoadedDimensions}
 
 	execResult := executeCondition(alertExecCtx, condition, now, dataService)
 
@@ -445,7 +467,7 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 }
 
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
-func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
+func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService plugins.DataRequestHandler) (*backend.QueryDataResponse, error) {
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
 	defer cancelFn()
 
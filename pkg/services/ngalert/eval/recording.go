@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RecordingEval executes the condition of a recording rule and returns the numbers it
+// evaluates to. Unlike ConditionEval, the numbers are not turned into alert states.
+func (e *Evaluator) RecordingEval(condition *models.Condition, now time.Time, dataService plugins.DataRequestHandler) ([]NumberValueCapture, error) {
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), evaluationTimeout(condition))
+	defer cancelFn()
+
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+
+	execResp, err := executeQueriesAndExpressions(alertExecCtx, condition.Data, now, dataService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute conditions: %w", err)
+	}
+
+	res, ok := execResp.Responses[condition.Condition]
+	if !ok {
+		return nil, fmt.Errorf("no result for the condition %s", condition.Condition)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return extractNumbers(condition.Condition, res.Frames)
+}
+
+// extractNumbers returns the number of each frame of the condition. Each frame must
+// have a single nullable float64 field with at most one value, empty frames are skipped.
+func extractNumbers(refID string, frames data.Frames) ([]NumberValueCapture, error) {
+	numbers := make([]NumberValueCapture, 0, len(frames))
+	seen := make(map[string]struct{}, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 || frame.Fields[0].Len() == 0 {
+			continue
+		}
+		if len(frame.Fields) != 1 || frame.Fields[0].Type() != data.FieldTypeNullableFloat64 || frame.Fields[0].Len() != 1 {
+			return nil, &invalidEvalResultFormatError{refID: refID, reason: "recording rules require the condition to be a set of numbers"}
+		}
+
+		labels := frame.Fields[0].Labels
+		key := labels.String()
+		if _, ok := seen[key]; ok {
+			return nil, &invalidEvalResultFormatError{refID: refID, reason: fmt.Sprintf("frame cannot uniquely be identified by its labels: has duplicate results with labels %v", key)}
+		}
+		seen[key] = struct{}{}
+
+		numbers = append(numbers, NumberValueCapture{
+			Var:    refID,
+			Labels: labels.Copy(),
+			Value:  frame.At(0, 0).(*float64),
+		})
+	}
+	return numbers, nil
+}
@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestExtractNumbers(t *testing.T) {
+	cases := []struct {
+		desc          string
+		frames        data.Frames
+		expectNumbers []NumberValueCapture
+		expectError   bool
+	}{
+		{
+			desc: "numbers keep their labels and values",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", data.Labels{"host": "a"}, []*float64{ptr.Float64(1)})),
+				data.NewFrame("", data.NewField("", data.Labels{"host": "b"}, []*float64{nil})),
+			},
+			expectNumbers: []NumberValueCapture{
+				{Var: "B", Labels: data.Labels{"host": "a"}, Value: ptr.Float64(1)},
+				{Var: "B", Labels: data.Labels{"host": "b"}, Value: nil},
+			},
+		},
+		{
+			desc: "empty frames are skipped",
+			frames: data.Frames{
+				data.NewFrame(""),
+				data.NewFrame("", data.NewField("", nil, []*float64{})),
+			},
+			expectNumbers: []NumberValueCapture{},
+		},
+		{
+			desc: "series are not supported",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", nil, []*float64{ptr.Float64(1), ptr.Float64(2)})),
+			},
+			expectError: true,
+		},
+		{
+			desc: "duplicate labels are not supported",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", data.Labels{"host": "a"}, []*float64{ptr.Float64(1)})),
+				data.NewFrame("", data.NewField("", data.Labels{"host": "a"}, []*float64{ptr.Float64(2)})),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			numbers, err := extractNumbers("B", tc.frames)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectNumbers, numbers)
+		})
+	}
+}
@@ -43,7 +43,14 @@ type Metrics struct {
 	EvalTotal            *prometheus.CounterVec
 	EvalFailures         *prometheus.CounterVec
 	EvalDuration         *prometheus.SummaryVec
+	EvalCycleDuration    *prometheus.HistogramVec
+	EvalRetries          *prometheus.CounterVec
+	EvalSkipped          *prometheus.CounterVec
 	GroupRules           *prometheus.GaugeVec
+	// NotificationDeliveries and NotificationDeliveryDuration are only updated on the metrics
+	// of the service, as the registries of the Alertmanagers of the organisations are not exposed.
+	NotificationDeliveries       *prometheus.CounterVec
+	NotificationDeliveryDuration *prometheus.HistogramVec
 }
 
 func NewMetrics(r prometheus.Registerer) *Metrics {
@@ -104,6 +111,34 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			},
 			[]string{"user"},
 		),
+		EvalCycleDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_cycle_duration_seconds",
+				Help:      "The duration of the evaluation of a rule on a tick of the scheduler, including retries.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 120},
+			},
+			[]string{"user"},
+		),
+		EvalRetries: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_retries_total",
+				Help:      "The total number of retries of failed rule evaluations.",
+			},
+			[]string{"user"},
+		),
+		EvalSkipped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_skipped_total",
+				Help:      "The total number of rule evaluations skipped because the previous evaluation was still running.",
+			},
+			[]string{"user"},
+		),
 		// TODO: once rule groups support multiple rules, consider partitioning
 		// on rule group as well as tenant, similar to loki|cortex.
 		GroupRules: promauto.With(r).NewGaugeVec(
@@ -115,6 +150,25 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			},
 			[]string{"user"},
 		),
+		NotificationDeliveries: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_deliveries_total",
+				Help:      "The total number of attempts to deliver notifications, by contact point, integration and status.",
+			},
+			[]string{"user", "receiver", "integration", "status"},
+		),
+		NotificationDeliveryDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_delivery_duration_seconds",
+				Help:      "The duration of the attempts to deliver notifications, by contact point and integration.",
+				Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30},
+			},
+			[]string{"user", "receiver", "integration"},
+		),
 	}
 }
 
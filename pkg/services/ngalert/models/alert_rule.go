@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 )
 
 var (
@@ -68,6 +71,50 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is the name of the metric the result of the condition is written to.
+	// It is only set for recording rules, which do not create alerts.
+	Record string
+	// DependsOn are the rules whose firing instances suppress the instances of this rule.
+	DependsOn []RuleDependency
+	// Evaluation overrides the settings of the scheduler for the evaluation of this rule.
+	Evaluation *EvaluationSettings
+	// IsPaused stops the evaluation of the rule, until PausedUntil if it is set. It is not
+	// part of the definition of the rule, so pausing or resuming a rule does not change its version.
+	IsPaused    bool
+	PausedUntil time.Time
+}
+
+// RuleDependency declares that the instances of an alert rule are suppressed while
+// an instance of the rule RuleUID is firing. If Equal is set, only the firing instances
+// that have the same values for these labels suppress an instance, otherwise any
+// firing instance of the rule does.
+type RuleDependency struct {
+	RuleUID string   `json:"rule_uid" yaml:"rule_uid"`
+	Equal   []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// Suppresses returns true if the labels of a firing instance of the rule the
+// dependency is on suppress an instance with the given labels.
+func (d RuleDependency) Suppresses(parent, labels map[string]string) bool {
+	for _, name := range d.Equal {
+		if parent[name] != labels[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// EvaluationSettings overrides the settings of the scheduler for the evaluation of an alert rule.
+// Unset fields fall back to the settings of the [unified_alerting] section of the configuration.
+type EvaluationSettings struct {
+	// Timeout is the maximum duration of an evaluation.
+	Timeout model.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxAttempts is how many times an evaluation that fails is attempted.
+	MaxAttempts int64 `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	// RetryBackoff is the delay before the first retry, it is doubled on every retry.
+	RetryBackoff *model.Duration `json:"retry_backoff,omitempty" yaml:"retry_backoff,omitempty"`
+	// SkipIfRunning skips a tick while the evaluation of the previous tick is still running.
+	SkipIfRunning *bool `json:"skip_if_running,omitempty" yaml:"skip_if_running,omitempty"`
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -80,6 +127,17 @@ func (k AlertRuleKey) String() string {
 	return fmt.Sprintf("{orgID: %d, UID: %s}", k.OrgID, k.UID)
 }
 
+// IsRecordingRule returns true if the rule records the result of its condition
+// as a metric instead of evaluating it as an alert.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != ""
+}
+
+// IsPausedAt returns true if the evaluation of the rule is paused at the given time.
+func (alertRule *AlertRule) IsPausedAt(now time.Time) bool {
+	return alertRule.IsPaused && (alertRule.PausedUntil.IsZero() || now.Before(alertRule.PausedUntil))
+}
+
 // GetKey returns the alert definitions identifier
 func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
@@ -121,6 +179,13 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is the name of the metric the result of the condition is written to.
+	// It is only set for recording rules, which do not create alerts.
+	Record string
+	// DependsOn are the rules whose firing instances suppress the instances of this rule.
+	DependsOn []RuleDependency
+	// Evaluation overrides the settings of the scheduler for the evaluation of this rule.
+	Evaluation *EvaluationSettings
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -176,6 +241,14 @@ type Condition struct {
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
+
+	// LoadedDimensions are the labels of the currently firing instances of the rule.
+	// They are passed to threshold expressions so their recovery thresholds apply.
+	LoadedDimensions []data.Labels `json:"-"`
+
+	// Timeout is the maximum duration of the evaluation of the condition.
+	// The default evaluation timeout is used if it is not set.
+	Timeout time.Duration `json:"-"`
 }
 
 // IsValid checks the condition's validity.
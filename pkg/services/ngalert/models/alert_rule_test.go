@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleIsPausedAt(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		rule     AlertRule
+		expected bool
+	}{
+		{
+			desc:     "not paused",
+			rule:     AlertRule{},
+			expected: false,
+		},
+		{
+			desc:     "paused until resumed",
+			rule:     AlertRule{IsPaused: true},
+			expected: true,
+		},
+		{
+			desc:     "paused until a later time",
+			rule:     AlertRule{IsPaused: true, PausedUntil: now.Add(time.Minute)},
+			expected: true,
+		},
+		{
+			desc:     "pause expired",
+			rule:     AlertRule{IsPaused: true, PausedUntil: now},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.rule.IsPausedAt(now))
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"errors"
+
+	"github.com/prometheus/common/model"
+)
+
 const AlertConfigurationVersion = 1
 
 // AlertConfiguration represents a single version of the Alerting Engine Configuration.
@@ -26,3 +32,24 @@ type SaveAlertmanagerConfigurationCmd struct {
 	Default                   bool
 	OrgID                     int64
 }
+
+// NotificationRateLimit limits the number of notifications an integration of a contact point sends.
+type NotificationRateLimit struct {
+	// MaxNotifications is the number of notifications the integration sends per interval.
+	MaxNotifications int            `json:"maxNotifications" yaml:"maxNotifications"`
+	Interval         model.Duration `json:"interval" yaml:"interval"`
+	// Digest collects the notifications over the limit and sends them as one notification at
+	// the end of the interval. The notifications over the limit are dropped otherwise.
+	Digest bool `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+// Validate returns an error if the rate limit does not allow any notification.
+func (l *NotificationRateLimit) Validate() error {
+	if l.MaxNotifications <= 0 {
+		return errors.New("maxNotifications of the rate limit must be greater than 0")
+	}
+	if l.Interval <= 0 {
+		return errors.New("interval of the rate limit must be greater than 0")
+	}
+	return nil
+}
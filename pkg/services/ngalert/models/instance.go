@@ -31,6 +31,8 @@ const (
 	InstanceStateNoData InstanceStateType = "NoData"
 	// InstanceStateError is for a erroring alert.
 	InstanceStateError InstanceStateType = "Error"
+	// InstanceStateSuppressed is for an alert that is firing while an alert it depends on is firing.
+	InstanceStateSuppressed InstanceStateType = "Suppressed"
 )
 
 // IsValid checks that the value of InstanceStateType is a valid
@@ -40,7 +42,8 @@ func (i InstanceStateType) IsValid() bool {
 		i == InstanceStateNormal ||
 		i == InstanceStateNoData ||
 		i == InstanceStatePending ||
-		i == InstanceStateError
+		i == InstanceStateError ||
+		i == InstanceStateSuppressed
 }
 
 // SaveAlertInstanceCommand is the query for saving a new alert instance.
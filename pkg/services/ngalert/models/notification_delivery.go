@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationDeliveryStatus is the outcome of the delivery of a notification by an integration.
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliverySuccess NotificationDeliveryStatus = "success"
+	NotificationDeliveryFailure NotificationDeliveryStatus = "failure"
+	// NotificationDeliveryDropped is the status of a notification over the rate limit of the integration.
+	NotificationDeliveryDropped NotificationDeliveryStatus = "dropped"
+	// NotificationDeliveryDigested is the status of a notification over the rate limit of the
+	// integration whose alerts are sent in the next digest.
+	NotificationDeliveryDigested NotificationDeliveryStatus = "digested"
+)
+
+// NotificationDelivery represents an attempt of an integration of a contact point to deliver
+// a notification for a group of alerts.
+type NotificationDelivery struct {
+	ID              int64                      `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID           int64                      `xorm:"org_id" json:"orgId"`
+	Receiver        string                     `json:"receiver"`
+	Integration     string                     `json:"integration"`
+	IntegrationName string                     `json:"integrationName"`
+	IntegrationUID  string                     `xorm:"integration_uid" json:"integrationUid"`
+	Fingerprints    AlertFingerprints          `json:"fingerprints"`
+	Status          NotificationDeliveryStatus `json:"status"`
+	// StatusCode is the status code of the last HTTP response, 0 if the integration does not use HTTP.
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+	// DurationMs is the time it took to deliver the notification, in milliseconds.
+	DurationMs int64     `xorm:"duration_ms" json:"durationMs"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
+// AlertFingerprints are the fingerprints of the alerts of a notification.
+type AlertFingerprints []string
+
+// FromDB loads fingerprints stored in the database as json.
+// FromDB is part of the xorm Conversion interface.
+func (f *AlertFingerprints) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, f)
+}
+
+// ToDB serializes the fingerprints to json.
+// ToDB is part of the xorm Conversion interface.
+func (f *AlertFingerprints) ToDB() ([]byte, error) {
+	return json.Marshal(f)
+}
+
+// SaveNotificationDeliveryCommand is the command for recording the delivery of a notification.
+type SaveNotificationDeliveryCommand struct {
+	OrgID           int64
+	Receiver        string
+	Integration     string
+	IntegrationName string
+	IntegrationUID  string
+	Fingerprints    AlertFingerprints
+	Status          NotificationDeliveryStatus
+	StatusCode      int
+	Error           string
+	Duration        time.Duration
+	SentAt          time.Time
+}
+
+// ListNotificationDeliveriesQuery is the query for the deliveries of notifications of an
+// organisation, most recent first.
+type ListNotificationDeliveriesQuery struct {
+	OrgID       int64
+	Receiver    string
+	Integration string
+	Status      NotificationDeliveryStatus
+	// Fingerprint only keeps the deliveries of notifications that contain this alert.
+	Fingerprint string
+	From        time.Time
+	To          time.Time
+	// Limit is the maximum number of deliveries to return, 0 means no limit.
+	Limit int
+
+	Result []*NotificationDelivery
+}
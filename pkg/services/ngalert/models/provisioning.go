@@ -0,0 +1,43 @@
+package models
+
+import "strings"
+
+// ProvisionedObjectType is the type of an alerting object created from a provisioning file.
+type ProvisionedObjectType string
+
+const (
+	// ProvisionedRuleGroup is a rule group, its key is returned by RuleGroupProvisioningKey.
+	ProvisionedRuleGroup ProvisionedObjectType = "rule_group"
+	// ProvisionedAlertmanagerConfig is the Alertmanager configuration of an organisation,
+	// with its contact points and notification policies. Its key is empty.
+	ProvisionedAlertmanagerConfig ProvisionedObjectType = "alertmanager_config"
+)
+
+// AlertProvisioning records that an alerting object was created from a provisioning file.
+// Provisioned objects cannot be changed through the API.
+type AlertProvisioning struct {
+	ID         int64 `xorm:"pk autoincr 'id'"`
+	OrgID      int64 `xorm:"org_id"`
+	ObjectType ProvisionedObjectType
+	ObjectKey  string
+	// Provisioner is the path of the file the object is provisioned from.
+	Provisioner string
+	// Checksum is the checksum of the object in the file, used to detect changes.
+	Checksum string
+	Updated  int64
+}
+
+// RuleGroupProvisioningKey returns the key of a provisioned rule group.
+func RuleGroupProvisioningKey(namespaceUID, ruleGroup string) string {
+	return namespaceUID + "/" + ruleGroup
+}
+
+// ParseRuleGroupProvisioningKey returns the namespace UID and the name of the rule group of a key
+// returned by RuleGroupProvisioningKey.
+func ParseRuleGroupProvisioningKey(key string) (namespaceUID, ruleGroup string) {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 {
+		return key, ""
+	}
+	return parts[0], parts[1]
+}
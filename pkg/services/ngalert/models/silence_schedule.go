@@ -0,0 +1,185 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrSilenceScheduleNotFound = errors.New("silence schedule not found")
+	ErrSilenceScheduleInvalid  = errors.New("invalid silence schedule")
+)
+
+// maxSilenceScheduleWindow is how far a window of time intervals is looked for after the end of a
+// time range. A window that does not end by then ends at the start of that day, so that a window
+// that never ends is materialized into one silence per day rather than one per time range.
+const maxSilenceScheduleWindow = 7 * 24 * time.Hour
+
+// SilenceSchedule is a recurring silence. Its windows of time are materialized into silences of the
+// Alertmanager of its organisation ahead of time. The windows are either the occurrences of a cron
+// expression for a duration, or the times that are in one of the time intervals, with the syntax of
+// the mute time intervals of the Alertmanager, e.g. on weekdays from 22:00 to 24:00.
+type SilenceSchedule struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	UID       string `xorm:"uid"`
+	Comment   string
+	CreatedBy string
+	// Matchers are the matchers of the silences, e.g. env="prod" or job=~"batch-.*".
+	Matchers []string
+	// Cron is a cron expression with 5 fields, the start of each window lasts for Duration.
+	Cron     string
+	Duration time.Duration
+	// TimeIntervals are set instead of Cron and Duration.
+	TimeIntervals []timeinterval.TimeInterval
+	// Location is the time zone the schedule is in, UTC if it is empty.
+	Location string
+	Created  int64
+	Updated  int64
+}
+
+// TimeWindow is a window of time of a silence schedule.
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Validate checks the matchers, the schedule and the location of the silence schedule.
+func (s *SilenceSchedule) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("%w: at least one matcher is required", ErrSilenceScheduleInvalid)
+	}
+	if _, err := s.ParseMatchers(); err != nil {
+		return fmt.Errorf("%w: %s", ErrSilenceScheduleInvalid, err)
+	}
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("%w: %s", ErrSilenceScheduleInvalid, err)
+	}
+	switch {
+	case s.Cron != "" && len(s.TimeIntervals) > 0:
+		return fmt.Errorf("%w: cron and time intervals cannot be set together", ErrSilenceScheduleInvalid)
+	case s.Cron != "":
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return fmt.Errorf("%w: invalid cron expression: %s", ErrSilenceScheduleInvalid, err)
+		}
+		if s.Duration < time.Minute {
+			return fmt.Errorf("%w: the duration must be at least one minute", ErrSilenceScheduleInvalid)
+		}
+	case len(s.TimeIntervals) > 0:
+		if s.Duration != 0 {
+			return fmt.Errorf("%w: the duration is only used with a cron expression", ErrSilenceScheduleInvalid)
+		}
+	default:
+		return fmt.Errorf("%w: either a cron expression or time intervals are required", ErrSilenceScheduleInvalid)
+	}
+	return nil
+}
+
+// ParseMatchers returns the matchers of the silence schedule.
+func (s *SilenceSchedule) ParseMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", m, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	sort.Sort(matchers)
+	return matchers, nil
+}
+
+func (s *SilenceSchedule) location() (*time.Location, error) {
+	if s.Location == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Location)
+}
+
+// Windows returns the windows of time of the schedule that end after from and start before to.
+// Windows that overlap or follow each other are merged.
+func (s *SilenceSchedule) Windows(from, to time.Time) ([]TimeWindow, error) {
+	loc, err := s.location()
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []TimeWindow
+	if s.Cron != "" {
+		schedule, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return nil, err
+		}
+		// start from the windows that started before from but have not ended yet
+		for start := schedule.Next(from.Add(-s.Duration).In(loc)); !start.IsZero() && start.Before(to); start = schedule.Next(start) {
+			if end := start.Add(s.Duration); end.After(from) {
+				windows = append(windows, TimeWindow{Start: start, End: end})
+			}
+		}
+	} else {
+		var current *TimeWindow
+		limit := to.Add(maxSilenceScheduleWindow).UTC().Truncate(24 * time.Hour)
+		for t := from.In(loc).Truncate(time.Minute); t.Before(limit); t = t.Add(time.Minute) {
+			if s.containsTime(t) {
+				if current == nil {
+					if !t.Before(to) {
+						break
+					}
+					current = &TimeWindow{Start: t}
+				}
+				continue
+			}
+			if current != nil {
+				current.End = t
+				windows = append(windows, *current)
+				current = nil
+			}
+			if !t.Before(to) {
+				break
+			}
+		}
+		if current != nil {
+			current.End = limit
+			windows = append(windows, *current)
+		}
+	}
+	return mergeTimeWindows(windows), nil
+}
+
+func (s *SilenceSchedule) containsTime(t time.Time) bool {
+	for _, ti := range s.TimeIntervals {
+		if ti.ContainsTime(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTimeWindows(windows []TimeWindow) []TimeWindow {
+	if len(windows) == 0 {
+		return windows
+	}
+	merged := []TimeWindow{windows[0]}
+	for _, w := range windows[1:] {
+		last := &merged[len(merged)-1]
+		if !w.Start.After(last.End) {
+			if w.End.After(last.End) {
+				last.End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// TableName is the name of the table of silence schedules, part of the xorm TableName interface.
+func (s SilenceSchedule) TableName() string {
+	return "alert_silence_schedule"
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceScheduleWindows(t *testing.T) {
+	// 2021-10-01 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc     string
+		schedule SilenceSchedule
+		from, to time.Time
+		expected []TimeWindow
+	}{
+		{
+			desc:     "cron windows, including the window that started before from",
+			schedule: SilenceSchedule{Cron: "0 22 * * *", Duration: 2 * time.Hour},
+			from:     at(1, 23, 0),
+			to:       at(2, 23, 0),
+			expected: []TimeWindow{
+				{Start: at(1, 22, 0), End: at(2, 0, 0)},
+				{Start: at(2, 22, 0), End: at(3, 0, 0)},
+			},
+		},
+		{
+			desc:     "cron windows in the location of the schedule",
+			schedule: SilenceSchedule{Cron: "0 9 * * *", Duration: time.Hour, Location: "Europe/Paris"},
+			from:     at(1, 0, 0),
+			to:       at(2, 0, 0),
+			expected: []TimeWindow{
+				{Start: at(1, 7, 0), End: at(1, 8, 0)},
+			},
+		},
+		{
+			desc:     "overlapping cron windows are merged",
+			schedule: SilenceSchedule{Cron: "0 * * * *", Duration: 90 * time.Minute},
+			from:     at(1, 12, 0),
+			to:       at(1, 15, 0),
+			expected: []TimeWindow{
+				{Start: at(1, 11, 0), End: at(1, 15, 30)},
+			},
+		},
+		{
+			desc:     "time interval windows",
+			schedule: SilenceSchedule{TimeIntervals: mustTimeIntervals(t, `[{"weekdays": ["monday:friday"], "times": [{"start_time": "22:00", "end_time": "24:00"}]}]`)},
+			from:     at(1, 12, 0),
+			to:       at(5, 12, 0),
+			expected: []TimeWindow{
+				{Start: at(1, 22, 0), End: at(2, 0, 0)},
+				{Start: at(4, 22, 0), End: at(5, 0, 0)},
+			},
+		},
+		{
+			desc:     "a time interval window that contains from ends after to",
+			schedule: SilenceSchedule{TimeIntervals: mustTimeIntervals(t, `[{"weekdays": ["saturday", "sunday"]}]`)},
+			from:     at(2, 12, 0),
+			to:       at(2, 13, 0),
+			expected: []TimeWindow{
+				{Start: at(2, 12, 0), End: at(4, 0, 0)},
+			},
+		},
+		{
+			desc:     "a time interval window that does not end ends at the start of the day after a week",
+			schedule: SilenceSchedule{TimeIntervals: mustTimeIntervals(t, `[{"months": ["october:december"]}]`)},
+			from:     at(1, 12, 0),
+			to:       at(1, 13, 0),
+			expected: []TimeWindow{
+				{Start: at(1, 12, 0), End: at(8, 0, 0)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			windows, err := tc.schedule.Windows(tc.from, tc.to)
+			require.NoError(t, err)
+			for i := range windows {
+				windows[i].Start = windows[i].Start.UTC()
+				windows[i].End = windows[i].End.UTC()
+			}
+			require.Equal(t, tc.expected, windows)
+		})
+	}
+}
+
+func TestSilenceScheduleValidate(t *testing.T) {
+	intervals := mustTimeIntervals(t, `[{"weekdays": ["saturday", "sunday"]}]`)
+
+	testCases := []struct {
+		desc     string
+		schedule SilenceSchedule
+		err      string
+	}{
+		{
+			desc:     "valid cron schedule",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}, Cron: "0 22 * * *", Duration: time.Hour, Location: "Europe/Paris"},
+		},
+		{
+			desc:     "valid time intervals schedule",
+			schedule: SilenceSchedule{Matchers: []string{`job=~"batch-.*"`}, TimeIntervals: intervals},
+		},
+		{
+			desc:     "no matchers",
+			schedule: SilenceSchedule{Cron: "0 22 * * *", Duration: time.Hour},
+			err:      "at least one matcher is required",
+		},
+		{
+			desc:     "invalid matcher",
+			schedule: SilenceSchedule{Matchers: []string{`env=~"("`}, Cron: "0 22 * * *", Duration: time.Hour},
+			err:      "invalid matcher",
+		},
+		{
+			desc:     "invalid location",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}, Cron: "0 22 * * *", Duration: time.Hour, Location: "Nowhere/Town"},
+			err:      "unknown time zone",
+		},
+		{
+			desc:     "invalid cron expression",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}, Cron: "0 25 * * *", Duration: time.Hour},
+			err:      "invalid cron expression",
+		},
+		{
+			desc:     "cron without duration",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}, Cron: "0 22 * * *"},
+			err:      "the duration must be at least one minute",
+		},
+		{
+			desc:     "cron and time intervals",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}, Cron: "0 22 * * *", Duration: time.Hour, TimeIntervals: intervals},
+			err:      "cron and time intervals cannot be set together",
+		},
+		{
+			desc:     "no schedule",
+			schedule: SilenceSchedule{Matchers: []string{`env="prod"`}},
+			err:      "either a cron expression or time intervals are required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.schedule.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrSilenceScheduleInvalid)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func mustTimeIntervals(t *testing.T, s string) []timeinterval.TimeInterval {
+	t.Helper()
+	var intervals []timeinterval.TimeInterval
+	require.NoError(t, json.Unmarshal([]byte(s), &intervals))
+	return intervals
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// AlertStateHistoryEntry represents a change of the state of an alert instance.
+type AlertStateHistoryEntry struct {
+	ID            int64              `xorm:"pk autoincr 'id'" json:"id"`
+	RuleOrgID     int64              `xorm:"rule_org_id" json:"ruleOrgId"`
+	RuleUID       string             `xorm:"rule_uid" json:"ruleUid"`
+	Labels        InstanceLabels     `json:"labels"`
+	LabelsHash    string             `json:"-"`
+	PreviousState InstanceStateType  `json:"previousState"`
+	State         InstanceStateType  `json:"state"`
+	Values        StateHistoryValues `xorm:"evaluation_values" json:"values"`
+	EvaluatedAt   time.Time          `json:"evaluatedAt"`
+}
+
+// StateHistoryValues holds the value of each RefID of the reduce and math
+// expressions of the evaluation that changed the state of an alert instance.
+type StateHistoryValues map[string]*float64
+
+// FromDB loads values stored in the database as json.
+// FromDB is part of the xorm Conversion interface.
+func (v *StateHistoryValues) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// ToDB serializes the values to json.
+// ToDB is part of the xorm Conversion interface.
+func (v *StateHistoryValues) ToDB() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// SaveAlertStateHistoryCommand is the command for recording a change of the state of an alert instance.
+type SaveAlertStateHistoryCommand struct {
+	RuleOrgID     int64
+	RuleUID       string
+	Labels        InstanceLabels
+	PreviousState InstanceStateType
+	State         InstanceStateType
+	Values        StateHistoryValues
+	EvaluatedAt   time.Time
+}
+
+// ListAlertStateHistoryQuery is the query for the changes of state of the alert instances
+// of an organisation, most recent first.
+type ListAlertStateHistoryQuery struct {
+	RuleOrgID int64
+	RuleUID   string
+	// Matchers only keeps the entries whose labels match all of them.
+	Matchers []*labels.Matcher
+	From     time.Time
+	To       time.Time
+	// Limit is the maximum number of entries to return, 0 means no limit.
+	Limit int
+
+	Result []*AlertStateHistoryEntry
+}
+
+// MatchesLabels returns true if the labels match all the matchers of the query.
+func (q *ListAlertStateHistoryQuery) MatchesLabels(lbs InstanceLabels) bool {
+	for _, m := range q.Matchers {
+		if !m.Matches(lbs[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
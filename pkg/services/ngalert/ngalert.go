@@ -18,7 +18,10 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -26,7 +29,6 @@ import (
 )
 
 const (
-	maxAttempts int64 = 3
 	// scheduler interval
 	// changing this value is discouraged
 	// because this could cause existing alert definition
@@ -35,11 +37,13 @@ const (
 	defaultBaseIntervalSeconds = 10
 	// default alert definition interval
 	defaultIntervalSeconds int64 = 6 * defaultBaseIntervalSeconds
+	// frequency at which old alert state history and notification deliveries are deleted
+	cleanUpInterval = 10 * time.Minute
 )
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, m *metrics.Metrics) (*AlertNG, error) {
+	quotaService *quota.QuotaService, m *metrics.Metrics, grafanaLive *live.GrafanaLive) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:             cfg,
 		DataSourceCache: dataSourceCache,
@@ -49,6 +53,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		DataProxy:       dataProxy,
 		QuotaService:    quotaService,
 		Metrics:         m,
+		Live:            grafanaLive,
 		Log:             log.New("ngalert"),
 	}
 
@@ -73,12 +78,16 @@ type AlertNG struct {
 	DataProxy       *datasourceproxy.DataSourceProxyService
 	QuotaService    *quota.QuotaService
 	Metrics         *metrics.Metrics
+	Live            *live.GrafanaLive
 	Log             log.Logger
 	schedule        schedule.ScheduleService
 	stateManager    *state.Manager
+	historyStore    store.StateHistoryStore
+	deliveryStore   store.NotificationDeliveryStore
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+	silenceScheduler     *notifier.SilenceScheduler
 }
 
 func (ng *AlertNG) init() error {
@@ -95,18 +104,33 @@ func (ng *AlertNG) init() error {
 		Logger:                 ng.Log,
 	}
 
-	ng.MultiOrgAlertmanager = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store)
+	ng.MultiOrgAlertmanager = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, store, ng.Metrics)
 
 	// Let's make sure we're able to complete an initial sync of Alertmanagers before we start the alerting components.
 	if err := ng.MultiOrgAlertmanager.LoadAndSyncAlertmanagersForOrgs(context.Background()); err != nil {
 		return err
 	}
 
+	var managedStreamRunner *managedstream.Runner
+	if ng.Live != nil {
+		managedStreamRunner = ng.Live.ManagedStreamRunner
+		if ng.Live.Pipeline != nil {
+			ng.Live.Pipeline.SetAlertSender(notifier.NewLiveAlertSender(ng.MultiOrgAlertmanager))
+		}
+	}
+	recordingWriter, err := recording.NewWriter(ng.Cfg, managedStreamRunner, log.New("ngalert.recording"))
+	if err != nil {
+		ng.Log.Error("the results of recording rules will not be written", "err", err)
+	}
+
 	schedCfg := schedule.SchedulerCfg{
 		C:                       clock.New(),
 		BaseInterval:            baseInterval,
 		Logger:                  log.New("ngalert.scheduler"),
-		MaxAttempts:             maxAttempts,
+		MaxAttempts:             ng.Cfg.EvaluationMaxAttempts,
+		EvaluationTimeout:       ng.Cfg.EvaluationTimeout,
+		RetryBackoff:            ng.Cfg.EvaluationRetryBackoff,
+		SkipIfRunning:           ng.Cfg.EvaluationSkipIfRunning,
 		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log},
 		InstanceStore:           store,
 		RuleStore:               store,
@@ -115,12 +139,20 @@ func (ng *AlertNG) init() error {
 		MultiOrgNotifier:        ng.MultiOrgAlertmanager,
 		Metrics:                 ng.Metrics,
 		AdminConfigPollInterval: ng.Cfg.AdminConfigPollInterval,
+		RecordingWriter:         recordingWriter,
+		ShardingEnabled:         ng.Cfg.HAShardingEnabled,
+		InstanceID:              ng.Cfg.HAInstanceID,
+		SchedulerInstanceStore:  store,
+		HeartbeatTimeout:        ng.Cfg.HAHeartbeatTimeout,
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics, store, store)
+	stateManager := state.NewManager(ng.Log, ng.Metrics, store, store, store)
 	schedule := schedule.NewScheduler(schedCfg, ng.DataService, ng.Cfg.AppURL, stateManager)
 
 	ng.stateManager = stateManager
 	ng.schedule = schedule
+	ng.historyStore = store
+	ng.deliveryStore = store
+	ng.silenceScheduler = notifier.NewSilenceScheduler(store, ng.MultiOrgAlertmanager)
 
 	api := api.API{
 		Cfg:                  ng.Cfg,
@@ -136,6 +168,11 @@ func (ng *AlertNG) init() error {
 		AdminConfigStore:     store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		StateHistoryStore:    store,
+		ProvisioningStore:    store,
+		DeliveryStore:        store,
+		LegacyImportStore:    store,
+		SilenceScheduleStore: store,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics)
 
@@ -154,9 +191,43 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.silenceScheduler.Run(subCtx)
+	})
+	if ng.Cfg.StateHistoryMaxAge > 0 {
+		children.Go(func() error {
+			return ng.cleanUp(subCtx, "alert state history", ng.Cfg.StateHistoryMaxAge, ng.historyStore.DeleteAlertStateHistoryBefore)
+		})
+	}
+	if ng.Cfg.NotificationLogMaxAge > 0 {
+		children.Go(func() error {
+			return ng.cleanUp(subCtx, "notification log", ng.Cfg.NotificationLogMaxAge, ng.deliveryStore.DeleteNotificationDeliveriesBefore)
+		})
+	}
 	return children.Wait()
 }
 
+// cleanUp periodically deletes the entries of the alert state history or of the
+// notification log that are older than their configured maximum age.
+func (ng *AlertNG) cleanUp(ctx context.Context, name string, maxAge time.Duration, deleteBefore func(time.Time) (int64, error)) error {
+	ticker := time.NewTicker(cleanUpInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			olderThan := time.Now().Add(-maxAge)
+			affected, err := deleteBefore(olderThan)
+			if err != nil {
+				ng.Log.Error("failed to clean up "+name, "err", err)
+				continue
+			}
+			ng.Log.Debug("cleaned up "+name, "deleted", affected, "olderThan", olderThan)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	gokit_log "github.com/go-kit/kit/log"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/dispatch"
@@ -73,6 +74,11 @@ const (
 `
 )
 
+// DefaultConfiguration returns the Alertmanager configuration of the organisations that have not configured it.
+func DefaultConfiguration() string {
+	return alertmanagerDefaultConfiguration
+}
+
 type Alertmanager struct {
 	logger      log.Logger
 	gokitLogger gokit_log.Logger
@@ -104,10 +110,15 @@ type Alertmanager struct {
 	config          *apimodels.PostableUserConfig
 	configHash      [16]byte
 	orgID           int64
+
+	// deliveries records the deliveries of the integrations, it is nil if they are not recorded.
+	deliveries *deliveryLog
+	rateLimits *rateLimits
 }
 
-func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics) (*Alertmanager, error) {
+func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics, deliveries *deliveryLog) (*Alertmanager, error) {
 	am := &Alertmanager{
+		deliveries:        deliveries,
 		Settings:          cfg,
 		stopc:             make(chan struct{}),
 		logger:            log.New("alertmanager", "org", orgID),
@@ -120,6 +131,7 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, m
 	}
 
 	am.gokitLogger = gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))
+	am.rateLimits = newRateLimits(clock.New(), am.logger)
 
 	// Initialize the notification log
 	am.wg.Add(1)
@@ -181,6 +193,7 @@ func (am *Alertmanager) StopAndWait() {
 	}
 
 	am.alerts.Close()
+	am.rateLimits.stop()
 
 	close(am.stopc)
 
@@ -369,6 +382,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	if err != nil {
 		return fmt.Errorf("failed to build integration map: %w", err)
 	}
+	am.rateLimits.prune(integrationUIDs(cfg.AlertmanagerConfig.Receivers))
 	// Now, let's put together our notification pipeline
 	routingStage := make(notify.RoutingStage, len(integrationsMap))
 
@@ -428,6 +442,17 @@ func (am *Alertmanager) buildIntegrationsMap(receivers []*apimodels.PostableApiR
 	return integrationsMap, nil
 }
 
+// integrationUIDs returns the UIDs of the Grafana integrations of the receivers.
+func integrationUIDs(receivers []*apimodels.PostableApiReceiver) map[string]struct{} {
+	uids := make(map[string]struct{})
+	for _, receiver := range receivers {
+		for _, r := range receiver.GrafanaManagedReceivers {
+			uids[r.UID] = struct{}{}
+		}
+	}
+	return uids
+}
+
 type NotificationChannel interface {
 	notify.Notifier
 	notify.ResolvedSender
@@ -441,6 +466,8 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
+		n = am.deliveries.wrap(am.orgID, receiver.Name, r, n)
+		n = am.rateLimits.wrap(receiver.Name, r.UID, r.RateLimit, n)
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
@@ -469,10 +496,19 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 			DisableResolveMessage: r.DisableResolveMessage,
 			Settings:              r.Settings,
 			SecureSettings:        secureSettings,
+			RateLimit:             r.RateLimit,
 		}
 		n   NotificationChannel
 		err error
 	)
+	if cfg.RateLimit != nil {
+		if err := cfg.RateLimit.Validate(); err != nil {
+			return nil, InvalidReceiverError{
+				Receiver: r,
+				Err:      err,
+			}
+		}
+	}
 	switch r.Type {
 	case "email":
 		n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
@@ -494,6 +530,14 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewKafkaNotifier(cfg, tmpl)
 	case "webhook":
 		n, err = channels.NewWebHookNotifier(cfg, tmpl)
+	case "webhook-hmac":
+		n, err = channels.NewHMACWebhookNotifier(cfg, tmpl)
+	case "mqtt":
+		n, err = channels.NewMQTTNotifier(cfg, tmpl)
+	case "amqp":
+		n, err = channels.NewAMQPNotifier(cfg, tmpl)
+	case "syslog":
+		n, err = channels.NewSyslogNotifier(cfg, tmpl)
 	case "sensugo":
 		n, err = channels.NewSensuGoNotifier(cfg, tmpl)
 	case "discord":
@@ -47,7 +47,7 @@ func setupAMTest(t *testing.T) *Alertmanager {
 		Logger:                 log.New("alertmanager-test"),
 	}
 
-	am, err := newAlertmanager(1, cfg, store, m)
+	am, err := newAlertmanager(1, cfg, store, m, nil)
 	require.NoError(t, err)
 	return am
 }
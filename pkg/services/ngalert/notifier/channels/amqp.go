@@ -0,0 +1,133 @@
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/streadway/amqp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// AMQPNotifier is responsible for publishing alert notifications
+// to an exchange of an AMQP 0.9.1 broker, such as RabbitMQ.
+type AMQPNotifier struct {
+	old_notifiers.NotifierBase
+	URL                string
+	Exchange           string
+	RoutingKey         string
+	Persistent         bool
+	InsecureSkipVerify bool
+	MaxAlerts          int
+	log                log.Logger
+	tmpl               *template.Template
+}
+
+// NewAMQPNotifier is the constructor for the AMQP notifier.
+func NewAMQPNotifier(model *NotificationChannelConfig, t *template.Template) (*AMQPNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	// The URL contains the credentials of the broker.
+	url := model.DecryptedValue("url", model.Settings.Get("url").MustString())
+	if url == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+	if _, err := amqp.ParseURI(url); err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid url property in settings", Err: err}
+	}
+	exchange := model.Settings.Get("exchange").MustString()
+	routingKey := model.Settings.Get("routingKey").MustString()
+	if exchange == "" && routingKey == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find exchange or routingKey property in settings"}
+	}
+	return &AMQPNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:                url,
+		Exchange:           exchange,
+		RoutingKey:         routingKey,
+		Persistent:         model.Settings.Get("persistent").MustBool(true),
+		InsecureSkipVerify: model.Settings.Get("insecureSkipVerify").MustBool(false),
+		MaxAlerts:          model.Settings.Get("maxAlerts").MustInt(0),
+		log:                log.New("alerting.notifier.amqp"),
+		tmpl:               t,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (an *AMQPNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	body, err := newWebhookMessage(ctx, an.tmpl, as, an.MaxAlerts, an.log)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, brokerTimeout)
+	defer cancel()
+
+	conn, err := amqp.DialConfig(an.URL, amqp.Config{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: an.InsecureSkipVerify},
+		Dial:            amqp.DefaultDial(brokerTimeout),
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to connect to AMQP broker: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			an.log.Debug("failed to close AMQP connection", "err", err)
+		}
+	}()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return true, fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+	// The broker confirms the messages it has taken responsibility for.
+	if err := ch.Confirm(false); err != nil {
+		return true, fmt.Errorf("failed to put AMQP channel in confirm mode: %w", err)
+	}
+	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	msg := amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Transient,
+		Timestamp:    time.Now(),
+		Body:         body,
+	}
+	if an.Persistent {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	if err := ch.Publish(an.Exchange, an.RoutingKey, false, false, msg); err != nil {
+		return true, fmt.Errorf("failed to publish to AMQP exchange %q: %w", an.Exchange, err)
+	}
+
+	select {
+	case confirm, ok := <-confirms:
+		if !ok {
+			return true, errors.New("AMQP channel closed before the broker confirmed the message")
+		}
+		if !confirm.Ack {
+			return true, errors.New("AMQP broker rejected the message")
+		}
+	case <-ctx.Done():
+		return true, fmt.Errorf("waiting for AMQP broker to confirm the message: %w", ctx.Err())
+	}
+
+	return true, nil
+}
+
+func (an *AMQPNotifier) SendResolved() bool {
+	return !an.GetDisableResolveMessage()
+}
@@ -0,0 +1,135 @@
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// brokerTimeout is the time the notifiers publishing to message brokers wait for the broker,
+// unless the context of the notification has an earlier deadline.
+const brokerTimeout = 30 * time.Second
+
+// MQTTNotifier is responsible for publishing alert notifications
+// to a topic of a MQTT broker.
+type MQTTNotifier struct {
+	old_notifiers.NotifierBase
+	BrokerURL          string
+	Topic              string
+	ClientID           string
+	User               string
+	Password           string
+	QoS                byte
+	Retain             bool
+	InsecureSkipVerify bool
+	MaxAlerts          int
+	log                log.Logger
+	tmpl               *template.Template
+}
+
+// NewMQTTNotifier is the constructor for the MQTT notifier.
+func NewMQTTNotifier(model *NotificationChannelConfig, t *template.Template) (*MQTTNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	brokerURL := model.Settings.Get("brokerUrl").MustString()
+	if brokerURL == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find brokerUrl property in settings"}
+	}
+	topic := model.Settings.Get("topic").MustString()
+	if topic == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find topic property in settings"}
+	}
+	// The contact point form sends the QoS as a string.
+	qos := model.Settings.Get("qos").MustInt(0)
+	if s, err := model.Settings.Get("qos").String(); err == nil && s != "" {
+		if qos, err = strconv.Atoi(s); err != nil {
+			return nil, receiverInitError{Cfg: *model, Reason: "invalid qos property in settings", Err: err}
+		}
+	}
+	if qos < 0 || qos > 2 {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid qos %d, it must be 0, 1 or 2", qos)}
+	}
+	return &MQTTNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		BrokerURL:          brokerURL,
+		Topic:              topic,
+		ClientID:           model.Settings.Get("clientId").MustString(),
+		User:               model.Settings.Get("username").MustString(),
+		Password:           model.DecryptedValue("password", model.Settings.Get("password").MustString()),
+		QoS:                byte(qos),
+		Retain:             model.Settings.Get("retain").MustBool(false),
+		InsecureSkipVerify: model.Settings.Get("insecureSkipVerify").MustBool(false),
+		MaxAlerts:          model.Settings.Get("maxAlerts").MustInt(0),
+		log:                log.New("alerting.notifier.mqtt"),
+		tmpl:               t,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (mn *MQTTNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	body, err := newWebhookMessage(ctx, mn.tmpl, as, mn.MaxAlerts, mn.log)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, brokerTimeout)
+	defer cancel()
+
+	// Brokers disconnect the clients whose ID is already connected, so that concurrent
+	// notifications need their own ID.
+	clientID := mn.ClientID
+	if clientID == "" {
+		clientID = "grafana-" + util.GenerateShortUID()
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(mn.BrokerURL).
+		SetClientID(clientID).
+		SetUsername(mn.User).
+		SetPassword(mn.Password).
+		SetTLSConfig(&tls.Config{InsecureSkipVerify: mn.InsecureSkipVerify}).
+		SetConnectTimeout(brokerTimeout).
+		SetAutoReconnect(false)
+	client := mqtt.NewClient(opts)
+	if err := waitToken(ctx, client.Connect()); err != nil {
+		return true, fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	if err := waitToken(ctx, client.Publish(mn.Topic, mn.QoS, mn.Retain, body)); err != nil {
+		return true, fmt.Errorf("failed to publish to MQTT topic %s: %w", mn.Topic, err)
+	}
+
+	return true, nil
+}
+
+// waitToken waits for the flow of the MQTT token to complete, or for the context to be done.
+func waitToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (mn *MQTTNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,88 @@
+package channels
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMQTTNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		expQoS       byte
+		expInitError string
+	}{
+		{
+			name:     "Default config",
+			settings: `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts"}`,
+			expQoS:   0,
+		}, {
+			name:     "QoS as number",
+			settings: `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": 1}`,
+			expQoS:   1,
+		}, {
+			name:     "QoS as string",
+			settings: `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": "2"}`,
+			expQoS:   2,
+		}, {
+			name:         "Invalid QoS",
+			settings:     `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": 3}`,
+			expInitError: `failed to validate receiver "mqtt_testing" of type "mqtt": invalid qos 3, it must be 0, 1 or 2`,
+		}, {
+			name:         "Missing topic",
+			settings:     `{"brokerUrl": "tcp://localhost:1883"}`,
+			expInitError: `failed to validate receiver "mqtt_testing" of type "mqtt": could not find topic property in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			n, err := NewMQTTNotifier(&NotificationChannelConfig{Name: "mqtt_testing", Type: "mqtt", Settings: settingsJSON}, tmpl)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expQoS, n.QoS)
+		})
+	}
+
+	t.Run("Unreachable broker", func(t *testing.T) {
+		// Reserve a port nobody listens on.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		require.NoError(t, l.Close())
+
+		settingsJSON, err := simplejson.NewJson([]byte(`{"brokerUrl": "tcp://` + addr + `", "topic": "alerts"}`))
+		require.NoError(t, err)
+		n, err := NewMQTTNotifier(&NotificationChannelConfig{Name: "mqtt_testing", Type: "mqtt", Settings: settingsJSON}, tmpl)
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ctx = notify.WithReceiverName(ctx, "my_receiver")
+		retry, err := n.Notify(ctx, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to connect to MQTT broker")
+		require.True(t, retry)
+	})
+}
@@ -215,6 +215,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 		}
 	}()
 
+	recordStatusCode(request.Context(), resp.StatusCode)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
@@ -0,0 +1,227 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	SyslogProtocolUDP = "udp"
+	SyslogProtocolTCP = "tcp"
+	SyslogProtocolTLS = "tls"
+)
+
+// syslogFacilities are the facility codes of RFC 5424 by name.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities are the severity codes of RFC 5424 by name.
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// SyslogNotifier is responsible for sending alert notifications as RFC 5424
+// syslog messages over UDP, TCP or TLS, one message per alert.
+type SyslogNotifier struct {
+	old_notifiers.NotifierBase
+	Address            string
+	Protocol           string
+	Facility           int
+	Severity           int
+	ResolvedSeverity   int
+	Hostname           string
+	AppName            string
+	InsecureSkipVerify bool
+	log                log.Logger
+	tmpl               *template.Template
+}
+
+// NewSyslogNotifier is the constructor for the syslog notifier.
+func NewSyslogNotifier(model *NotificationChannelConfig, t *template.Template) (*SyslogNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	address := model.Settings.Get("address").MustString()
+	if address == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find address property in settings"}
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid address property in settings", Err: err}
+	}
+	protocol := model.Settings.Get("protocol").MustString(SyslogProtocolUDP)
+	switch protocol {
+	case SyslogProtocolUDP, SyslogProtocolTCP, SyslogProtocolTLS:
+	default:
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid protocol %q, it must be udp, tcp or tls", protocol)}
+	}
+	facility, ok := syslogFacilities[model.Settings.Get("facility").MustString("local0")]
+	if !ok {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid facility %q", model.Settings.Get("facility").MustString())}
+	}
+	severity, ok := syslogSeverities[model.Settings.Get("severity").MustString("warning")]
+	if !ok {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid severity %q", model.Settings.Get("severity").MustString())}
+	}
+	resolvedSeverity, ok := syslogSeverities[model.Settings.Get("resolvedSeverity").MustString("info")]
+	if !ok {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid resolvedSeverity %q", model.Settings.Get("resolvedSeverity").MustString())}
+	}
+	hostname := model.Settings.Get("hostname").MustString()
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	return &SyslogNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		Address:            address,
+		Protocol:           protocol,
+		Facility:           facility,
+		Severity:           severity,
+		ResolvedSeverity:   resolvedSeverity,
+		Hostname:           hostname,
+		AppName:            model.Settings.Get("appName").MustString("grafana"),
+		InsecureSkipVerify: model.Settings.Get("insecureSkipVerify").MustBool(false),
+		log:                log.New("alerting.notifier.syslog"),
+		tmpl:               t,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (sn *SyslogNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	_, data := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+	if tmplErr != nil {
+		sn.log.Debug("failed to template syslog message", "err", tmplErr.Error())
+	}
+
+	var buf bytes.Buffer
+	now := time.Now()
+	for _, a := range data.Alerts {
+		msg, err := json.Marshal(a)
+		if err != nil {
+			return false, err
+		}
+		severity := sn.Severity
+		if a.Status == string(model.AlertResolved) {
+			severity = sn.ResolvedSeverity
+		}
+		line := formatSyslogMessage(sn.Facility*8+severity, now, sn.Hostname, sn.AppName, a.Status, msg)
+		if sn.Protocol == SyslogProtocolUDP {
+			// Each datagram is a message.
+			if err := sn.send(ctx, line); err != nil {
+				return true, err
+			}
+			continue
+		}
+		// Messages are framed with octet counting on streams, as per RFC 6587.
+		buf.WriteString(strconv.Itoa(len(line)))
+		buf.WriteByte(' ')
+		buf.Write(line)
+	}
+
+	if buf.Len() > 0 {
+		if err := sn.send(ctx, buf.Bytes()); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+func (sn *SyslogNotifier) send(ctx context.Context, b []byte) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch sn.Protocol {
+	case SyslogProtocolTLS:
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{InsecureSkipVerify: sn.InsecureSkipVerify}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", sn.Address)
+	default:
+		conn, err = dialer.DialContext(ctx, sn.Protocol, sn.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog server: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			sn.log.Debug("failed to close syslog connection", "err", err)
+		}
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.Write(b); err != nil {
+		return fmt.Errorf("failed to send syslog message: %w", err)
+	}
+	return nil
+}
+
+// syslogTimestampFormat is RFC 3339 with at most microseconds, as required by RFC 5424.
+const syslogTimestampFormat = "2006-01-02T15:04:05.999999Z07:00"
+
+// formatSyslogMessage formats a RFC 5424 message without structured data.
+func formatSyslogMessage(priority int, timestamp time.Time, hostname, appName, msgID string, msg []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s - ",
+		priority,
+		timestamp.UTC().Format(syslogTimestampFormat),
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(appName, 48),
+		os.Getpid(),
+		syslogHeaderField(msgID, 32),
+	)
+	b.Write(msg)
+	return b.Bytes()
+}
+
+// syslogHeaderField returns the value of a header field of a RFC 5424 message, which is
+// made of at most maxLen printable US-ASCII characters, or - if it is empty.
+func syslogHeaderField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (sn *SyslogNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
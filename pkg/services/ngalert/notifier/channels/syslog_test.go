@@ -0,0 +1,140 @@
+package channels
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var syslogMessageRegexp = regexp.MustCompile(`^<(\d+)>1 \S+ host grafana \d+ (firing|resolved) - (.*)$`)
+
+func TestSyslogNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		}, {
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+				Annotations: model.LabelSet{"ann1": "annv2"},
+			},
+		},
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	ctx = notify.WithReceiverName(ctx, "my_receiver")
+
+	// checkMessage checks the syslog message of the i-th alert.
+	checkMessage := func(t *testing.T, i int, msg string) {
+		t.Helper()
+		m := syslogMessageRegexp.FindStringSubmatch(msg)
+		require.NotNil(t, m, msg)
+		// local0.warning
+		require.Equal(t, "132", m[1])
+		require.Equal(t, "firing", m[2])
+		var alert ExtendedAlert
+		require.NoError(t, json.Unmarshal([]byte(m[3]), &alert))
+		require.Equal(t, string(alerts[i].Labels["lbl1"]), alert.Labels["lbl1"])
+		require.Equal(t, alerts[i].Fingerprint().String(), alert.Fingerprint)
+	}
+
+	t.Run("UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		n := newSyslogNotifierForTests(t, tmpl, `{"address": "`+conn.LocalAddr().String()+`", "hostname": "host"}`)
+		ok, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		buf := make([]byte, 65536)
+		for i := range alerts {
+			l, _, err := conn.ReadFrom(buf)
+			require.NoError(t, err)
+			checkMessage(t, i, string(buf[:l]))
+		}
+	})
+
+	t.Run("TCP", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+
+		n := newSyslogNotifierForTests(t, tmpl, `{"address": "`+l.Addr().String()+`", "protocol": "tcp", "hostname": "host"}`)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := n.Notify(ctx, alerts...)
+			errs <- err
+		}()
+
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for i := range alerts {
+			// Messages are prefixed with their length.
+			length, err := r.ReadString(' ')
+			require.NoError(t, err)
+			size, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			require.NoError(t, err)
+			msg := make([]byte, size)
+			_, err = io.ReadFull(r, msg)
+			require.NoError(t, err)
+			checkMessage(t, i, string(msg))
+		}
+		require.NoError(t, <-errs)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		for settings, expErr := range map[string]string{
+			`{}`: `failed to validate receiver "syslog_testing" of type "syslog": could not find address property in settings`,
+			`{"address": "localhost:514", "protocol": "http"}`:   `failed to validate receiver "syslog_testing" of type "syslog": invalid protocol "http", it must be udp, tcp or tls`,
+			`{"address": "localhost:514", "facility": "local9"}`: `failed to validate receiver "syslog_testing" of type "syslog": invalid facility "local9"`,
+		} {
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+			_, err = NewSyslogNotifier(&NotificationChannelConfig{Name: "syslog_testing", Type: "syslog", Settings: settingsJSON}, tmpl)
+			require.EqualError(t, err, expErr)
+		}
+	})
+}
+
+func TestSyslogHeaderField(t *testing.T) {
+	require.Equal(t, "-", syslogHeaderField("", 48))
+	require.Equal(t, "myhost", syslogHeaderField("my host\n", 48))
+	require.Equal(t, "abc", syslogHeaderField("abcdef", 3))
+}
+
+func newSyslogNotifierForTests(t *testing.T, tmpl *template.Template, settings string) *SyslogNotifier {
+	t.Helper()
+	settingsJSON, err := simplejson.NewJson([]byte(settings))
+	require.NoError(t, err)
+	n, err := NewSyslogNotifier(&NotificationChannelConfig{Name: "syslog_testing", Type: "syslog", Settings: settingsJSON}, tmpl)
+	require.NoError(t, err)
+	return n
+}
@@ -10,9 +10,13 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sync/atomic"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/prometheus/common/model"
 
@@ -62,6 +66,8 @@ type NotificationChannelConfig struct {
 	DisableResolveMessage bool                          `json:"disableResolveMessage"`
 	Settings              *simplejson.Json              `json:"settings"`
 	SecureSettings        securejsondata.SecureJsonData `json:"secureSettings"`
+	// RateLimit is enforced by the Alertmanager for every channel, the channels do not have to handle it.
+	RateLimit *ngmodels.NotificationRateLimit `json:"rateLimit,omitempty"`
 }
 
 // DecryptedValue returns decrypted value from secureSettings
@@ -119,6 +125,7 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 		}
 	}()
 
+	recordStatusCode(ctx, resp.StatusCode)
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
@@ -134,6 +141,31 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 	return respBody, nil
 }
 
+type statusCodeKey struct{}
+
+// WithStatusCodeRecorder returns a context in which the notifiers record the status code of the
+// HTTP responses they receive, and a function returning the last recorded status code, or 0 if none is.
+func WithStatusCodeRecorder(ctx context.Context) (context.Context, func() int) {
+	code := new(int64)
+	return context.WithValue(ctx, statusCodeKey{}, code), func() int {
+		return int(atomic.LoadInt64(code))
+	}
+}
+
+// recordStatusCode records the status code of a HTTP response if the context has a recorder.
+func recordStatusCode(ctx context.Context, statusCode int) {
+	if code, ok := ctx.Value(statusCodeKey{}).(*int64); ok && statusCode != 0 {
+		atomic.StoreInt64(code, int64(statusCode))
+	}
+}
+
+// sendWebhook sends a webhook with the notification service and records the status code of the response.
+func sendWebhook(ctx context.Context, cmd *models.SendWebhookSync) error {
+	err := bus.DispatchCtx(ctx, cmd)
+	recordStatusCode(ctx, cmd.StatusCode)
+	return err
+}
+
 func joinUrlPath(base, additionalPath string, logger log.Logger) string {
 	u, err := url.Parse(base)
 	if err != nil {
@@ -9,7 +9,6 @@ import (
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
-	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
@@ -74,14 +73,38 @@ type webhookMessage struct {
 
 // Notify implements the Notifier interface.
 func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
-	groupKey, err := notify.ExtractGroupKey(ctx)
+	body, err := newWebhookMessage(ctx, wn.tmpl, as, wn.MaxAlerts, wn.log)
 	if err != nil {
 		return false, err
 	}
 
-	as, numTruncated := truncateAlerts(wn.MaxAlerts, as)
+	cmd := &models.SendWebhookSync{
+		Url:        wn.URL,
+		User:       wn.User,
+		Password:   wn.Password,
+		Body:       string(body),
+		HttpMethod: wn.HTTPMethod,
+	}
+
+	if err := sendWebhook(ctx, cmd); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// newWebhookMessage returns the JSON webhook message of the alerts, with at most maxAlerts alerts
+// if maxAlerts is greater than 0. It is also the payload of the notifiers that publish alerts to
+// message brokers.
+func newWebhookMessage(ctx context.Context, t *template.Template, as []*types.Alert, maxAlerts int, l log.Logger) ([]byte, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	as, numTruncated := truncateAlerts(maxAlerts, as)
 	var tmplErr error
-	tmpl, data := TmplText(ctx, wn.tmpl, as, wn.log, &tmplErr)
+	tmpl, data := TmplText(ctx, t, as, l, &tmplErr)
 	msg := &webhookMessage{
 		Version:         "1",
 		ExtendedData:    data,
@@ -98,27 +121,10 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	}
 
 	if tmplErr != nil {
-		wn.log.Debug("failed to template webhook message", "err", tmplErr.Error())
-	}
-
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return false, err
+		l.Debug("failed to template webhook message", "err", tmplErr.Error())
 	}
 
-	cmd := &models.SendWebhookSync{
-		Url:        wn.URL,
-		User:       wn.User,
-		Password:   wn.Password,
-		Body:       string(body),
-		HttpMethod: wn.HTTPMethod,
-	}
-
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		return false, err
-	}
-
-	return true, nil
+	return json.Marshal(msg)
 }
 
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
@@ -0,0 +1,160 @@
+package channels
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	DefaultHMACSignatureHeader = "X-Grafana-Signature"
+	DefaultHMACTimestampHeader = "X-Grafana-Timestamp"
+)
+
+// HMACWebhookNotifier is responsible for sending alert notifications as webhooks
+// signed with HMAC-SHA256, with headers templated from the alerts.
+type HMACWebhookNotifier struct {
+	old_notifiers.NotifierBase
+	URL             string
+	HTTPMethod      string
+	Secret          string
+	SignatureHeader string
+	TimestampHeader string
+	// Headers are the templates of the custom headers, by header name.
+	Headers   map[string]string
+	MaxAlerts int
+	log       log.Logger
+	tmpl      *template.Template
+	now       func() time.Time
+}
+
+// NewHMACWebhookNotifier is the constructor for the HMAC signed webhook notifier.
+func NewHMACWebhookNotifier(model *NotificationChannelConfig, t *template.Template) (*HMACWebhookNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	url := model.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+	secret := model.DecryptedValue("secret", model.Settings.Get("secret").MustString())
+	if secret == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find secret property in settings"}
+	}
+	headers, err := parseHeaderTemplates(model.Settings.Get("headers").Interface())
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid headers property in settings", Err: err}
+	}
+	return &HMACWebhookNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:             url,
+		HTTPMethod:      model.Settings.Get("httpMethod").MustString("POST"),
+		Secret:          secret,
+		SignatureHeader: model.Settings.Get("signatureHeader").MustString(DefaultHMACSignatureHeader),
+		TimestampHeader: model.Settings.Get("timestampHeader").MustString(DefaultHMACTimestampHeader),
+		Headers:         headers,
+		MaxAlerts:       model.Settings.Get("maxAlerts").MustInt(0),
+		log:             log.New("alerting.notifier.webhook-hmac"),
+		tmpl:            t,
+		now:             time.Now,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (wn *HMACWebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	body, err := newWebhookMessage(ctx, wn.tmpl, as, wn.MaxAlerts, wn.log)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, wn.tmpl, as, wn.log, &tmplErr)
+	headers := make(map[string]string, len(wn.Headers)+2)
+	for name, text := range wn.Headers {
+		headers[name] = tmpl(text)
+	}
+	if tmplErr != nil {
+		wn.log.Debug("failed to template webhook headers", "err", tmplErr.Error())
+	}
+
+	// The signature headers are set last so that custom headers cannot override them.
+	timestamp := strconv.FormatInt(wn.now().Unix(), 10)
+	headers[wn.TimestampHeader] = timestamp
+	headers[wn.SignatureHeader] = "sha256=" + hmacSignature(wn.Secret, timestamp, body)
+
+	cmd := &models.SendWebhookSync{
+		Url:        wn.URL,
+		Body:       string(body),
+		HttpMethod: wn.HTTPMethod,
+		HttpHeader: headers,
+	}
+
+	if err := sendWebhook(ctx, cmd); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// parseHeaderTemplates parses the templates of the custom headers, which are either an object
+// or lines of "Name: template", as entered in the text area of the contact point form.
+func parseHeaderTemplates(v interface{}) (map[string]string, error) {
+	headers := make(map[string]string)
+	switch v := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		for name, value := range v {
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("the template of header %s must be a string", name)
+			}
+			headers[name] = text
+		}
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("invalid header %q, it must be Name: template", line)
+			}
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	default:
+		return nil, fmt.Errorf("unexpected type %T", v)
+	}
+	return headers, nil
+}
+
+// hmacSignature returns the hex encoded HMAC-SHA256 of the timestamp and the body, separated by a
+// dot. Signing the timestamp lets receivers reject replayed requests.
+func hmacSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (wn *HMACWebhookNotifier) SendResolved() bool {
+	return !wn.GetDisableResolveMessage()
+}
@@ -0,0 +1,132 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestHMACWebhookNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "team": "ops"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		expHeaders   map[string]string
+		expInitError string
+	}{
+		{
+			name:     "Default config",
+			settings: `{"url": "http://localhost/test", "secret": "s3cr3t"}`,
+			expHeaders: map[string]string{
+				"X-Grafana-Timestamp": "1000",
+			},
+		}, {
+			name: "Custom headers as object",
+			settings: `{
+				"url": "http://localhost/test",
+				"secret": "s3cr3t",
+				"signatureHeader": "X-Signature",
+				"timestampHeader": "X-Timestamp",
+				"headers": {"X-Team": "{{ .CommonLabels.team }}", "X-Signature": "overridden"}
+			}`,
+			expHeaders: map[string]string{
+				"X-Team":      "ops",
+				"X-Timestamp": "1000",
+			},
+		}, {
+			name: "Custom headers as lines",
+			settings: `{
+				"url": "http://localhost/test",
+				"secret": "s3cr3t",
+				"headers": "X-Team: {{ .CommonLabels.team }}\nX-Source: grafana\n"
+			}`,
+			expHeaders: map[string]string{
+				"X-Team":              "ops",
+				"X-Source":            "grafana",
+				"X-Grafana-Timestamp": "1000",
+			},
+		}, {
+			name:         "Missing secret",
+			settings:     `{"url": "http://localhost/test"}`,
+			expInitError: `failed to validate receiver "webhook_testing" of type "webhook-hmac": could not find secret property in settings`,
+		}, {
+			name:         "Invalid headers",
+			settings:     `{"url": "http://localhost/test", "secret": "s3cr3t", "headers": "X-Team"}`,
+			expInitError: `failed to validate receiver "webhook_testing" of type "webhook-hmac": invalid headers property in settings: invalid header "X-Team", it must be Name: template`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				Name:     "webhook_testing",
+				Type:     "webhook-hmac",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewHMACWebhookNotifier(m, tmpl)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			pn.now = func() time.Time { return time.Unix(1000, 0) }
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ctx = notify.WithReceiverName(ctx, "my_receiver")
+			ok, err := pn.Notify(ctx, alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			signature := "sha256=" + hmacSignature("s3cr3t", "1000", []byte(payload.Body))
+			signatureHeader := settingsJSON.Get("signatureHeader").MustString(DefaultHMACSignatureHeader)
+			expHeaders := map[string]string{signatureHeader: signature}
+			for k, v := range c.expHeaders {
+				expHeaders[k] = v
+			}
+			require.Equal(t, expHeaders, payload.HttpHeader)
+			require.Equal(t, "http://localhost/test", payload.Url)
+			require.Equal(t, "POST", payload.HttpMethod)
+		})
+	}
+}
+
+func TestHMACSignature(t *testing.T) {
+	// echo -n '1000.{"a":1}' | openssl dgst -sha256 -hmac s3cr3t
+	require.Equal(t, "1c63144b0234fa8e8a61222452549c1a2b39a99c2016cca82643e97397beef0a", hmacSignature("s3cr3t", "1000", []byte(`{"a":1}`)))
+}
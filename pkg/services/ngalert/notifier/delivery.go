@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// deliveryLog records the attempts of the integrations of the contact points to deliver
+// notifications in the notification delivery log and in the metrics.
+type deliveryLog struct {
+	store   store.NotificationDeliveryStore
+	metrics *metrics.Metrics
+	logger  log.Logger
+}
+
+func newDeliveryLog(store store.NotificationDeliveryStore, m *metrics.Metrics) *deliveryLog {
+	if store == nil && m == nil {
+		return nil
+	}
+	return &deliveryLog{
+		store:   store,
+		metrics: m,
+		logger:  log.New("alertmanager.delivery"),
+	}
+}
+
+// wrap returns a notification channel that records the deliveries of the integration.
+func (l *deliveryLog) wrap(orgID int64, receiver string, r *apimodels.PostableGrafanaReceiver, n NotificationChannel) NotificationChannel {
+	if l == nil {
+		return n
+	}
+	return &deliveryRecorder{
+		NotificationChannel: n,
+		log:                 l,
+		orgID:               orgID,
+		receiver:            receiver,
+		integration:         r.Type,
+		integrationName:     r.Name,
+		integrationUID:      r.UID,
+	}
+}
+
+// deliveryRecorder is a notification channel that records the deliveries of the channel it wraps.
+type deliveryRecorder struct {
+	NotificationChannel
+	log             *deliveryLog
+	orgID           int64
+	receiver        string
+	integration     string
+	integrationName string
+	integrationUID  string
+}
+
+// Notify implements the Notifier interface.
+func (r *deliveryRecorder) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	ctx, statusCode := channels.WithStatusCodeRecorder(ctx)
+	start := time.Now()
+	retry, err := r.NotificationChannel.Notify(ctx, as...)
+	duration := time.Since(start)
+
+	cmd := r.newCommand(as, ngmodels.NotificationDeliverySuccess, start)
+	cmd.StatusCode = statusCode()
+	cmd.Duration = duration
+	if err != nil {
+		cmd.Status = ngmodels.NotificationDeliveryFailure
+		cmd.Error = err.Error()
+	}
+
+	if m := r.log.metrics; m != nil {
+		m.NotificationDeliveryDuration.WithLabelValues(strconv.FormatInt(r.orgID, 10), r.receiver, r.integration).Observe(duration.Seconds())
+	}
+	r.record(cmd)
+
+	return retry, err
+}
+
+func (r *deliveryRecorder) newCommand(as []*types.Alert, status ngmodels.NotificationDeliveryStatus, sentAt time.Time) *ngmodels.SaveNotificationDeliveryCommand {
+	cmd := &ngmodels.SaveNotificationDeliveryCommand{
+		OrgID:           r.orgID,
+		Receiver:        r.receiver,
+		Integration:     r.integration,
+		IntegrationName: r.integrationName,
+		IntegrationUID:  r.integrationUID,
+		Fingerprints:    make(ngmodels.AlertFingerprints, 0, len(as)),
+		Status:          status,
+		SentAt:          sentAt,
+	}
+	for _, a := range as {
+		cmd.Fingerprints = append(cmd.Fingerprints, a.Fingerprint().String())
+	}
+	return cmd
+}
+
+// record counts the delivery in the metrics and saves it in the notification delivery log.
+func (r *deliveryRecorder) record(cmd *ngmodels.SaveNotificationDeliveryCommand) {
+	if m := r.log.metrics; m != nil {
+		m.NotificationDeliveries.WithLabelValues(strconv.FormatInt(r.orgID, 10), r.receiver, r.integration, string(cmd.Status)).Inc()
+	}
+
+	if r.log.store != nil {
+		if saveErr := r.log.store.SaveNotificationDelivery(cmd); saveErr != nil {
+			r.log.logger.Error("failed to record notification delivery", "org", r.orgID, "receiver", r.receiver, "integration", r.integration, "err", saveErr)
+		}
+	}
+}
+
+// recordNotSent records that the channel n did not send a notification for the alerts, with the
+// status explaining why, if n records its deliveries.
+func recordNotSent(n NotificationChannel, as []*types.Alert, status ngmodels.NotificationDeliveryStatus) {
+	r, ok := n.(*deliveryRecorder)
+	if !ok {
+		return
+	}
+	r.record(r.newCommand(as, status, time.Now()))
+}
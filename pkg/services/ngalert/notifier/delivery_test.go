@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeDeliveryStore struct {
+	deliveries []*ngmodels.SaveNotificationDeliveryCommand
+}
+
+func (f *fakeDeliveryStore) SaveNotificationDelivery(cmd *ngmodels.SaveNotificationDeliveryCommand) error {
+	f.deliveries = append(f.deliveries, cmd)
+	return nil
+}
+
+func (f *fakeDeliveryStore) ListNotificationDeliveries(_ *ngmodels.ListNotificationDeliveriesQuery) error {
+	return nil
+}
+
+func (f *fakeDeliveryStore) DeleteNotificationDeliveriesBefore(_ time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeChannel struct {
+	err error
+}
+
+func (f *fakeChannel) Notify(_ context.Context, _ ...*types.Alert) (bool, error) {
+	return f.err != nil, f.err
+}
+
+func (f *fakeChannel) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryRecorder(t *testing.T) {
+	st := &fakeDeliveryStore{}
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+	l := newDeliveryLog(st, m)
+	receiver := &apimodels.PostableGrafanaReceiver{UID: "uid", Name: "team-webhook", Type: "webhook"}
+
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	n := l.wrap(1, "team", receiver, &fakeChannel{})
+	retry, err := n.Notify(context.Background(), alert)
+	require.NoError(t, err)
+	require.False(t, retry)
+	require.True(t, n.SendResolved())
+
+	n = l.wrap(1, "team", receiver, &fakeChannel{err: errors.New("connection refused")})
+	retry, err = n.Notify(context.Background(), alert)
+	require.EqualError(t, err, "connection refused")
+	require.True(t, retry)
+
+	require.Len(t, st.deliveries, 2)
+	d := st.deliveries[0]
+	require.Equal(t, int64(1), d.OrgID)
+	require.Equal(t, "team", d.Receiver)
+	require.Equal(t, "webhook", d.Integration)
+	require.Equal(t, "team-webhook", d.IntegrationName)
+	require.Equal(t, "uid", d.IntegrationUID)
+	require.Equal(t, ngmodels.AlertFingerprints{alert.Fingerprint().String()}, d.Fingerprints)
+	require.Equal(t, ngmodels.NotificationDeliverySuccess, d.Status)
+	require.Empty(t, d.Error)
+
+	d = st.deliveries[1]
+	require.Equal(t, ngmodels.NotificationDeliveryFailure, d.Status)
+	require.Equal(t, "connection refused", d.Error)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.NotificationDeliveries.WithLabelValues("1", "team", "webhook", "success")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.NotificationDeliveries.WithLabelValues("1", "team", "webhook", "failure")))
+}
+
+func TestDeliveryLogIsOptional(t *testing.T) {
+	l := newDeliveryLog(nil, nil)
+	require.Nil(t, l)
+
+	n := &fakeChannel{}
+	require.Same(t, n, l.wrap(1, "team", &apimodels.PostableGrafanaReceiver{}, n))
+}
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// LiveAlertSender sends the alerts of the threshold outputs of Grafana Live channel rules
+// to the Alertmanager of their organization.
+type LiveAlertSender struct {
+	moa *MultiOrgAlertmanager
+}
+
+func NewLiveAlertSender(moa *MultiOrgAlertmanager) *LiveAlertSender {
+	return &LiveAlertSender{moa: moa}
+}
+
+func (s *LiveAlertSender) SendAlerts(_ context.Context, orgID int64, alerts []*pipeline.Alert) error {
+	am, err := s.moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	postableAlerts := apimodels.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(alerts))}
+	for _, a := range alerts {
+		postableAlerts.PostableAlerts = append(postableAlerts.PostableAlerts, models.PostableAlert{
+			Annotations: models.LabelSet(a.Annotations),
+			StartsAt:    strfmt.DateTime(a.StartsAt),
+			EndsAt:      strfmt.DateTime(a.EndsAt),
+			Alert: models.Alert{
+				Labels: models.LabelSet(a.Labels),
+			},
+		})
+	}
+	return am.PutAlerts(postableAlerts)
+}
@@ -30,17 +30,22 @@ type MultiOrgAlertmanager struct {
 
 	configStore store.AlertingStore
 	orgStore    store.OrgStore
+	deliveries  *deliveryLog
 
 	orgRegistry *metrics.OrgRegistries
 }
 
-func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore) *MultiOrgAlertmanager {
+// NewMultiOrgAlertmanager returns the Alertmanagers of the organisations. The deliveries of the
+// integrations are recorded in the deliveryStore and in the metrics m, if they are not nil.
+func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore,
+	deliveryStore store.NotificationDeliveryStore, m *metrics.Metrics) *MultiOrgAlertmanager {
 	return &MultiOrgAlertmanager{
 		settings:      cfg,
 		logger:        log.New("multiorg.alertmanager"),
 		alertmanagers: map[int64]*Alertmanager{},
 		configStore:   configStore,
 		orgStore:      orgStore,
+		deliveries:    newDeliveryLog(deliveryStore, m),
 		orgRegistry:   metrics.NewOrgRegistries(),
 	}
 }
@@ -86,7 +91,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(orgIDs []int64) {
 		existing, found := moa.alertmanagers[orgID]
 		if !found {
 			reg := moa.orgRegistry.GetOrCreateOrgRegistry(orgID)
-			am, err := newAlertmanager(orgID, moa.settings, moa.configStore, metrics.NewMetrics(reg))
+			am, err := newAlertmanager(orgID, moa.settings, moa.configStore, metrics.NewMetrics(reg), moa.deliveries)
 			if err != nil {
 				moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "err", err)
 			}
@@ -19,7 +19,7 @@ func TestMultiOrgAlertmanager_SyncAlertmanagersForOrgs(t *testing.T) {
 		orgs: []int64{1, 2, 3},
 	}
 	SyncOrgsPollInterval = 10 * time.Minute // Don't poll in unit tests.
-	mam := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore)
+	mam := NewMultiOrgAlertmanager(&setting.Cfg{DataPath: t.TempDir()}, configStore, orgStore, nil, nil)
 	ctx := context.Background()
 
 	// Ensure that one Alertmanager is created per org.
@@ -50,7 +50,7 @@ func TestMultiOrgAlertmanager_AlertmanagerFor(t *testing.T) {
 	}
 
 	SyncOrgsPollInterval = 10 * time.Minute // Don't poll in unit tests.
-	mam := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore)
+	mam := NewMultiOrgAlertmanager(&setting.Cfg{DataPath: t.TempDir()}, configStore, orgStore, nil, nil)
 	ctx := context.Background()
 
 	// Ensure that one Alertmanagers is created per org.
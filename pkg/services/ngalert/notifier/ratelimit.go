@@ -0,0 +1,227 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// digestTimeout is the maximum time an integration has to send a digest.
+const digestTimeout = time.Minute
+
+// rateLimits keeps the state of the rate limits of the integrations of an Alertmanager, by
+// integration UID, so that it survives configuration changes.
+type rateLimits struct {
+	mtx      sync.Mutex
+	limiters map[string]*rateLimiter
+	clock    clock.Clock
+	logger   log.Logger
+}
+
+func newRateLimits(c clock.Clock, logger log.Logger) *rateLimits {
+	return &rateLimits{
+		limiters: make(map[string]*rateLimiter),
+		clock:    c,
+		logger:   logger,
+	}
+}
+
+// wrap returns a notification channel that enforces the rate limit of the integration, if it has one.
+func (l *rateLimits) wrap(receiver string, uid string, limit *ngmodels.NotificationRateLimit, n NotificationChannel) NotificationChannel {
+	if limit == nil {
+		return n
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	limiter, ok := l.limiters[uid]
+	if !ok {
+		limiter = &rateLimiter{clock: l.clock, logger: l.logger.New("receiver", receiver, "integration", uid)}
+		l.limiters[uid] = limiter
+	}
+	return &rateLimitedChannel{
+		NotificationChannel: n,
+		limiter:             limiter,
+		limit:               *limit,
+		uid:                 uid,
+	}
+}
+
+// prune forgets the rate limits of the integrations that are not in uids. The pending digests
+// of these integrations are sent right away.
+func (l *rateLimits) prune(uids map[string]struct{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for uid, limiter := range l.limiters {
+		if _, ok := uids[uid]; ok {
+			continue
+		}
+		delete(l.limiters, uid)
+		if limiter.stop() {
+			go limiter.flush()
+		}
+	}
+}
+
+// stop stops the timers of the pending digests, the digests are dropped.
+func (l *rateLimits) stop() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for _, limiter := range l.limiters {
+		if limiter.stop() {
+			limiter.logger.Warn("dropping pending digest on shutdown")
+		}
+	}
+}
+
+// rateLimitedChannel is a notification channel that sends at most limit.MaxNotifications
+// notifications per limit.Interval.
+type rateLimitedChannel struct {
+	NotificationChannel
+	limiter *rateLimiter
+	limit   ngmodels.NotificationRateLimit
+	uid     string
+}
+
+// Notify implements the Notifier interface. A notification over the limit is recorded with the
+// dropped or digested status in the notification delivery log and the metrics. It is not an
+// error, so the Alertmanager records it as sent in its notification log: the alerts are not
+// notified again before the repeat interval of the notification policy, even when the
+// notification was dropped.
+func (c *rateLimitedChannel) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	if c.limiter.allow(c.limit) {
+		return c.NotificationChannel.Notify(ctx, as...)
+	}
+
+	if !c.limit.Digest {
+		c.limiter.logger.Warn("rate limit reached, dropping notification", "alerts", len(as))
+		recordNotSent(c.NotificationChannel, as, ngmodels.NotificationDeliveryDropped)
+		return false, nil
+	}
+	c.limiter.logger.Info("rate limit reached, adding notification to the digest", "alerts", len(as))
+	recordNotSent(c.NotificationChannel, as, ngmodels.NotificationDeliveryDigested)
+	c.limiter.add(ctx, c, as)
+	return false, nil
+}
+
+// rateLimiter counts the notifications sent by an integration in the current interval and
+// collects the notifications over the limit in a digest.
+type rateLimiter struct {
+	mtx         sync.Mutex
+	clock       clock.Clock
+	logger      log.Logger
+	windowStart time.Time
+	sent        int
+
+	// pending are the alerts of the digest by fingerprint, the digest is sent by channel
+	// when timer fires at the end of the interval, flushAt.
+	pending     map[model.Fingerprint]*types.Alert
+	receiver    string
+	groupLabels model.LabelSet
+	channel     *rateLimitedChannel
+	flushAt     time.Time
+	timer       *clock.Timer
+}
+
+// allow returns true and counts the notification if the integration has not reached its limit
+// in the current interval.
+func (l *rateLimiter) allow(limit ngmodels.NotificationRateLimit) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.clock.Now()
+	if now.Sub(l.windowStart) >= time.Duration(limit.Interval) {
+		l.windowStart = now
+		l.sent = 0
+	}
+	if l.sent >= limit.MaxNotifications {
+		return false
+	}
+	l.sent++
+	return true
+}
+
+// add adds the alerts of a notification over the limit to the digest, which is sent at the end
+// of the current interval.
+func (l *rateLimiter) add(ctx context.Context, c *rateLimitedChannel, as []*types.Alert) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.pending == nil {
+		l.pending = make(map[model.Fingerprint]*types.Alert, len(as))
+		l.receiver, _ = notify.ReceiverName(ctx)
+		groupLabels, _ := notify.GroupLabels(ctx)
+		l.groupLabels = groupLabels.Clone()
+	} else {
+		// The digest is grouped by the labels common to all its notifications.
+		groupLabels, _ := notify.GroupLabels(ctx)
+		for name, value := range l.groupLabels {
+			if groupLabels[name] != value {
+				delete(l.groupLabels, name)
+			}
+		}
+	}
+	for _, a := range as {
+		l.pending[a.Fingerprint()] = a
+	}
+	// The digest is sent by the latest configuration of the integration.
+	l.channel = c
+
+	if l.timer == nil {
+		l.flushAt = l.windowStart.Add(time.Duration(c.limit.Interval))
+		l.timer = l.clock.AfterFunc(l.flushAt.Sub(l.clock.Now()), l.flush)
+	}
+}
+
+// stop stops the timer of the pending digest, if any, and returns true if there is a pending digest.
+func (l *rateLimiter) stop() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	return len(l.pending) > 0
+}
+
+// flush sends the pending digest. The digest counts as the first notification of the interval
+// that starts when the digest is due.
+func (l *rateLimiter) flush() {
+	l.mtx.Lock()
+	pending, receiver, groupLabels, c, now := l.pending, l.receiver, l.groupLabels, l.channel, l.flushAt
+	l.pending, l.groupLabels, l.channel, l.timer = nil, nil, nil, nil
+	l.windowStart = now
+	l.sent = 1
+	l.mtx.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	alerts := make([]*types.Alert, 0, len(pending))
+	for _, a := range pending {
+		alerts = append(alerts, a)
+	}
+	sort.Sort(types.AlertSlice(alerts))
+
+	ctx, cancel := context.WithTimeout(context.Background(), digestTimeout)
+	defer cancel()
+	ctx = notify.WithReceiverName(ctx, receiver)
+	ctx = notify.WithGroupKey(ctx, "digest:"+c.uid)
+	ctx = notify.WithGroupLabels(ctx, groupLabels)
+	ctx = notify.WithNow(ctx, now)
+
+	l.logger.Debug("sending digest", "alerts", len(alerts))
+	if _, err := c.NotificationChannel.Notify(ctx, alerts...); err != nil {
+		l.logger.Error("failed to send digest", "alerts", len(alerts), "err", err)
+	}
+}
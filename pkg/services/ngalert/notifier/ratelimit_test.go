@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type notification struct {
+	groupKey string
+	alerts   []*types.Alert
+}
+
+type recordingChannel struct {
+	notifications []notification
+}
+
+func (r *recordingChannel) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	key, _ := notify.ExtractGroupKey(ctx)
+	r.notifications = append(r.notifications, notification{groupKey: string(key), alerts: as})
+	return false, nil
+}
+
+func (r *recordingChannel) SendResolved() bool {
+	return true
+}
+
+func notificationContext(receiver, groupKey string, groupLabels model.LabelSet) context.Context {
+	ctx := notify.WithReceiverName(context.Background(), receiver)
+	ctx = notify.WithGroupKey(ctx, groupKey)
+	return notify.WithGroupLabels(ctx, groupLabels)
+}
+
+func TestRateLimitedChannel(t *testing.T) {
+	newAlert := func(name string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": model.LabelValue(name), "team": "a"}}}
+	}
+	a, b, c := newAlert("a"), newAlert("b"), newAlert("c")
+	labels := model.LabelSet{"team": "a"}
+
+	t.Run("notifications are sent until the limit is reached", func(t *testing.T) {
+		mock := clock.NewMock()
+		rec := &recordingChannel{}
+		limit := &ngmodels.NotificationRateLimit{MaxNotifications: 2, Interval: model.Duration(time.Minute)}
+		n := newRateLimits(mock, log.New("test")).wrap("team", "uid", limit, rec)
+
+		for _, alert := range []*types.Alert{a, b, c} {
+			retry, err := n.Notify(notificationContext("team", "group", labels), alert)
+			require.NoError(t, err)
+			require.False(t, retry)
+		}
+		require.Len(t, rec.notifications, 2)
+
+		// A new interval starts.
+		mock.Add(time.Minute)
+		_, err := n.Notify(notificationContext("team", "group", labels), c)
+		require.NoError(t, err)
+		require.Len(t, rec.notifications, 3)
+		require.Equal(t, []*types.Alert{c}, rec.notifications[2].alerts)
+	})
+
+	t.Run("notifications over the limit are sent as a digest", func(t *testing.T) {
+		mock := clock.NewMock()
+		rec := &recordingChannel{}
+		limit := &ngmodels.NotificationRateLimit{MaxNotifications: 1, Interval: model.Duration(time.Minute), Digest: true}
+		n := newRateLimits(mock, log.New("test")).wrap("team", "uid", limit, rec)
+
+		_, err := n.Notify(notificationContext("team", "group-a", labels), a)
+		require.NoError(t, err)
+		_, err = n.Notify(notificationContext("team", "group-b", model.LabelSet{"team": "a", "alertname": "b"}), b)
+		require.NoError(t, err)
+		_, err = n.Notify(notificationContext("team", "group-c", labels), c, b)
+		require.NoError(t, err)
+		require.Len(t, rec.notifications, 1)
+
+		mock.Add(30 * time.Second)
+		require.Len(t, rec.notifications, 1)
+
+		mock.Add(30 * time.Second)
+		require.Len(t, rec.notifications, 2)
+		digest := rec.notifications[1]
+		require.Equal(t, "digest:uid", digest.groupKey)
+		require.Equal(t, []*types.Alert{b, c}, digest.alerts)
+
+		// The digest counts as the first notification of the new interval.
+		_, err = n.Notify(notificationContext("team", "group-a", labels), a)
+		require.NoError(t, err)
+		require.Len(t, rec.notifications, 2)
+	})
+
+	t.Run("rate limits survive configuration changes", func(t *testing.T) {
+		mock := clock.NewMock()
+		limits := newRateLimits(mock, log.New("test"))
+		limit := &ngmodels.NotificationRateLimit{MaxNotifications: 1, Interval: model.Duration(time.Minute)}
+
+		rec := &recordingChannel{}
+		_, err := limits.wrap("team", "uid", limit, rec).Notify(notificationContext("team", "group", labels), a)
+		require.NoError(t, err)
+
+		_, err = limits.wrap("team", "uid", limit, rec).Notify(notificationContext("team", "group", labels), a)
+		require.NoError(t, err)
+		require.Len(t, rec.notifications, 1)
+
+		limits.prune(map[string]struct{}{})
+		_, err = limits.wrap("team", "uid", limit, rec).Notify(notificationContext("team", "group", labels), a)
+		require.NoError(t, err)
+		require.Len(t, rec.notifications, 2)
+	})
+
+	t.Run("integrations without rate limit are not wrapped", func(t *testing.T) {
+		rec := &recordingChannel{}
+		require.Equal(t, rec, newRateLimits(clock.NewMock(), log.New("test")).wrap("team", "uid", nil, rec))
+	})
+}
+
+func TestRateLimitedChannel_RecordsNotificationsNotSent(t *testing.T) {
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}}}
+	receiver := &apimodels.PostableGrafanaReceiver{UID: "uid", Name: "team-webhook", Type: "webhook"}
+
+	for _, tc := range []struct {
+		digest bool
+		status ngmodels.NotificationDeliveryStatus
+	}{
+		{digest: false, status: ngmodels.NotificationDeliveryDropped},
+		{digest: true, status: ngmodels.NotificationDeliveryDigested},
+	} {
+		t.Run(string(tc.status), func(t *testing.T) {
+			st := &fakeDeliveryStore{}
+			m := metrics.NewMetrics(prometheus.NewRegistry())
+			limits := newRateLimits(clock.NewMock(), log.New("test"))
+			defer limits.stop()
+			limit := &ngmodels.NotificationRateLimit{MaxNotifications: 1, Interval: model.Duration(time.Minute), Digest: tc.digest}
+			n := limits.wrap("team", "uid", limit, newDeliveryLog(st, m).wrap(1, "team", receiver, &fakeChannel{}))
+
+			for i := 0; i < 2; i++ {
+				_, err := n.Notify(notificationContext("team", "group", nil), alert)
+				require.NoError(t, err)
+			}
+
+			require.Len(t, st.deliveries, 2)
+			require.Equal(t, ngmodels.NotificationDeliverySuccess, st.deliveries[0].Status)
+			require.Equal(t, tc.status, st.deliveries[1].Status)
+			require.Equal(t, ngmodels.AlertFingerprints{alert.Fingerprint().String()}, st.deliveries[1].Fingerprints)
+			require.Equal(t, 1.0, testutil.ToFloat64(m.NotificationDeliveries.WithLabelValues("1", "team", "webhook", string(tc.status))))
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+var (
+	// SilenceScheduleSyncInterval is how often the silence schedules are materialized into silences.
+	SilenceScheduleSyncInterval = 1 * time.Minute
+	// SilenceScheduleLookahead is how long ahead of time the silences of the schedules are created.
+	SilenceScheduleLookahead = 24 * time.Hour
+)
+
+const silenceScheduleCreatedByPrefix = "silence schedule "
+
+// SilenceScheduleCreatedBy is the creator of the silences of the silence schedule with the given UID.
+func SilenceScheduleCreatedBy(uid string) string {
+	return silenceScheduleCreatedByPrefix + uid
+}
+
+// SilenceScheduler materializes the windows of the silence schedules into silences of the
+// Alertmanagers of their organisations, and expires the silences that are no longer planned,
+// such as those of deleted schedules.
+type SilenceScheduler struct {
+	store  store.SilenceScheduleStore
+	moa    *MultiOrgAlertmanager
+	logger log.Logger
+}
+
+func NewSilenceScheduler(store store.SilenceScheduleStore, moa *MultiOrgAlertmanager) *SilenceScheduler {
+	return &SilenceScheduler{
+		store:  store,
+		moa:    moa,
+		logger: log.New("ngalert.silence-scheduler"),
+	}
+}
+
+func (s *SilenceScheduler) Run(ctx context.Context) error {
+	s.logger.Info("starting silence scheduler")
+	for {
+		if err := s.Sync(time.Now()); err != nil {
+			s.logger.Error("failed to synchronize silence schedules", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(SilenceScheduleSyncInterval):
+		}
+	}
+}
+
+// Sync creates the silences of the windows of every silence schedule that end after now and start
+// before the lookahead, and expires the silences of the schedules that are no longer planned.
+func (s *SilenceScheduler) Sync(now time.Time) error {
+	schedules, err := s.store.ListSilenceSchedules(0)
+	if err != nil {
+		return err
+	}
+	byOrg := make(map[int64][]*models.SilenceSchedule)
+	for _, schedule := range schedules {
+		byOrg[schedule.OrgID] = append(byOrg[schedule.OrgID], schedule)
+	}
+
+	s.moa.alertmanagersMtx.RLock()
+	ams := make(map[int64]*Alertmanager, len(s.moa.alertmanagers))
+	for orgID, am := range s.moa.alertmanagers {
+		ams[orgID] = am
+	}
+	s.moa.alertmanagersMtx.RUnlock()
+
+	for orgID, am := range ams {
+		if am == nil || !am.Ready() {
+			continue
+		}
+		if err := syncSilenceSchedules(am, byOrg[orgID], now, s.logger.New("org", orgID)); err != nil {
+			s.logger.Error("failed to synchronize silence schedules of org", "org", orgID, "err", err)
+		}
+	}
+	return nil
+}
+
+// syncSilenceSchedules synchronizes the silences of the schedules of an organisation with its Alertmanager.
+// A silence of a schedule is identified by its creator, its matchers, its end and its start if it is pending.
+func syncSilenceSchedules(am *Alertmanager, schedules []*models.SilenceSchedule, now time.Time, logger log.Logger) error {
+	planned := make(map[string]*apimodels.PostableSilence)
+	for _, schedule := range schedules {
+		matchers, err := schedule.ParseMatchers()
+		if err != nil {
+			logger.Error("invalid silence schedule", "uid", schedule.UID, "err", err)
+			continue
+		}
+		windows, err := schedule.Windows(now, now.Add(SilenceScheduleLookahead))
+		if err != nil {
+			logger.Error("failed to compute the windows of silence schedule", "uid", schedule.UID, "err", err)
+			continue
+		}
+		for _, w := range windows {
+			ps := silenceOfWindow(schedule, matchers, w)
+			planned[silenceKey(&ps.Silence, now)] = ps
+		}
+	}
+
+	silences, err := am.ListSilences(nil)
+	if err != nil {
+		return err
+	}
+	for _, sil := range silences {
+		if sil.CreatedBy == nil || !strings.HasPrefix(*sil.CreatedBy, silenceScheduleCreatedByPrefix) {
+			continue
+		}
+		if sil.Status != nil && sil.Status.State != nil && *sil.Status.State == amv2.SilenceStatusStateExpired {
+			continue
+		}
+		key := silenceKey(&sil.Silence, now)
+		if _, ok := planned[key]; ok {
+			// the silence exists, it is not created again, and duplicates, e.g. created by another
+			// instance at the same time, are expired
+			delete(planned, key)
+			continue
+		}
+		if err := am.DeleteSilence(*sil.ID); err != nil && err != ErrSilenceNotFound {
+			logger.Error("failed to expire silence of silence schedule", "id", *sil.ID, "created_by", *sil.CreatedBy, "err", err)
+		}
+	}
+
+	keys := make([]string, 0, len(planned))
+	for key := range planned {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ps := planned[key]
+		if _, err := am.CreateSilence(ps); err != nil {
+			logger.Error("failed to create silence of silence schedule", "created_by", *ps.CreatedBy, "err", err)
+		}
+	}
+	return nil
+}
+
+func silenceOfWindow(schedule *models.SilenceSchedule, matchers labels.Matchers, w models.TimeWindow) *apimodels.PostableSilence {
+	comment := schedule.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Created by the silence schedule %s", schedule.UID)
+	}
+	createdBy := SilenceScheduleCreatedBy(schedule.UID)
+	startsAt := strfmt.DateTime(w.Start)
+	endsAt := strfmt.DateTime(w.End)
+	ps := &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+		},
+	}
+	for _, m := range matchers {
+		name, value := m.Name, m.Value
+		isRegex := m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp
+		isEqual := m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp
+		ps.Matchers = append(ps.Matchers, &amv2.Matcher{Name: &name, Value: &value, IsRegex: &isRegex, IsEqual: &isEqual})
+	}
+	return ps
+}
+
+// silenceKey identifies a silence of a silence schedule. The start is only part of it when it is
+// after now, because the Alertmanager changes the start of the silences that start in the past.
+func silenceKey(s *amv2.Silence, now time.Time) string {
+	matchers := make([]string, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		if m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			continue
+		}
+		t := labels.MatchEqual
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		switch {
+		case *m.IsRegex && isEqual:
+			t = labels.MatchRegexp
+		case *m.IsRegex:
+			t = labels.MatchNotRegexp
+		case !isEqual:
+			t = labels.MatchNotEqual
+		}
+		matchers = append(matchers, (&labels.Matcher{Type: t, Name: *m.Name, Value: *m.Value}).String())
+	}
+	sort.Strings(matchers)
+
+	var createdBy string
+	if s.CreatedBy != nil {
+		createdBy = *s.CreatedBy
+	}
+	var start, end int64
+	if s.StartsAt != nil && time.Time(*s.StartsAt).After(now) {
+		start = time.Time(*s.StartsAt).Unix()
+	}
+	if s.EndsAt != nil {
+		end = time.Time(*s.EndsAt).Unix()
+	}
+	return fmt.Sprintf("%s|%s|%d|%d", createdBy, strings.Join(matchers, ","), start, end)
+}
@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSyncSilenceSchedules(t *testing.T) {
+	am := setupAMTest(t)
+	logger := log.New("silence-scheduler-test")
+
+	now := time.Now()
+	schedule := &models.SilenceSchedule{
+		OrgID:    1,
+		UID:      "nightly",
+		Matchers: []string{`env="prod"`},
+		Cron:     "0 */2 * * *",
+		Duration: time.Hour,
+	}
+	windows, err := schedule.Windows(now, now.Add(SilenceScheduleLookahead))
+	require.NoError(t, err)
+
+	activeSilences := func(t *testing.T) apimodels.GettableSilences {
+		t.Helper()
+		silences, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		active := apimodels.GettableSilences{}
+		for _, s := range silences {
+			if *s.Status.State != "expired" {
+				active = append(active, s)
+			}
+		}
+		return active
+	}
+
+	t.Run("the windows of the schedules are materialized into silences", func(t *testing.T) {
+		require.NoError(t, syncSilenceSchedules(am, []*models.SilenceSchedule{schedule}, now, logger))
+		silences := activeSilences(t)
+		require.Len(t, silences, len(windows))
+		for _, s := range silences {
+			require.Equal(t, SilenceScheduleCreatedBy("nightly"), *s.CreatedBy)
+			require.Len(t, s.Matchers, 1)
+			require.Equal(t, "env", *s.Matchers[0].Name)
+			require.Equal(t, "prod", *s.Matchers[0].Value)
+		}
+	})
+
+	t.Run("existing silences are not created again", func(t *testing.T) {
+		require.NoError(t, syncSilenceSchedules(am, []*models.SilenceSchedule{schedule}, now.Add(time.Second), logger))
+		require.Len(t, activeSilences(t), len(windows))
+	})
+
+	t.Run("silences that are not created by schedules are left alone", func(t *testing.T) {
+		comment, createdBy := "maintenance", "admin"
+		startsAt, endsAt := strfmt.DateTime(now), strfmt.DateTime(now.Add(time.Hour))
+		ps := &apimodels.PostableSilence{}
+		ps.Comment, ps.CreatedBy, ps.StartsAt, ps.EndsAt = &comment, &createdBy, &startsAt, &endsAt
+		name, value, isRegex := "env", "dev", false
+		ps.Matchers = append(ps.Matchers, &amv2.Matcher{Name: &name, Value: &value, IsRegex: &isRegex})
+		_, err := am.CreateSilence(ps)
+		require.NoError(t, err)
+
+		require.NoError(t, syncSilenceSchedules(am, nil, now, logger))
+		silences := activeSilences(t)
+		require.Len(t, silences, 1)
+		require.Equal(t, "admin", *silences[0].CreatedBy)
+	})
+
+	t.Run("silences of changed schedules are replaced", func(t *testing.T) {
+		changed := *schedule
+		changed.Matchers = []string{`env="staging"`}
+		require.NoError(t, syncSilenceSchedules(am, []*models.SilenceSchedule{&changed}, now, logger))
+		count := 0
+		for _, s := range activeSilences(t) {
+			if *s.CreatedBy == SilenceScheduleCreatedBy("nightly") {
+				require.Equal(t, "staging", *s.Matchers[0].Value)
+				count++
+			}
+		}
+		require.Equal(t, len(windows), count)
+	})
+}
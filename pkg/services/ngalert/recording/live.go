@@ -0,0 +1,51 @@
+package recording
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// LiveStreamID is the managed stream the results of recording rules are pushed to,
+// on the channel stream/recording_rules/<rule uid>.
+const LiveStreamID = "recording_rules"
+
+// LiveWriter pushes the results of recording rules to a Grafana Live managed stream.
+type LiveWriter struct {
+	runner *managedstream.Runner
+}
+
+func NewLiveWriter(runner *managedstream.Runner) *LiveWriter {
+	return &LiveWriter{runner: runner}
+}
+
+// Write pushes a frame with a time field and a field for each number, named after
+// the metric of the rule. Metric names can contain colons, which are not allowed in
+// channel paths, so the path of the channel is the UID of the rule.
+func (w *LiveWriter) Write(_ context.Context, rule *ngmodels.AlertRule, ts time.Time, numbers []eval.NumberValueCapture) error {
+	if len(numbers) == 0 {
+		return nil
+	}
+	stream, err := w.runner.GetOrCreateStream(rule.OrgID, LiveStreamID)
+	if err != nil {
+		return err
+	}
+	return stream.Push(rule.UID, liveFrame(rule, ts, numbers))
+}
+
+func liveFrame(rule *ngmodels.AlertRule, ts time.Time, numbers []eval.NumberValueCapture) *data.Frame {
+	fields := make([]*data.Field, 0, len(numbers)+1)
+	fields = append(fields, data.NewField("time", nil, []time.Time{ts}))
+	for _, n := range numbers {
+		lbs := seriesLabels(rule, n)
+		delete(lbs, model.MetricNameLabel)
+		fields = append(fields, data.NewField(rule.Record, lbs, []*float64{n.Value}))
+	}
+	return data.NewFrame(rule.Record, fields...)
+}
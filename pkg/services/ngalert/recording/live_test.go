@@ -0,0 +1,46 @@
+package recording
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLiveFrame(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		OrgID:  1,
+		UID:    "rule",
+		Record: "job:requests:rate5m",
+		Labels: map[string]string{"team": "a"},
+	}
+	ts := time.Unix(1000, 0)
+	numbers := []eval.NumberValueCapture{
+		{Var: "B", Labels: data.Labels{"job": "api"}, Value: ptr.Float64(3)},
+		{Var: "B", Labels: data.Labels{"job": "db"}, Value: nil},
+	}
+
+	expected := data.NewFrame("job:requests:rate5m",
+		data.NewField("time", nil, []time.Time{ts}),
+		data.NewField("job:requests:rate5m", data.Labels{"job": "api", "team": "a"}, []*float64{ptr.Float64(3)}),
+		data.NewField("job:requests:rate5m", data.Labels{"job": "db", "team": "a"}, []*float64{nil}),
+	)
+	require.Equal(t, expected, liveFrame(rule, ts, numbers))
+}
+
+func TestNewWriter_WithoutLive(t *testing.T) {
+	w, err := NewWriter(&setting.Cfg{}, nil, log.New("test"))
+	require.NoError(t, err, "Live is only needed when a recording rule is evaluated")
+
+	rule := &ngmodels.AlertRule{OrgID: 1, UID: "rule", Record: "job:requests:rate5m"}
+	numbers := []eval.NumberValueCapture{{Var: "B", Value: ptr.Float64(3)}}
+	require.Error(t, w.Write(context.Background(), rule, time.Unix(1000, 0), numbers))
+}
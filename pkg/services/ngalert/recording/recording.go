@@ -0,0 +1,64 @@
+// Package recording writes the results of recording rules, which precompute the result
+// of their condition on their interval, to a time series store.
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Writer writes the numbers a recording rule evaluated to at a given time.
+type Writer interface {
+	Write(ctx context.Context, rule *ngmodels.AlertRule, ts time.Time, numbers []eval.NumberValueCapture) error
+}
+
+// NewWriter returns the Writer for the output configured in the unified alerting settings.
+func NewWriter(cfg *setting.Cfg, runner *managedstream.Runner, logger log.Logger) (Writer, error) {
+	switch cfg.RecordingRulesOutput {
+	case "", "live":
+		if runner == nil {
+			// Live is only needed once a recording rule is evaluated, which then fails.
+			logger.Debug("grafana live is not available, the results of recording rules cannot be written")
+			return unavailableWriter{err: errors.New("grafana live is required to write the results of recording rules to a managed stream")}, nil
+		}
+		return NewLiveWriter(runner), nil
+	case "remote_write":
+		return NewRemoteWriter(cfg.RecordingRulesRemoteWriteURL, cfg.RecordingRulesRemoteWriteUser, cfg.RecordingRulesRemoteWritePassword, logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported recording rules output %q", cfg.RecordingRulesOutput)
+	}
+}
+
+// unavailableWriter is the Writer of an output that is not available. It fails to write.
+type unavailableWriter struct {
+	err error
+}
+
+func (w unavailableWriter) Write(context.Context, *ngmodels.AlertRule, time.Time, []eval.NumberValueCapture) error {
+	return w.err
+}
+
+// seriesLabels returns the labels of the series a number is written to: the labels of
+// the number, overridden by the labels of the rule, and the metric name of the rule.
+func seriesLabels(rule *ngmodels.AlertRule, n eval.NumberValueCapture) data.Labels {
+	lbs := make(data.Labels, len(n.Labels)+len(rule.Labels)+1)
+	for k, v := range n.Labels {
+		lbs[k] = v
+	}
+	for k, v := range rule.Labels {
+		lbs[k] = v
+	}
+	lbs[model.MetricNameLabel] = rule.Record
+	return lbs
+}
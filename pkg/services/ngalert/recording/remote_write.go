@@ -0,0 +1,103 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const remoteWriteTimeout = 10 * time.Second
+
+// RemoteWriter sends the results of recording rules to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+	log      log.Logger
+}
+
+func NewRemoteWriter(url, user, password string, logger log.Logger) *RemoteWriter {
+	return &RemoteWriter{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: remoteWriteTimeout},
+		log:      logger,
+	}
+}
+
+// Write sends a sample for each number that has a value. Null numbers are skipped.
+func (w *RemoteWriter) Write(ctx context.Context, rule *ngmodels.AlertRule, ts time.Time, numbers []eval.NumberValueCapture) error {
+	req := writeRequest(rule, ts, numbers)
+	if len(req.Timeseries) == 0 {
+		return nil
+	}
+
+	b, err := req.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal remote write request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(snappy.Encode(nil, b)))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.user != "" {
+		httpReq.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("failed to close remote write response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func writeRequest(rule *ngmodels.AlertRule, ts time.Time, numbers []eval.NumberValueCapture) *prompb.WriteRequest {
+	req := &prompb.WriteRequest{Timeseries: make([]prompb.TimeSeries, 0, len(numbers))}
+	for _, n := range numbers {
+		if n.Value == nil {
+			continue
+		}
+		lbs := seriesLabels(rule, n)
+		series := prompb.TimeSeries{
+			Labels:  make([]prompb.Label, 0, len(lbs)),
+			Samples: []prompb.Sample{{Value: *n.Value, Timestamp: ts.UnixNano() / int64(time.Millisecond)}},
+		}
+		for k, v := range lbs {
+			series.Labels = append(series.Labels, prompb.Label{Name: k, Value: v})
+		}
+		// remote write requires the labels to be sorted by name
+		sort.Slice(series.Labels, func(i, j int) bool {
+			return series.Labels[i].Name < series.Labels[j].Name
+		})
+		req.Timeseries = append(req.Timeseries, series)
+	}
+	return req
+}
@@ -0,0 +1,80 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRemoteWriter(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		OrgID:  1,
+		UID:    "rule",
+		Record: "job:requests:rate5m",
+		Labels: map[string]string{"team": "a"},
+	}
+	ts := time.Unix(1000, 0)
+	numbers := []eval.NumberValueCapture{
+		{Var: "B", Labels: data.Labels{"job": "api", "team": "b"}, Value: ptr.Float64(3)},
+		{Var: "B", Labels: data.Labels{"job": "db"}, Value: nil},
+	}
+
+	var received *prompb.WriteRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, received.Unmarshal(b))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	w := NewRemoteWriter(srv.URL, "user", "password", log.New("test"))
+	require.NoError(t, w.Write(context.Background(), rule, ts, numbers))
+
+	expected := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "job:requests:rate5m"},
+					{Name: "job", Value: "api"},
+					{Name: "team", Value: "a"},
+				},
+				Samples: []prompb.Sample{{Value: 3, Timestamp: 1000000}},
+			},
+		},
+	}
+	require.Equal(t, expected, received)
+
+	t.Run("returns an error when the endpoint fails", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "out of order sample", http.StatusBadRequest)
+		}))
+		t.Cleanup(failing.Close)
+
+		w := NewRemoteWriter(failing.URL, "", "", log.New("test"))
+		err := w.Write(context.Background(), rule, ts, numbers)
+		require.EqualError(t, err, "remote write endpoint returned status 400: out of order sample\n")
+	})
+}
@@ -0,0 +1,415 @@
+// Package ruletest runs unit tests of Grafana managed alert rules, in the manner of promtool test rules.
+//
+// A test file holds rule groups in the format of the ruler API, synthetic input series and the
+// alert instances expected at given times. The rules are evaluated with expr and eval against the
+// input series rather than data sources, and their states are computed by a dry run state.Manager,
+// so that For, NoData and Error apply as they do in the scheduler.
+//
+// The queries of the rules do not use their data sources, which are looked up on the bus: the
+// tests must not run in a Grafana server, where they would replace the data sources of the bus.
+package ruletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultEvaluationInterval = time.Minute
+	testOrgID                 = 1
+	testNamespaceUID          = "ruletest"
+)
+
+// testFile is the content of a test file.
+type testFile struct {
+	// RuleFiles are files of rule groups, relative to the test file. A file holds a rule group
+	// or a list of rule groups.
+	RuleFiles  []string                            `json:"rule_files"`
+	RuleGroups []apimodels.PostableRuleGroupConfig `json:"rule_groups"`
+	// EvaluationInterval is the interval of the rule groups that have none, 1m by default.
+	EvaluationInterval model.Duration `json:"evaluation_interval"`
+	Tests              []testGroup    `json:"tests"`
+}
+
+// testGroup is a test with its own input series, the states of the rules are not shared between tests.
+type testGroup struct {
+	Name string `json:"name"`
+	// Interval is the interval between the values of the input series, the evaluation interval by default.
+	Interval       model.Duration  `json:"interval"`
+	InputSeries    []inputSeries   `json:"input_series"`
+	AlertRuleTests []alertRuleTest `json:"alert_rule_test"`
+}
+
+// inputSeries is a series returned by the queries with the refID, of every rule or only of the rule
+// with the given title. Its labels and values use the notation of promtool, e.g. cpu{host="a"} and
+// "1+1x3 _ stale". The metric name is the name of the series, it is not one of its labels.
+type inputSeries struct {
+	Rule   string `json:"rule"`
+	RefID  string `json:"ref_id"`
+	Series string `json:"series"`
+	Values string `json:"values"`
+}
+
+// alertRuleTest asserts on the instances of the rule with the title alertname that are in the state,
+// Alerting by default, after the last evaluation at or before the evaluation time.
+type alertRuleTest struct {
+	EvalTime  model.Duration `json:"eval_time"`
+	Alertname string         `json:"alertname"`
+	State     string         `json:"state"`
+	ExpAlerts []expAlert     `json:"exp_alerts"`
+}
+
+// expAlert is an expected alert instance. The labels added to every instance, such as alertname,
+// are not part of its labels.
+type expAlert struct {
+	ExpLabels      map[string]string `json:"exp_labels"`
+	ExpAnnotations map[string]string `json:"exp_annotations"`
+}
+
+// Result is the outcome of the tests of a test file, they passed if there are no failures.
+type Result struct {
+	Filename string
+	Failures []string
+}
+
+// Passed returns true if every test passed.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// RunFile loads the rules and the tests of the test file and runs the tests. An error is returned
+// if the file or its rules cannot be loaded.
+func RunFile(filename string) (*Result, error) {
+	f := &testFile{}
+	if err := decodeFile(filename, f); err != nil {
+		return nil, err
+	}
+	if f.EvaluationInterval == 0 {
+		f.EvaluationInterval = model.Duration(defaultEvaluationInterval)
+	}
+
+	groups := f.RuleGroups
+	for _, rf := range f.RuleFiles {
+		if !filepath.IsAbs(rf) {
+			rf = filepath.Join(filepath.Dir(filename), rf)
+		}
+		g, err := loadRuleGroups(rf)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g...)
+	}
+
+	rules, err := toAlertRules(groups, time.Duration(f.EvaluationInterval))
+	if err != nil {
+		return nil, err
+	}
+
+	bus.AddHandler("ruletest", func(q *models.GetDataSourceQuery) error {
+		q.Result = &models.DataSource{Id: q.Id, Uid: q.Uid, OrgId: q.OrgId, Name: q.Uid, Type: datasourceType}
+		return nil
+	})
+
+	result := &Result{Filename: filename}
+	for i, t := range f.Tests {
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("test %d", i+1)
+		}
+		if t.Interval == 0 {
+			t.Interval = f.EvaluationInterval
+		}
+		result.Failures = append(result.Failures, t.run(rules)...)
+	}
+	return result, nil
+}
+
+// decodeFile decodes a YAML or JSON file. The YAML is converted to JSON, so that the rules are
+// decoded with the JSON decoders of the ruler API.
+func decodeFile(filename string, v interface{}) error {
+	// nolint:gosec
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var content interface{}
+	if err := yaml.Unmarshal(b, &content); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return nil
+}
+
+func loadRuleGroups(filename string) ([]apimodels.PostableRuleGroupConfig, error) {
+	var groups []apimodels.PostableRuleGroupConfig
+	if err := decodeFile(filename, &groups); err == nil {
+		return groups, nil
+	}
+	var group apimodels.PostableRuleGroupConfig
+	if err := decodeFile(filename, &group); err != nil {
+		return nil, err
+	}
+	return []apimodels.PostableRuleGroupConfig{group}, nil
+}
+
+// toAlertRules returns the alert rules of the rule groups. Recording rules are left out.
+func toAlertRules(groups []apimodels.PostableRuleGroupConfig, evaluationInterval time.Duration) ([]*ngmodels.AlertRule, error) {
+	var rules []*ngmodels.AlertRule
+	titles := make(map[string]struct{})
+	for _, g := range groups {
+		interval := time.Duration(g.Interval)
+		if interval == 0 {
+			interval = evaluationInterval
+		}
+		for _, r := range g.Rules {
+			if r.GrafanaManagedAlert == nil {
+				return nil, fmt.Errorf("rule group %s: only Grafana managed rules can be tested", g.Name)
+			}
+			if r.GrafanaManagedAlert.Record != "" {
+				continue
+			}
+			if _, ok := titles[r.GrafanaManagedAlert.Title]; ok {
+				return nil, fmt.Errorf("rule group %s: the title %q is not unique", g.Name, r.GrafanaManagedAlert.Title)
+			}
+			titles[r.GrafanaManagedAlert.Title] = struct{}{}
+
+			rule := &ngmodels.AlertRule{
+				OrgID:           testOrgID,
+				Title:           r.GrafanaManagedAlert.Title,
+				Condition:       r.GrafanaManagedAlert.Condition,
+				Data:            r.GrafanaManagedAlert.Data,
+				UID:             r.GrafanaManagedAlert.UID,
+				NamespaceUID:    testNamespaceUID,
+				RuleGroup:       g.Name,
+				IntervalSeconds: int64(interval.Seconds()),
+				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				DependsOn:       r.GrafanaManagedAlert.DependsOn,
+				Evaluation:      r.GrafanaManagedAlert.Evaluation,
+			}
+			if rule.UID == "" {
+				rule.UID = rule.Title
+			}
+			if rule.NoDataState == "" {
+				rule.NoDataState = ngmodels.NoData
+			}
+			if rule.ExecErrState == "" {
+				rule.ExecErrState = ngmodels.AlertingErrState
+			}
+			if r.ApiRuleNode != nil {
+				rule.For = time.Duration(r.ApiRuleNode.For)
+				rule.Labels = r.ApiRuleNode.Labels
+				rule.Annotations = r.ApiRuleNode.Annotations
+			}
+			if rule.IntervalSeconds <= 0 {
+				return nil, fmt.Errorf("rule group %s: the interval must be at least one second", g.Name)
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// run evaluates the rules from the start of the input series until the last evaluation time of
+// the test, and returns the failed assertions.
+func (t testGroup) run(rules []*ngmodels.AlertRule) []string {
+	failf := func(format string, args ...interface{}) string {
+		return fmt.Sprintf("%s: %s", t.Name, fmt.Sprintf(format, args...))
+	}
+
+	start := time.Unix(0, 0).UTC()
+	var allSeries []*series
+	for _, in := range t.InputSeries {
+		s, err := parseInputSeries(in, start, time.Duration(t.Interval))
+		if err != nil {
+			return []string{failf("%s", err)}
+		}
+		allSeries = append(allSeries, s)
+	}
+
+	var failures []string
+	rulesByTitle := make(map[string]*ngmodels.AlertRule, len(rules))
+	seriesByRule := make(map[string][]*series, len(rules))
+	for _, rule := range rules {
+		rulesByTitle[rule.Title] = rule
+		for _, s := range allSeries {
+			if s.rule == "" || s.rule == rule.Title {
+				seriesByRule[rule.UID] = append(seriesByRule[rule.UID], s)
+			}
+		}
+		for _, q := range rule.Data {
+			if isExpr, _ := q.IsExpression(); isExpr {
+				continue
+			}
+			if !hasSeries(seriesByRule[rule.UID], q.RefID) {
+				failures = append(failures, failf("no input series for the query %s of the rule %q", q.RefID, rule.Title))
+			}
+		}
+	}
+
+	tests := make([]alertRuleTest, 0, len(t.AlertRuleTests))
+	for _, at := range t.AlertRuleTests {
+		if _, ok := rulesByTitle[at.Alertname]; !ok {
+			failures = append(failures, failf("no rule with the title %q", at.Alertname))
+			continue
+		}
+		if _, err := parseState(at.State); err != nil {
+			failures = append(failures, failf("%s", err))
+			continue
+		}
+		tests = append(tests, at)
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].EvalTime < tests[j].EvalTime })
+	if len(tests) == 0 {
+		return nil
+	}
+
+	step := time.Duration(rules[0].IntervalSeconds) * time.Second
+	for _, rule := range rules[1:] {
+		step = gcd(step, time.Duration(rule.IntervalSeconds)*time.Second)
+	}
+
+	logger := log.New("ngalert.ruletest")
+	evaluator := eval.Evaluator{Cfg: &setting.Cfg{ExpressionsEnabled: true}, Log: logger}
+	manager := state.NewDryRunManager(logger)
+	last := time.Duration(tests[len(tests)-1].EvalTime)
+	for ts := time.Duration(0); ts <= last; ts += step {
+		now := start.Add(ts)
+		for _, rule := range rules {
+			if ts%(time.Duration(rule.IntervalSeconds)*time.Second) != 0 {
+				continue
+			}
+			cond := ngmodels.Condition{
+				Condition:        rule.Condition,
+				OrgID:            rule.OrgID,
+				Data:             rule.Data,
+				LoadedDimensions: manager.GetLoadedDimensions(rule),
+			}
+			results, err := evaluator.ConditionEval(&cond, now, mockedData{series: seriesByRule[rule.UID]})
+			if err != nil {
+				return append(failures, failf("failed to evaluate the rule %q at %s: %s", rule.Title, ts, err))
+			}
+			manager.ProcessEvalResults(rule, results)
+		}
+
+		for len(tests) > 0 && time.Duration(tests[0].EvalTime) < ts+step {
+			if f := assertAlerts(manager, rulesByTitle[tests[0].Alertname], tests[0]); f != "" {
+				failures = append(failures, failf("%s", f))
+			}
+			tests = tests[1:]
+		}
+	}
+	return failures
+}
+
+func hasSeries(series []*series, refID string) bool {
+	for _, s := range series {
+		if s.refID == refID {
+			return true
+		}
+	}
+	return false
+}
+
+func gcd(a, b time.Duration) time.Duration {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func parseState(s string) (eval.State, error) {
+	if s == "" {
+		return eval.Alerting, nil
+	}
+	for st := eval.Normal; st <= eval.Suppressed; st++ {
+		if strings.EqualFold(st.String(), s) {
+			return st, nil
+		}
+	}
+	return 0, errors.New("state must be one of Normal, Alerting, Pending, NoData, Error or Suppressed: " + s)
+}
+
+// assertAlerts compares the instances of the rule that are in the state of the test with the
+// expected alerts, regardless of their order. It returns a description of the difference, if any.
+func assertAlerts(manager *state.Manager, rule *ngmodels.AlertRule, at alertRuleTest) string {
+	want, _ := parseState(at.State)
+
+	got := make([]string, 0)
+	for _, s := range manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+		if s.State != want {
+			continue
+		}
+		got = append(got, alertString(s.Labels, s.Annotations))
+	}
+	exp := make([]string, 0, len(at.ExpAlerts))
+	for _, a := range at.ExpAlerts {
+		exp = append(exp, alertString(a.ExpLabels, a.ExpAnnotations))
+	}
+	sort.Strings(got)
+	sort.Strings(exp)
+	if strings.Join(got, "\n") == strings.Join(exp, "\n") {
+		return ""
+	}
+	return fmt.Sprintf("alertname: %s, state: %s, time: %s,\n    exp: %s,\n    got: %s",
+		rule.Title, want, time.Duration(at.EvalTime), formatAlerts(exp), formatAlerts(got))
+}
+
+// alertString returns a canonical representation of an alert, without the labels and the
+// annotations that are added to every instance.
+func alertString(labels, annotations map[string]string) string {
+	lbls := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k == model.AlertNameLabel || strings.HasPrefix(k, "__") {
+			continue
+		}
+		lbls[k] = v
+	}
+	annots := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		annots[k] = v
+	}
+	// maps are marshalled with sorted keys
+	l, _ := json.Marshal(lbls)
+	a, _ := json.Marshal(annots)
+	return fmt.Sprintf("{labels: %s, annotations: %s}", l, a)
+}
+
+func formatAlerts(alerts []string) string {
+	if len(alerts) == 0 {
+		return "[]"
+	}
+	return "[\n        " + strings.Join(alerts, "\n        ") + "\n    ]"
+}
@@ -0,0 +1,51 @@
+package ruletest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFile(t *testing.T) {
+	t.Run("the expected alerts are firing", func(t *testing.T) {
+		result, err := RunFile("testdata/rules_test.yaml")
+		require.NoError(t, err)
+		require.Empty(t, result.Failures)
+		require.True(t, result.Passed())
+	})
+
+	t.Run("the failed assertions are reported", func(t *testing.T) {
+		result, err := RunFile("testdata/failing_test.yaml")
+		require.NoError(t, err)
+		require.False(t, result.Passed())
+		require.Len(t, result.Failures, 1)
+		require.Contains(t, result.Failures[0], `no rule with the title "Unknown"`)
+	})
+
+	t.Run("the alerts that differ from the expected alerts are reported", func(t *testing.T) {
+		result, err := RunFile("testdata/wrong_alerts_test.yaml")
+		require.NoError(t, err)
+		require.False(t, result.Passed())
+		require.Equal(t, []string{
+			`wrong alerts: alertname: HighCPU, state: Alerting, time: 3m0s,
+    exp: [
+        {labels: {"host":"b","severity":"critical"}, annotations: {"summary":"CPU of b is 90%"}}
+    ],
+    got: [
+        {labels: {"host":"a","severity":"critical"}, annotations: {"summary":"CPU of a is 90%"}}
+    ]`,
+			`wrong alerts: alertname: HighCPU, state: Alerting, time: 4m0s,
+    exp: [
+        {labels: {"host":"a","severity":"critical"}, annotations: {"summary":"CPU of a is 80%"}}
+    ],
+    got: [
+        {labels: {"host":"a","severity":"critical"}, annotations: {"summary":"CPU of a is 90%"}}
+    ]`,
+		}, result.Failures)
+	})
+
+	t.Run("a file that does not exist cannot be run", func(t *testing.T) {
+		_, err := RunFile("testdata/missing.yaml")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,115 @@
+package ruletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// datasourceType is the type of the data source of the queries of the rules under test,
+// whatever their actual data source.
+const datasourceType = "ruletest"
+
+// sample is a value of a series at a time.
+type sample struct {
+	t time.Time
+	v float64
+}
+
+// series is an input series with its samples.
+type series struct {
+	rule    string
+	refID   string
+	name    string
+	labels  data.Labels
+	samples []sample
+}
+
+// parseInputSeries expands the values of an input series, with the notation of promtool, e.g.
+// "1+1x3 _ stale", into samples that are interval apart from start.
+func parseInputSeries(in inputSeries, start time.Time, interval time.Duration) (*series, error) {
+	if in.RefID == "" {
+		return nil, fmt.Errorf("the ref_id of input series %q is missing", in.Series)
+	}
+	lbls, values, err := parser.ParseSeriesDesc(in.Series + " " + in.Values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input series %q: %w", in.Series, err)
+	}
+
+	s := &series{
+		rule:   in.Rule,
+		refID:  in.RefID,
+		name:   lbls.Get(labels.MetricName),
+		labels: data.Labels{},
+	}
+	for _, l := range lbls {
+		if l.Name != labels.MetricName {
+			s.labels[l.Name] = l.Value
+		}
+	}
+	for i, v := range values {
+		if v.Omitted || math.IsNaN(v.Value) && value.IsStaleNaN(v.Value) {
+			continue
+		}
+		s.samples = append(s.samples, sample{t: start.Add(time.Duration(i) * interval), v: v.Value})
+	}
+	return s, nil
+}
+
+// frame returns the samples of the series between from and to, or nil if there are none.
+func (s *series) frame(refID string, from, to time.Time) *data.Frame {
+	var times []time.Time
+	var values []float64
+	for _, smpl := range s.samples {
+		if smpl.t.Before(from) || smpl.t.After(to) {
+			continue
+		}
+		times = append(times, smpl.t)
+		values = append(values, smpl.v)
+	}
+	if len(times) == 0 {
+		return nil
+	}
+	frame := data.NewFrame(s.name,
+		data.NewField("Time", nil, times),
+		data.NewField("Value", s.labels.Copy(), values),
+	)
+	frame.RefID = refID
+	return frame
+}
+
+// mockedData is the data source of the queries of a rule. Each query returns the samples of
+// the input series of its refID in its time range.
+type mockedData struct {
+	series []*series
+}
+
+func (m mockedData) HandleRequest(_ context.Context, _ *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	from, to := query.TimeRange.GetFromAsTimeUTC(), query.TimeRange.GetToAsTimeUTC()
+	resp := plugins.DataResponse{Results: make(map[string]plugins.DataQueryResult, len(query.Queries))}
+	for _, q := range query.Queries {
+		frames := data.Frames{}
+		for _, s := range m.series {
+			if s.refID != q.RefID {
+				continue
+			}
+			if f := s.frame(q.RefID, from, to); f != nil {
+				frames = append(frames, f)
+			}
+		}
+		resp.Results[q.RefID] = plugins.DataQueryResult{
+			RefID:      q.RefID,
+			Dataframes: plugins.NewDecodedDataFrames(frames),
+		}
+	}
+	return resp, nil
+}
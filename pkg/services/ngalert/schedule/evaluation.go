@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// evaluationSettings controls the evaluation of an alert rule on a tick of the scheduler.
+type evaluationSettings struct {
+	// timeout is the maximum duration of an attempt, the evaluator default is used if it is zero.
+	timeout time.Duration
+	// maxAttempts is how many times an evaluation that fails is attempted.
+	maxAttempts int64
+	// retryBackoff is the delay before the first retry, it is doubled on every retry.
+	retryBackoff time.Duration
+	// skipIfRunning skips a tick while the evaluation of the previous tick is still running,
+	// otherwise the tick is evaluated once the previous evaluation finishes.
+	skipIfRunning bool
+}
+
+// evaluationSettingsFor returns the settings of the scheduler overridden by those of the alert rule.
+func (sch *schedule) evaluationSettingsFor(alertRule *models.AlertRule) evaluationSettings {
+	settings := sch.evaluation
+	e := alertRule.Evaluation
+	if e == nil {
+		return settings
+	}
+	if e.Timeout > 0 {
+		settings.timeout = time.Duration(e.Timeout)
+	}
+	if e.MaxAttempts > 0 {
+		settings.maxAttempts = e.MaxAttempts
+	}
+	if e.RetryBackoff != nil {
+		settings.retryBackoff = time.Duration(*e.RetryBackoff)
+	}
+	if e.SkipIfRunning != nil {
+		settings.skipIfRunning = *e.SkipIfRunning
+	}
+	return settings
+}
+
+// nextBackoff doubles the backoff, up to the interval of the alert rule.
+func nextBackoff(backoff, interval time.Duration) time.Duration {
+	backoff *= 2
+	if interval > 0 && backoff > interval {
+		return interval
+	}
+	return backoff
+}
+
+// evaluationError returns the error of the first result in the Error state, if any.
+func evaluationError(results eval.Results) error {
+	for _, r := range results {
+		if r.State != eval.Error {
+			continue
+		}
+		if r.Error != nil {
+			return r.Error
+		}
+		return errors.New("the evaluation resulted in the Error state")
+	}
+	return nil
+}
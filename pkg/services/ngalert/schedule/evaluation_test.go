@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationSettingsFor(t *testing.T) {
+	sch := &schedule{evaluation: evaluationSettings{
+		timeout:       30 * time.Second,
+		maxAttempts:   3,
+		retryBackoff:  time.Second,
+		skipIfRunning: true,
+	}}
+
+	t.Run("global settings are used when the rule does not override them", func(t *testing.T) {
+		require.Equal(t, sch.evaluation, sch.evaluationSettingsFor(&models.AlertRule{}))
+		require.Equal(t, sch.evaluation, sch.evaluationSettingsFor(&models.AlertRule{Evaluation: &models.EvaluationSettings{}}))
+	})
+
+	t.Run("rule settings override the global settings", func(t *testing.T) {
+		noBackoff := model.Duration(0)
+		skip := false
+		settings := sch.evaluationSettingsFor(&models.AlertRule{Evaluation: &models.EvaluationSettings{
+			Timeout:       model.Duration(5 * time.Second),
+			MaxAttempts:   1,
+			RetryBackoff:  &noBackoff,
+			SkipIfRunning: &skip,
+		}})
+		require.Equal(t, evaluationSettings{
+			timeout:       5 * time.Second,
+			maxAttempts:   1,
+			retryBackoff:  0,
+			skipIfRunning: false,
+		}, settings)
+	})
+}
+
+func TestNextBackoff(t *testing.T) {
+	require.Equal(t, 2*time.Second, nextBackoff(time.Second, time.Minute))
+	require.Equal(t, 10*time.Second, nextBackoff(8*time.Second, 10*time.Second))
+	require.Equal(t, 16*time.Second, nextBackoff(8*time.Second, 0))
+}
+
+func TestEvaluationError(t *testing.T) {
+	require.NoError(t, evaluationError(eval.Results{{State: eval.Normal}, {State: eval.Alerting}}))
+
+	err := errors.New("datasource is down")
+	require.Equal(t, err, evaluationError(eval.Results{{State: eval.Normal}, {State: eval.Error, Error: err}}))
+	require.Error(t, evaluationError(eval.Results{{State: eval.Error}}))
+}
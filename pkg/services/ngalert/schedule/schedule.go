@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -46,7 +48,8 @@ type schedule struct {
 	// each alert rule gets its own channel and routine
 	registry alertRuleRegistry
 
-	maxAttempts int64
+	// evaluation holds the global settings for the evaluation of the alert rules.
+	evaluation evaluationSettings
 
 	clock clock.Clock
 
@@ -74,6 +77,12 @@ type schedule struct {
 
 	stateManager *state.Manager
 
+	// recordingWriter writes the results of recording rules.
+	recordingWriter recording.Writer
+
+	// sharding is set when the alert rules are sharded among the instances of a cluster.
+	sharding *ruleSharding
+
 	appURL string
 
 	multiOrgNotifier *notifier.MultiOrgAlertmanager
@@ -102,6 +111,20 @@ type SchedulerCfg struct {
 	MultiOrgNotifier        *notifier.MultiOrgAlertmanager
 	Metrics                 *metrics.Metrics
 	AdminConfigPollInterval time.Duration
+	RecordingWriter         recording.Writer
+
+	// EvaluationTimeout, MaxAttempts, RetryBackoff and SkipIfRunning control the evaluation
+	// of the alert rules, unless an alert rule overrides them.
+	EvaluationTimeout time.Duration
+	RetryBackoff      time.Duration
+	SkipIfRunning     bool
+
+	// ShardingEnabled shards the alert rules among the schedulers of the Grafana instances
+	// of a cluster, identified by InstanceID, that send a heartbeat to the SchedulerInstanceStore.
+	ShardingEnabled        bool
+	InstanceID             string
+	SchedulerInstanceStore store.SchedulerInstanceStore
+	HeartbeatTimeout       time.Duration
 }
 
 // NewScheduler returns a new schedule.
@@ -109,7 +132,6 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string, st
 	ticker := alerting.NewTicker(cfg.C.Now(), time.Second*0, cfg.C, int64(cfg.BaseInterval.Seconds()))
 	sch := schedule{
 		registry:                alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
-		maxAttempts:             cfg.MaxAttempts,
 		clock:                   cfg.C,
 		baseInterval:            cfg.BaseInterval,
 		log:                     cfg.Logger,
@@ -126,9 +148,19 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string, st
 		metrics:                 cfg.Metrics,
 		appURL:                  appURL,
 		stateManager:            stateManager,
+		recordingWriter:         cfg.RecordingWriter,
 		senders:                 map[int64]*sender.Sender{},
 		sendersCfgHash:          map[int64]string{},
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
+		evaluation: evaluationSettings{
+			timeout:       cfg.EvaluationTimeout,
+			maxAttempts:   cfg.MaxAttempts,
+			retryBackoff:  cfg.RetryBackoff,
+			skipIfRunning: cfg.SkipIfRunning,
+		},
+	}
+	if cfg.ShardingEnabled {
+		sch.sharding = newRuleSharding(cfg.InstanceID, cfg.SchedulerInstanceStore, cfg.HeartbeatTimeout, cfg.Logger)
 	}
 	return &sch
 }
@@ -310,6 +342,12 @@ func (sch *schedule) adminConfigSync(ctx context.Context) error {
 
 func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	// owned keeps whether each alert rule was owned by this instance on the previous
+	// tick, and reloadState the rules whose states must be reloaded from the database
+	// on their next evaluation because they were evaluated by another instance.
+	owned := make(map[models.AlertRuleKey]bool)
+	reloadState := make(map[models.AlertRuleKey]struct{})
 	for {
 		select {
 		case tick := <-sch.heartbeat.C:
@@ -317,6 +355,10 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			alertRules := sch.fetchAllDetails()
 			sch.log.Debug("alert rules fetched", "count", len(alertRules))
 
+			if sch.sharding != nil {
+				sch.sharding.sync(tick)
+			}
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
@@ -349,13 +391,35 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 					continue
 				}
 
+				// remove the alert rule from the registered alert rules
+				delete(registeredDefinitions, key)
+
+				if sch.sharding != nil {
+					wasOwned, known := owned[key]
+					isOwned := sch.sharding.owns(key)
+					owned[key] = isOwned
+					if !isOwned {
+						if !known || wasOwned {
+							// another instance evaluates the rule, its states there are authoritative
+							sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+							delete(reloadState, key)
+						}
+						continue
+					}
+					if known && !wasOwned {
+						reloadState[key] = struct{}{}
+					}
+				}
+
+				// the routine of a paused rule keeps running so that its states are kept
+				if item.IsPausedAt(tick) {
+					continue
+				}
+
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
 					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
 				}
-
-				// remove the alert rule from the registered alert rules
-				delete(registeredDefinitions, key)
 			}
 
 			var step int64 = 0
@@ -365,9 +429,11 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 
 			for i := range readyToRun {
 				item := readyToRun[i]
+				_, reload := reloadState[item.key]
+				delete(reloadState, item.key)
 
 				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version, reloadState: reload}
 				})
 			}
 
@@ -380,6 +446,8 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 				}
 				ruleInfo.stopCh <- struct{}{}
 				sch.registry.del(key)
+				delete(owned, key)
+				delete(reloadState, key)
 			}
 		case <-ctx.Done():
 			waitErr := dispatcherGroup.Wait()
@@ -394,6 +462,9 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			}
 
 			sch.stateManager.Close()
+			if sch.sharding != nil {
+				sch.sharding.leave()
+			}
 			return waitErr
 		}
 	}
@@ -402,96 +473,52 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRuleKey, evalCh <-chan *evalContext, stopCh <-chan struct{}) error {
 	sch.log.Debug("alert rule routine started", "key", key)
 
-	evalRunning := false
-	var attempt int64
+	// evalRunning is set while an evaluation that can be skipped runs in the background.
+	var evalRunning int32
+	var evalWg sync.WaitGroup
+	defer evalWg.Wait()
+
 	var alertRule *models.AlertRule
 	for {
 		select {
 		case ctx := <-evalCh:
-			if evalRunning {
+			if atomic.LoadInt32(&evalRunning) == 1 {
+				sch.metrics.EvalSkipped.WithLabelValues(fmt.Sprint(key.OrgID)).Inc()
+				sch.log.Warn("skipping the evaluation of the alert rule, the previous evaluation is still running", "key", key, "now", ctx.now)
 				continue
 			}
 
-			evaluate := func(attempt int64) error {
-				start := timeNow()
-
-				// fetch latest alert rule version
-				if alertRule == nil || alertRule.Version < ctx.version {
-					q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
-					err := sch.ruleStore.GetAlertRuleByUID(&q)
-					if err != nil {
-						sch.log.Error("failed to fetch alert rule", "key", key)
-						return err
-					}
-					alertRule = q.Result
-					sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
-				}
-
-				condition := models.Condition{
-					Condition: alertRule.Condition,
-					OrgID:     alertRule.OrgID,
-					Data:      alertRule.Data,
-				}
-				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
-				var (
-					end    = timeNow()
-					tenant = fmt.Sprint(alertRule.OrgID)
-					dur    = end.Sub(start).Seconds()
-				)
-
-				sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
-				sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+			// fetch latest alert rule version
+			if alertRule == nil || alertRule.Version < ctx.version {
+				q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
+				err := sch.ruleStore.GetAlertRuleByUID(&q)
 				if err != nil {
-					sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
-					// consider saving alert instance on error
-					sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
-						"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
-					return err
-				}
-
-				processedStates := sch.stateManager.ProcessEvalResults(alertRule, results)
-				sch.saveAlertStates(processedStates)
-				alerts := FromAlertStateToPostableAlerts(sch.log, processedStates, sch.stateManager, sch.appURL)
-
-				sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts, "org", alertRule.OrgID)
-				n, err := sch.multiOrgNotifier.AlertmanagerFor(alertRule.OrgID)
-				if err == nil {
-					if err := n.PutAlerts(alerts); err != nil {
-						sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
-					}
-				} else {
-					sch.log.Error("unable to lookup local notifier for this org - alerts not delivered", "org", alertRule.OrgID, "count", len(alerts.PostableAlerts), "err", err)
-				}
-
-				// Send alerts to external Alertmanager(s) if we have a sender for this organization.
-				sch.sendersMtx.RLock()
-				defer sch.sendersMtx.RUnlock()
-				s, ok := sch.senders[alertRule.OrgID]
-				if ok {
-					s.SendAlerts(alerts)
+					sch.log.Error("failed to fetch alert rule", "key", key, "err", err)
+					sch.evalApplied(key, ctx.now)
+					continue
 				}
-
-				return nil
+				alertRule = q.Result
+				sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
 			}
 
-			func() {
-				evalRunning = true
-				defer func() {
-					evalRunning = false
-					sch.evalApplied(key, ctx.now)
-				}()
+			settings := sch.evaluationSettingsFor(alertRule)
+			if !settings.skipIfRunning {
+				sch.evaluate(grafanaCtx, alertRule, ctx, settings)
+				sch.evalApplied(key, ctx.now)
+				continue
+			}
 
-				for attempt = 0; attempt < sch.maxAttempts; attempt++ {
-					err := evaluate(attempt)
-					if err == nil {
-						break
-					}
-				}
-			}()
+			atomic.StoreInt32(&evalRunning, 1)
+			evalWg.Add(1)
+			go func(alertRule *models.AlertRule, ctx *evalContext) {
+				defer evalWg.Done()
+				sch.evaluate(grafanaCtx, alertRule, ctx, settings)
+				atomic.StoreInt32(&evalRunning, 0)
+				sch.evalApplied(key, ctx.now)
+			}(alertRule, ctx)
 		case <-stopCh:
 			sch.stopApplied(key)
 			sch.log.Debug("stopping alert rule routine", "key", key)
-			// interrupt evaluation if it's running
 			return nil
 		case <-grafanaCtx.Done():
 			return grafanaCtx.Err()
@@ -499,6 +526,113 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// evaluate evaluates the alert rule for a tick of the scheduler. An evaluation that fails is
+// retried with an exponential backoff until it succeeds or the maximum number of attempts is reached.
+func (sch *schedule) evaluate(grafanaCtx context.Context, alertRule *models.AlertRule, ctx *evalContext, settings evaluationSettings) {
+	start := timeNow()
+	tenant := fmt.Sprint(alertRule.OrgID)
+	defer func() {
+		sch.metrics.EvalCycleDuration.WithLabelValues(tenant).Observe(timeNow().Sub(start).Seconds())
+	}()
+
+	backoff := settings.retryBackoff
+	for attempt := int64(0); attempt < settings.maxAttempts; attempt++ {
+		if attempt > 0 {
+			sch.metrics.EvalRetries.WithLabelValues(tenant).Inc()
+			if backoff > 0 {
+				select {
+				case <-time.After(backoff):
+				case <-grafanaCtx.Done():
+					return
+				}
+				backoff = nextBackoff(backoff, time.Duration(alertRule.IntervalSeconds)*time.Second)
+			}
+		}
+
+		var (
+			err          error
+			attemptStart = timeNow()
+			lastAttempt  = attempt == settings.maxAttempts-1
+		)
+		if alertRule.IsRecordingRule() {
+			err = sch.record(alertRule, ctx.now, attempt, settings.timeout)
+		} else {
+			err = sch.evaluateAlertRule(alertRule, ctx, attempt, lastAttempt, settings.timeout)
+		}
+		// The health of the rule is the outcome of the last attempt, failed attempts that are retried do not count.
+		if err == nil || lastAttempt {
+			sch.stateManager.RecordEvaluation(alertRule.OrgID, alertRule.UID, ctx.now, timeNow().Sub(attemptStart), err)
+		}
+		if err == nil {
+			return
+		}
+	}
+}
+
+// evaluateAlertRule evaluates the condition of the alert rule, processes the results and sends
+// the resulting alerts. Unless it is the last attempt, results in the Error state are not
+// processed and an error is returned so the evaluation is retried. On the last attempt, they
+// are processed and their error is returned after the alerts are sent.
+func (sch *schedule) evaluateAlertRule(alertRule *models.AlertRule, ctx *evalContext, attempt int64, lastAttempt bool, timeout time.Duration) error {
+	start := timeNow()
+	key := alertRule.GetKey()
+
+	if ctx.reloadState && attempt == 0 {
+		sch.stateManager.WarmRule(alertRule)
+	}
+
+	condition := models.Condition{
+		Condition:        alertRule.Condition,
+		OrgID:            alertRule.OrgID,
+		Data:             alertRule.Data,
+		LoadedDimensions: sch.stateManager.GetLoadedDimensions(alertRule),
+		Timeout:          timeout,
+	}
+	results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+	if err == nil && !lastAttempt {
+		err = evaluationError(results)
+	}
+	var (
+		end    = timeNow()
+		tenant = fmt.Sprint(alertRule.OrgID)
+		dur    = end.Sub(start).Seconds()
+	)
+
+	sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+		// consider saving alert instance on error
+		sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
+			"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
+		return err
+	}
+
+	processedStates := sch.stateManager.ProcessEvalResults(alertRule, results)
+	sch.saveAlertStates(processedStates)
+	alerts := FromAlertStateToPostableAlerts(sch.log, processedStates, sch.stateManager, sch.appURL)
+
+	sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts, "org", alertRule.OrgID)
+	n, err := sch.multiOrgNotifier.AlertmanagerFor(alertRule.OrgID)
+	if err == nil {
+		if err := n.PutAlerts(alerts); err != nil {
+			sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
+		}
+	} else {
+		sch.log.Error("unable to lookup local notifier for this org - alerts not delivered", "org", alertRule.OrgID, "count", len(alerts.PostableAlerts), "err", err)
+	}
+
+	// Send alerts to external Alertmanager(s) if we have a sender for this organization.
+	sch.sendersMtx.RLock()
+	defer sch.sendersMtx.RUnlock()
+	s, ok := sch.senders[alertRule.OrgID]
+	if ok {
+		s.SendAlerts(alerts)
+	}
+
+	return evaluationError(results)
+}
+
 func (sch *schedule) saveAlertStates(states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -601,6 +735,47 @@ type alertRuleInfo struct {
 type evalContext struct {
 	now     time.Time
 	version int64
+	// reloadState is set when the alert rule was evaluated by another instance of the
+	// cluster until now, and its states must be reloaded from the database.
+	reloadState bool
+}
+
+// record evaluates the condition of a recording rule and writes the numbers it evaluates to.
+func (sch *schedule) record(alertRule *models.AlertRule, now time.Time, attempt int64, timeout time.Duration) error {
+	start := timeNow()
+	condition := models.Condition{
+		Condition: alertRule.Condition,
+		OrgID:     alertRule.OrgID,
+		Data:      alertRule.Data,
+		Timeout:   timeout,
+	}
+	numbers, err := sch.evaluator.RecordingEval(&condition, now, sch.dataService)
+	var (
+		end    = timeNow()
+		tenant = fmt.Sprint(alertRule.OrgID)
+	)
+
+	sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(end.Sub(start).Seconds())
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+		sch.log.Error("failed to evaluate recording rule", "title", alertRule.Title,
+			"key", alertRule.GetKey(), "attempt", attempt, "now", now, "duration", end.Sub(start), "error", err)
+		return err
+	}
+
+	if sch.recordingWriter == nil {
+		sch.log.Warn("no output is configured for recording rules, dropping results", "key", alertRule.GetKey(), "record", alertRule.Record)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(alertRule.IntervalSeconds)*time.Second)
+	defer cancel()
+	if err := sch.recordingWriter.Write(ctx, alertRule, now, numbers); err != nil {
+		sch.log.Error("failed to write the results of recording rule", "title", alertRule.Title,
+			"key", alertRule.GetKey(), "attempt", attempt, "record", alertRule.Record, "error", err)
+		return err
+	}
+	return nil
 }
 
 // overrideCfg is only used on tests.
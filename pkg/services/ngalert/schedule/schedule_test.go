@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -44,6 +45,7 @@ func TestWarmStateCache(t *testing.T) {
 			OrgID:        rule.OrgID,
 			CacheId:      `[["test1","testValue1"]]`,
 			Labels:       data.Labels{"test1": "testValue1"},
+			ResultLabels: data.Labels{"test1": "testValue1"},
 			State:        eval.Normal,
 			Results: []state.Evaluation{
 				{EvaluationTime: evaluationTime, EvaluationState: eval.Normal},
@@ -57,6 +59,7 @@ func TestWarmStateCache(t *testing.T) {
 			OrgID:        rule.OrgID,
 			CacheId:      `[["test2","testValue2"]]`,
 			Labels:       data.Labels{"test2": "testValue2"},
+			ResultLabels: data.Labels{"test2": "testValue2"},
 			State:        eval.Alerting,
 			Results: []state.Evaluation{
 				{EvaluationTime: evaluationTime, EvaluationState: eval.Alerting},
@@ -101,7 +104,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore, dbstore)
 	st.Warm()
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -146,7 +149,7 @@ func TestAlertingTicker(t *testing.T) {
 		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore, dbstore)
 	sched := schedule.NewScheduler(schedCfg, nil, "http://localhost", st)
 
 	ctx := context.Background()
@@ -214,6 +217,32 @@ func TestAlertingTicker(t *testing.T) {
 		tick := advanceClock(t, mockedClock)
 		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
 	})
+
+	err = dbstore.SetAlertRulePaused(store.SetAlertRulePausedCmd{OrgID: alerts[2].OrgID, UID: alerts[2].UID, Paused: true})
+	require.NoError(t, err)
+	t.Logf("alert rule: %v paused", alerts[2].GetKey())
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{}
+	t.Run(fmt.Sprintf("on 8th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[1].GetKey()}
+	t.Run(fmt.Sprintf("on 9th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
+
+	err = dbstore.SetAlertRulePaused(store.SetAlertRulePausedCmd{OrgID: alerts[2].OrgID, UID: alerts[2].UID, Paused: false})
+	require.NoError(t, err)
+	t.Logf("alert rule: %v resumed", alerts[2].GetKey())
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[2].GetKey()}
+	t.Run(fmt.Sprintf("on 10th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
 }
 
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
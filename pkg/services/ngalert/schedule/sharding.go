@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// tokensPerInstance is the number of points each instance has on the hash ring.
+// More points make the distribution of the rules more even.
+const tokensPerInstance = 128
+
+// hashRing assigns alert rules to instances using consistent hashing, so that
+// only the rules of the instance that joins or leaves the ring change owner.
+type hashRing struct {
+	tokens []uint64
+	owners map[uint64]string
+}
+
+func newHashRing(instances []string) *hashRing {
+	r := &hashRing{
+		tokens: make([]uint64, 0, len(instances)*tokensPerInstance),
+		owners: make(map[uint64]string, len(instances)*tokensPerInstance),
+	}
+	for _, instance := range instances {
+		for i := 0; i < tokensPerInstance; i++ {
+			token := hash(fmt.Sprintf("%s#%d", instance, i))
+			if _, ok := r.owners[token]; ok {
+				continue
+			}
+			r.owners[token] = instance
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool { return r.tokens[i] < r.tokens[j] })
+	return r
+}
+
+// owner returns the instance that owns the alert rule: the instance of the
+// first token after the hash of the key, or an empty string if the ring is empty.
+func (r *hashRing) owner(key models.AlertRuleKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hash(fmt.Sprintf("%d/%s", key.OrgID, key.UID))
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[r.tokens[i]]
+}
+
+// hash returns a well distributed hash of the string. FNV is not used as its high bits are
+// poorly mixed for short and similar strings, such as the tokens of an instance.
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// ruleSharding distributes the evaluation of the alert rules over the schedulers of
+// the Grafana instances of a cluster, so each rule is evaluated once per interval.
+// The instances advertise that they are alive with a heartbeat in the database, and
+// the instances whose last heartbeat is older than the heartbeat timeout are left out.
+type ruleSharding struct {
+	instanceID       string
+	store            store.SchedulerInstanceStore
+	heartbeatTimeout time.Duration
+	log              log.Logger
+
+	// instances are the instances of the current ring, sorted by ID.
+	instances []string
+	ring      *hashRing
+}
+
+func newRuleSharding(instanceID string, store store.SchedulerInstanceStore, heartbeatTimeout time.Duration, logger log.Logger) *ruleSharding {
+	return &ruleSharding{
+		instanceID:       instanceID,
+		store:            store,
+		heartbeatTimeout: heartbeatTimeout,
+		log:              logger,
+	}
+}
+
+// sync records the heartbeat of the instance and rebuilds the ring if instances joined
+// or left the cluster. It returns true if the ring changed. If the database can not be
+// reached the previous ring is kept.
+func (s *ruleSharding) sync(now time.Time) bool {
+	if err := s.store.HeartbeatSchedulerInstance(s.instanceID, now); err != nil {
+		s.log.Error("failed to record scheduler heartbeat", "instance", s.instanceID, "err", err)
+		return false
+	}
+
+	since := now.Add(-s.heartbeatTimeout)
+	if err := s.store.DeleteSchedulerInstancesBefore(since); err != nil {
+		s.log.Warn("failed to delete expired scheduler instances", "err", err)
+	}
+	instances, err := s.store.ListSchedulerInstances(since)
+	if err != nil {
+		s.log.Error("failed to list scheduler instances", "err", err)
+		return false
+	}
+
+	if s.ring != nil && equalInstances(s.instances, instances) {
+		return false
+	}
+	s.log.Info("rebalancing alert rules", "instance", s.instanceID, "instances", strings.Join(instances, ","))
+	s.instances = instances
+	s.ring = newHashRing(instances)
+	return true
+}
+
+// owns returns true if the instance must evaluate the alert rule. All the alert rules are
+// owned until the ring is known, so rules are not left unevaluated if the database is down
+// on startup.
+func (s *ruleSharding) owns(key models.AlertRuleKey) bool {
+	if s.ring == nil || len(s.instances) == 0 {
+		return true
+	}
+	return s.ring.owner(key) == s.instanceID
+}
+
+// leave removes the instance from the cluster so its rules are taken over by the other
+// instances without waiting for the heartbeat timeout.
+func (s *ruleSharding) leave() {
+	if err := s.store.DeleteSchedulerInstance(s.instanceID); err != nil {
+		s.log.Error("failed to remove scheduler instance", "instance", s.instanceID, "err", err)
+	}
+}
+
+func equalInstances(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("an empty ring has no owner", func(t *testing.T) {
+		require.Equal(t, "", newHashRing(nil).owner(keys[0]))
+	})
+
+	t.Run("rules are spread over the instances", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		counts := map[string]int{}
+		for _, k := range keys {
+			counts[ring.owner(k)]++
+		}
+		require.Len(t, counts, 3)
+		for instance, count := range counts {
+			require.Greater(t, count, 200, "instance %s owns too few rules", instance)
+		}
+	})
+
+	t.Run("only the rules of the instance that joins change owner", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "b", "c", "d"})
+		for _, k := range keys {
+			if owner := after.owner(k); owner != "d" {
+				require.Equal(t, before.owner(k), owner)
+			}
+		}
+	})
+}
+
+type fakeSchedulerInstanceStore struct {
+	heartbeats map[string]time.Time
+	err        error
+}
+
+func (f *fakeSchedulerInstanceStore) HeartbeatSchedulerInstance(instanceID string, now time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.heartbeats[instanceID] = now
+	return nil
+}
+
+func (f *fakeSchedulerInstanceStore) ListSchedulerInstances(since time.Time) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	instances := []string{}
+	for id, hb := range f.heartbeats {
+		if !hb.Before(since) {
+			instances = append(instances, id)
+		}
+	}
+	sort.Strings(instances)
+	return instances, nil
+}
+
+func (f *fakeSchedulerInstanceStore) DeleteSchedulerInstancesBefore(olderThan time.Time) error {
+	for id, hb := range f.heartbeats {
+		if hb.Before(olderThan) {
+			delete(f.heartbeats, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSchedulerInstanceStore) DeleteSchedulerInstance(instanceID string) error {
+	delete(f.heartbeats, instanceID)
+	return nil
+}
+
+func TestRuleSharding(t *testing.T) {
+	start := time.Unix(1000, 0)
+	store := &fakeSchedulerInstanceStore{heartbeats: map[string]time.Time{}}
+	a := newRuleSharding("a", store, time.Minute, log.New("test"))
+	b := newRuleSharding("b", store, time.Minute, log.New("test"))
+
+	key := models.AlertRuleKey{OrgID: 1, UID: "rule"}
+	require.True(t, a.owns(key), "rules are owned until the ring is known")
+
+	require.True(t, a.sync(start))
+	require.True(t, b.sync(start))
+	require.True(t, a.sync(start), "the ring changes when an instance joins")
+	require.False(t, a.sync(start.Add(10*time.Second)))
+	require.NotEqual(t, a.owns(key), b.owns(key), "a rule is owned by a single instance")
+
+	// b stops sending heartbeats
+	require.True(t, a.sync(start.Add(2*time.Minute)), "the ring changes when an instance leaves")
+	require.True(t, a.owns(key))
+
+	t.Run("the previous ring is kept when the database fails", func(t *testing.T) {
+		store.err = errors.New("database is down")
+		require.False(t, a.sync(start.Add(3*time.Minute)))
+		require.True(t, a.owns(key))
+		store.err = nil
+	})
+
+	t.Run("leave removes the instance from the ring of the others", func(t *testing.T) {
+		now := start.Add(4 * time.Minute)
+		require.True(t, b.sync(now))
+		require.True(t, a.sync(now))
+		b.leave()
+		require.True(t, a.sync(now))
+		require.Equal(t, []string{"a"}, a.instances)
+	})
+}
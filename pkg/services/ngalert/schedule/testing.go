@@ -180,6 +180,23 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	return nil
 }
 
+func (f *fakeRuleStore) SetAlertRulePaused(cmd store.SetAlertRulePausedCmd) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, rg := range f.rules[cmd.OrgID] {
+		for _, rules := range rg {
+			for _, r := range rules {
+				if r.UID == cmd.UID {
+					r.IsPaused = cmd.Paused
+					r.PausedUntil = cmd.Until
+					return nil
+				}
+			}
+		}
+	}
+	return models.ErrAlertRuleNotFound
+}
+
 type fakeInstanceStore struct{}
 
 func (f *fakeInstanceStore) GetAlertInstance(_ *models.GetAlertInstanceQuery) error     { return nil }
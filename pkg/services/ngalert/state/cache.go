@@ -3,6 +3,7 @@ package state
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,7 +40,7 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	// clone the labels so we don't change eval.Result
 	labels := result.Instance.Copy()
 	attachRuleLabels(labels, alertRule)
-	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(alertRule, labels, result)
+	ruleLabels, annotations, templateErr := c.expandRuleLabelsAndAnnotations(alertRule, labels, result)
 
 	// if duplicate labels exist, alertRule label will take precedence
 	lbs := mergeLabels(ruleLabels, result.Instance)
@@ -61,6 +62,8 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	if state, ok := c.states[alertRule.OrgID][alertRule.UID][id]; ok {
 		// Annotations can change over time for the same alert.
 		state.Annotations = annotations
+		state.TemplateError = templateErr
+		state.ResultLabels = result.Instance.Copy()
 		c.states[alertRule.OrgID][alertRule.UID][id] = state
 		return state
 	}
@@ -72,8 +75,10 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 		OrgID:              alertRule.OrgID,
 		CacheId:            id,
 		Labels:             lbs,
+		ResultLabels:       result.Instance.Copy(),
 		Annotations:        annotations,
 		EvaluationDuration: result.EvaluationDuration,
+		TemplateError:      templateErr,
 	}
 	if result.State == eval.Alerting {
 		newState.StartsAt = result.EvaluatedAt
@@ -88,14 +93,19 @@ func attachRuleLabels(m map[string]string, alertRule *ngModels.AlertRule) {
 	m[prometheusModel.AlertNameLabel] = alertRule.Title
 }
 
-func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string) {
-	expand := func(original map[string]string) map[string]string {
+// expandRuleLabelsAndAnnotations expands the templates of the labels and annotations of the rule.
+// A label or annotation whose template fails keeps its original text, and the failures are
+// returned as a single error so that they can be reported on the health of the rule.
+func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string, error) {
+	var errs []string
+	expand := func(kind string, original map[string]string) map[string]string {
 		expanded := make(map[string]string, len(original))
 		for k, v := range original {
 			ev, err := expandTemplate(alertRule.Title, v, labels, alertInstance)
 			expanded[k] = ev
 			if err != nil {
 				c.log.Error("error in expanding template", "name", k, "value", v, "err", err.Error())
+				errs = append(errs, fmt.Sprintf("%s %s: %s", kind, k, err))
 				// Store the original template on error.
 				expanded[k] = v
 			}
@@ -103,7 +113,13 @@ func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, la
 
 		return expanded
 	}
-	return expand(alertRule.Labels), expand(alertRule.Annotations)
+	expandedLabels, expandedAnnotations := expand("label", alertRule.Labels), expand("annotation", alertRule.Annotations)
+	if len(errs) == 0 {
+		return expandedLabels, expandedAnnotations, nil
+	}
+	// Map iteration order is random, sort the errors so that the message is stable between evaluations.
+	sort.Strings(errs)
+	return expandedLabels, expandedAnnotations, fmt.Errorf("failed to expand templates: %s", strings.Join(errs, "; "))
 }
 
 // templateCaptureValue represents each value in .Values in the annotations
@@ -137,7 +153,7 @@ func expandTemplate(name, text string, labels map[string]string, alertInstance e
 		}
 	}()
 
-	tmpl, err := text_template.New(name).Option("missingkey=error").Parse(text)
+	tmpl, err := text_template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
 	if err != nil {
 		return "", fmt.Errorf("error parsing template %v: %s", name, err.Error())
 	}
@@ -228,11 +244,12 @@ func (c *cache) recordMetrics() {
 	// Set default values to zero such that gauges are reset
 	// after all values from a single state disappear.
 	ct := map[eval.State]int{
-		eval.Normal:   0,
-		eval.Alerting: 0,
-		eval.Pending:  0,
-		eval.NoData:   0,
-		eval.Error:    0,
+		eval.Normal:     0,
+		eval.Alerting:   0,
+		eval.Pending:    0,
+		eval.NoData:     0,
+		eval.Error:      0,
+		eval.Suppressed: 0,
 	}
 
 	for org, orgMap := range c.states {
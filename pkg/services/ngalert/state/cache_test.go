@@ -5,7 +5,10 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
@@ -88,6 +91,31 @@ func TestExpandTemplate(t *testing.T) {
 			EvaluationString: "[ var='A' labels={instance=foo} value=10 ]",
 		},
 		expected: "[ var='A' labels={instance=foo} value=10 ]",
+	}, {
+		name: "values are humanized",
+		text: "{{ humanize $values.A }} requests, {{ $values.B | humanizePercentage }} errors, {{ humanizeDuration $values.C }} latency",
+		alertInstance: eval.Result{
+			Values: map[string]eval.NumberValueCapture{
+				"A": {Var: "A", Value: ptr.Float64(1234567)},
+				"B": {Var: "B", Value: ptr.Float64(0.0512)},
+				"C": {Var: "C", Value: ptr.Float64(3725)},
+			},
+		},
+		expected: "1.235M requests, 5.12% errors, 1h 2m 5s latency",
+	}, {
+		name:     "strings functions are available",
+		text:     `{{ toUpper $labels.instance }} {{ printf "%.2f" 1.0 }} {{ if match "^foo" $labels.instance }}{{ reReplaceAll "o+" "0" $labels.instance }}{{ end }}`,
+		labels:   data.Labels{"instance": "foo"},
+		expected: "FOO 1.00 f0",
+	}, {
+		name:          "unknown functions are an error",
+		text:          "{{ query \"up\" }}",
+		expectedError: errors.New("error parsing template __alert_test: template: __alert_test:1: function \"query\" not defined"),
+	}, {
+		name:          "non numeric values cannot be humanized",
+		text:          "{{ humanize $labels.instance }}",
+		labels:        data.Labels{"instance": "foo"},
+		expectedError: errors.New("error executing template __alert_test: template: __alert_test:1:79: executing \"__alert_test\" at <humanize $labels.instance>: error calling humanize: strconv.ParseFloat: parsing \"foo\": invalid syntax"),
 	}}
 
 	for _, c := range cases {
@@ -98,3 +126,29 @@ func TestExpandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOrCreateTemplateError(t *testing.T) {
+	c := newCache(log.New("test"), metrics.NewMetrics(nil))
+	rule := &models.AlertRule{
+		OrgID: 1,
+		UID:   "rule",
+		Title: "rule",
+		Annotations: map[string]string{
+			"summary":     "{{ $labels.instance }} is down",
+			"description": "{{ $values.A }} requests",
+		},
+	}
+
+	result := eval.Result{Instance: data.Labels{"instance": "foo"}}
+	state := c.getOrCreate(rule, result)
+	require.Equal(t, "foo is down", state.Annotations["summary"])
+	require.Equal(t, "{{ $values.A }} requests", state.Annotations["description"])
+	require.Error(t, state.TemplateError)
+	require.Contains(t, state.TemplateError.Error(), "annotation description:")
+
+	// The error is cleared once the template expands again.
+	result.Values = map[string]eval.NumberValueCapture{"A": {Var: "A", Value: ptr.Float64(3)}}
+	state = c.getOrCreate(rule, result)
+	require.Equal(t, "3 requests", state.Annotations["description"])
+	require.NoError(t, state.TemplateError)
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// firingDependency holds the labels of the firing instances of a rule an alert rule depends on.
+type firingDependency struct {
+	dependency ngModels.RuleDependency
+	labels     []data.Labels
+}
+
+// firingDependencies returns the firing instances of the rules the alert rule depends on.
+// The states of the rules are read from the cache. When the cache has no states for a rule,
+// e.g. because it is evaluated by another instance of the cluster, they are read from the
+// alert instances saved in the database.
+func (st *Manager) firingDependencies(alertRule *ngModels.AlertRule) []firingDependency {
+	if len(alertRule.DependsOn) == 0 {
+		return nil
+	}
+
+	deps := make([]firingDependency, 0, len(alertRule.DependsOn))
+	for _, d := range alertRule.DependsOn {
+		fd := firingDependency{dependency: d}
+		states := st.GetStatesForRuleUID(alertRule.OrgID, d.RuleUID)
+		if len(states) > 0 || st.instanceStore == nil {
+			for _, s := range states {
+				if s.State == eval.Alerting {
+					fd.labels = append(fd.labels, s.Labels)
+				}
+			}
+		} else {
+			cmd := ngModels.ListAlertInstancesQuery{
+				RuleOrgID: alertRule.OrgID,
+				RuleUID:   d.RuleUID,
+				State:     ngModels.InstanceStateFiring,
+			}
+			if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
+				st.log.Error("unable to fetch the instances of the rule the alert rule depends on", "uid", alertRule.UID, "dependency", d.RuleUID, "err", err)
+				continue
+			}
+			for _, instance := range cmd.Result {
+				fd.labels = append(fd.labels, data.Labels(instance.Labels))
+			}
+		}
+		if len(fd.labels) > 0 {
+			deps = append(deps, fd)
+		}
+	}
+	return deps
+}
+
+// isSuppressed returns true if a firing instance of a rule the alert rule depends on
+// suppresses the instance with the given labels.
+func isSuppressed(labels data.Labels, parents []firingDependency) bool {
+	for _, p := range parents {
+		for _, parentLabels := range p.labels {
+			if p.dependency.Suppresses(parentLabels, labels) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// RuleHealth is the health of the evaluations of an alert rule.
+type RuleHealth struct {
+	LastEvaluation     time.Time
+	EvaluationDuration time.Duration
+	// LastError is the error of the last evaluation, nil if it succeeded.
+	LastError error
+	// ConsecutiveFailures is the number of evaluations that failed in a row, 0 if the last evaluation succeeded.
+	ConsecutiveFailures int64
+}
+
+type ruleHealths struct {
+	mtx    sync.RWMutex
+	health map[int64]map[string]RuleHealth // orgID > alertRuleUID > health
+}
+
+func newRuleHealths() *ruleHealths {
+	return &ruleHealths{health: make(map[int64]map[string]RuleHealth)}
+}
+
+func (h *ruleHealths) record(orgID int64, ruleUID string, evaluatedAt time.Time, duration time.Duration, err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if _, ok := h.health[orgID]; !ok {
+		h.health[orgID] = make(map[string]RuleHealth)
+	}
+	health := h.health[orgID][ruleUID]
+	health.LastEvaluation = evaluatedAt
+	health.EvaluationDuration = duration
+	health.LastError = err
+	if err != nil {
+		health.ConsecutiveFailures++
+	} else {
+		health.ConsecutiveFailures = 0
+	}
+	h.health[orgID][ruleUID] = health
+}
+
+func (h *ruleHealths) get(orgID int64, ruleUID string) (RuleHealth, bool) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	health, ok := h.health[orgID][ruleUID]
+	return health, ok
+}
+
+func (h *ruleHealths) remove(orgID int64, ruleUID string) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	delete(h.health[orgID], ruleUID)
+}
+
+func (h *ruleHealths) reset() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.health = make(map[int64]map[string]RuleHealth)
+}
+
+// RecordEvaluation records the outcome of an evaluation of the alert rule: its time, its duration,
+// and its error if it failed, including errors of the evaluation of some of its instances.
+func (st *Manager) RecordEvaluation(orgID int64, ruleUID string, evaluatedAt time.Time, duration time.Duration, err error) {
+	st.health.record(orgID, ruleUID, evaluatedAt, duration, err)
+}
+
+// GetRuleHealth returns the health of the alert rule, false if it was not evaluated yet.
+func (st *Manager) GetRuleHealth(orgID int64, ruleUID string) (RuleHealth, bool) {
+	return st.health.get(orgID, ruleUID)
+}
@@ -0,0 +1,45 @@
+package state_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestRuleHealth(t *testing.T) {
+	st := state.NewManager(log.New("test_rule_health"), nilMetrics, nil, nil, nil)
+	now := time.Date(2021, 3, 25, 0, 0, 0, 0, time.UTC)
+
+	_, ok := st.GetRuleHealth(1, "rule")
+	require.False(t, ok)
+
+	st.RecordEvaluation(1, "rule", now, time.Second, errors.New("query failed"))
+	st.RecordEvaluation(1, "rule", now.Add(time.Minute), 2*time.Second, errors.New("query timed out"))
+	health, ok := st.GetRuleHealth(1, "rule")
+	require.True(t, ok)
+	require.Equal(t, state.RuleHealth{
+		LastEvaluation:      now.Add(time.Minute),
+		EvaluationDuration:  2 * time.Second,
+		LastError:           errors.New("query timed out"),
+		ConsecutiveFailures: 2,
+	}, health)
+
+	// a successful evaluation resets the failures
+	st.RecordEvaluation(1, "rule", now.Add(2*time.Minute), time.Second, nil)
+	health, _ = st.GetRuleHealth(1, "rule")
+	require.NoError(t, health.LastError)
+	require.Equal(t, int64(0), health.ConsecutiveFailures)
+
+	// the health is per organization
+	_, ok = st.GetRuleHealth(2, "rule")
+	require.False(t, ok)
+
+	st.RemoveByRuleUID(1, "rule")
+	_, ok = st.GetRuleHealth(1, "rule")
+	require.False(t, ok)
+}
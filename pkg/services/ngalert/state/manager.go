@@ -5,6 +5,9 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -19,34 +22,73 @@ import (
 
 var ResendDelay = 30 * time.Second
 
+const (
+	// stateHistoryQueueSize is the number of changes of state waiting to be saved in the state
+	// history. Further changes are dropped, so that a slow database does not stall evaluations.
+	stateHistoryQueueSize = 10000
+	// stateHistoryBatchSize is the maximum number of changes of state saved at once.
+	stateHistoryBatchSize = 100
+)
+
 type Manager struct {
 	log     log.Logger
 	metrics *metrics.Metrics
 
 	cache       *cache
+	health      *ruleHealths
 	quit        chan struct{}
 	ResendDelay time.Duration
 
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
+	historyStore  store.StateHistoryStore
+	// history holds the changes of state waiting to be saved in historyStore.
+	history chan *ngModels.SaveAlertStateHistoryCommand
+
+	// dryRun is set for managers that only keep states in memory, see NewDryRunManager.
+	dryRun bool
 }
 
-func NewManager(logger log.Logger, metrics *metrics.Metrics, ruleStore store.RuleStore, instanceStore store.InstanceStore) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.Metrics, ruleStore store.RuleStore, instanceStore store.InstanceStore, historyStore store.StateHistoryStore) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics),
+		health:        newRuleHealths(),
 		quit:          make(chan struct{}),
 		ResendDelay:   ResendDelay, // TODO: make this configurable
 		log:           logger,
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		historyStore:  historyStore,
 	}
 	go manager.recordMetrics()
+	if historyStore != nil {
+		manager.history = make(chan *ngModels.SaveAlertStateHistoryCommand, stateHistoryQueueSize)
+		go manager.saveStateHistoryLoop()
+	}
 	return manager
 }
 
+// NewDryRunManager returns a Manager that only keeps states in memory. It does not record
+// metrics, create annotations or delete alert instances from the database, and states become
+// stale relative to the time of the evaluation rather than the current time. This makes it
+// possible to replay the evaluations of a rule over a time range in the past.
+func NewDryRunManager(logger log.Logger) *Manager {
+	return &Manager{
+		cache:       newCache(logger, nil),
+		health:      newRuleHealths(),
+		quit:        make(chan struct{}),
+		ResendDelay: ResendDelay,
+		log:         logger,
+		dryRun:      true,
+	}
+}
+
 func (st *Manager) Close() {
-	st.quit <- struct{}{}
+	if st.dryRun {
+		return
+	}
+	close(st.quit)
 }
 
 func (st *Manager) Warm() {
@@ -87,25 +129,7 @@ func (st *Manager) Warm() {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -114,6 +138,62 @@ func (st *Manager) Warm() {
 	}
 }
 
+// WarmRule replaces the states of an alert rule in the cache with the alert instances
+// saved in the database. It is used when the rule was evaluated by another instance of
+// the cluster until now, so the states in the cache are outdated.
+func (st *Manager) WarmRule(alertRule *ngModels.AlertRule) {
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "uid", alertRule.UID, "msg", err.Error())
+		return
+	}
+
+	st.RemoveByRuleUID(alertRule.OrgID, alertRule.UID)
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(entry, alertRule))
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.ListAlertInstancesQueryResult, alertRule *ngModels.AlertRule) *State {
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheId:            cacheId,
+		Labels:             map[string]string(entry.Labels),
+		ResultLabels:       instanceResultLabels(entry.Labels, alertRule),
+		State:              translateInstanceState(entry.CurrentState),
+		Results:            []Evaluation{},
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        alertRule.Annotations,
+	}
+}
+
+// instanceResultLabels returns the labels of a saved alert instance without the labels of the
+// rule. The result labels are not saved, so a result label which has the same name as a rule
+// label cannot be told apart from it and is removed too, until the next evaluation.
+func instanceResultLabels(labels ngModels.InstanceLabels, alertRule *ngModels.AlertRule) data.Labels {
+	lbs := data.Labels{}
+	for k, v := range labels {
+		if _, ok := alertRule.Labels[k]; ok {
+			continue
+		}
+		if k == ngModels.RuleUIDLabel || k == ngModels.NamespaceUIDLabel || k == prometheusModel.AlertNameLabel {
+			continue
+		}
+		lbs[k] = v
+	}
+	return lbs
+}
+
 func (st *Manager) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
 	return st.cache.getOrCreate(alertRule, result)
 }
@@ -129,28 +209,35 @@ func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) (*State, error
 // ResetCache is used to ensure a clean cache on startup.
 func (st *Manager) ResetCache() {
 	st.cache.reset()
+	st.health.reset()
 }
 
 // RemoveByRuleUID deletes all entries in the state manager that match the given rule UID.
 func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
+	st.health.remove(orgID, ruleUID)
 }
 
 func (st *Manager) ProcessEvalResults(alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
 	processedResults := make(map[string]*State, len(results))
+	now := time.Now()
+	parents := st.firingDependencies(alertRule)
 	for _, result := range results {
-		s := st.setNextState(alertRule, result)
+		s := st.setNextState(alertRule, result, parents)
 		states = append(states, s)
 		processedResults[s.CacheId] = s
+		if st.dryRun {
+			now = result.EvaluatedAt
+		}
 	}
-	st.staleResultsHandler(alertRule, processedResults)
+	st.staleResultsHandler(alertRule, processedResults, now)
 	return states
 }
 
 //Set the current state based on evaluation results
-func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) *State {
+func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result, parents []firingDependency) *State {
 	currentState := st.getOrCreate(alertRule, result)
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -177,17 +264,84 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 	case eval.Pending: // we do not emit results with this state
 	}
 
+	currentState.resultSuppressed(result, parents)
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	currentState.Resolved = oldState == eval.Alerting && (currentState.State == eval.Normal || currentState.State == eval.Suppressed)
 
 	st.set(currentState)
-	if oldState != currentState.State {
+	if oldState != currentState.State && !st.dryRun {
+		st.saveStateHistory(currentState, result, oldState)
 		go st.createAlertAnnotation(currentState.State, alertRule, result, oldState)
 	}
 	return currentState
 }
 
+// saveStateHistory queues the change of state of an alert instance to be saved in the state
+// history store.
+func (st *Manager) saveStateHistory(s *State, result eval.Result, oldState eval.State) {
+	if st.history == nil {
+		return
+	}
+	values := make(ngModels.StateHistoryValues, len(result.Values))
+	for refID, v := range result.Values {
+		values[refID] = v.Value
+	}
+	cmd := &ngModels.SaveAlertStateHistoryCommand{
+		RuleOrgID:     s.OrgID,
+		RuleUID:       s.AlertRuleUID,
+		Labels:        ngModels.InstanceLabels(s.Labels),
+		PreviousState: ngModels.InstanceStateType(oldState.String()),
+		State:         ngModels.InstanceStateType(s.State.String()),
+		Values:        values,
+		EvaluatedAt:   result.EvaluatedAt,
+	}
+	select {
+	case st.history <- cmd:
+	default:
+		st.log.Warn("alert state history queue is full, dropping change of state", "uid", s.AlertRuleUID, "labels", s.Labels.String())
+	}
+}
+
+// saveStateHistoryLoop saves the queued changes of state in batches until the manager is closed,
+// then saves the changes still queued.
+func (st *Manager) saveStateHistoryLoop() {
+	for {
+		select {
+		case cmd := <-st.history:
+			st.saveStateHistoryBatch(cmd)
+		case <-st.quit:
+			for {
+				select {
+				case cmd := <-st.history:
+					st.saveStateHistoryBatch(cmd)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// saveStateHistoryBatch saves cmd along with the changes of state queued after it, up to
+// stateHistoryBatchSize.
+func (st *Manager) saveStateHistoryBatch(cmd *ngModels.SaveAlertStateHistoryCommand) {
+	batch := []*ngModels.SaveAlertStateHistoryCommand{cmd}
+loop:
+	for len(batch) < stateHistoryBatchSize {
+		select {
+		case cmd := <-st.history:
+			batch = append(batch, cmd)
+		default:
+			break loop
+		}
+	}
+	if err := st.historyStore.SaveAlertStateHistory(batch...); err != nil {
+		st.log.Error("failed to save alert state history", "count", len(batch), "err", err)
+	}
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	return st.cache.getAll(orgID)
 }
@@ -196,6 +350,20 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// GetLoadedDimensions returns the labels of the instances of the alert rule whose condition
+// was met on the last evaluation, i.e. that are Pending or Alerting. These are the labels of
+// the evaluation results, without the labels the state manager adds to each instance.
+func (st *Manager) GetLoadedDimensions(alertRule *ngModels.AlertRule) []data.Labels {
+	var dims []data.Labels
+	for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		if s.State != eval.Alerting && s.State != eval.Pending {
+			continue
+		}
+		dims = append(dims, s.ResultLabels.Copy())
+	}
+	return dims
+}
+
 func (st *Manager) recordMetrics() {
 	// TODO: parameterize?
 	// Setting to a reasonable default scrape interval for Prometheus.
@@ -226,6 +394,8 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 		return eval.Alerting
 	case state == ngModels.InstanceStateNormal:
 		return eval.Normal
+	case state == ngModels.InstanceStateSuppressed:
+		return eval.Suppressed
 	default:
 		return eval.Error
 	}
@@ -276,13 +446,16 @@ func (st *Manager) createAlertAnnotation(new eval.State, alertRule *ngModels.Ale
 	}
 }
 
-func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State) {
+func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State, now time.Time) {
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(now, s.LastEvaluationTime, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+			if st.dryRun {
+				continue
+			}
 			ilbs := ngModels.InstanceLabels(s.Labels)
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
@@ -296,6 +469,6 @@ func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map
 	}
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+func isItStale(now, lastEval time.Time, intervalSeconds int64) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(now)
 }
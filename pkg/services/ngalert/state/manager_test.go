@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -67,7 +68,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -120,7 +122,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_1":             "test",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"instance_label_1": "test"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -143,7 +146,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_2":             "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label_2": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -200,7 +204,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -260,7 +265,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -331,7 +337,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -399,7 +406,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -462,7 +470,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -525,7 +534,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -588,7 +598,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -651,7 +662,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -715,7 +727,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -779,7 +792,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					State: eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -836,7 +850,8 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"job":                          "prod/grafana",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"cluster": "us-central-1", "namespace": "prod", "pod": "grafana"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -853,7 +868,7 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil)
+		st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(tc.alertRule, res)
@@ -929,7 +944,8 @@ func TestStaleResultsHandler(t *testing.T) {
 						"alertname":                    rule.Title,
 						"test1":                        "testValue1",
 					},
-					State: eval.Normal,
+					ResultLabels: data.Labels{"test1": "testValue1"},
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(3 * time.Minute),
@@ -948,7 +964,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_stale_results_handler"), nilMetrics, dbstore, dbstore)
+		st := state.NewManager(log.New("test_stale_results_handler"), nilMetrics, dbstore, dbstore, dbstore)
 		st.Warm()
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
@@ -968,3 +984,163 @@ func TestStaleResultsHandler(t *testing.T) {
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
 	}
 }
+
+func TestProcessEvalResultsWithDependencies(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	parentRule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "cluster_down",
+		UID:             "parent_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+	}
+	childRule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "service_down",
+		UID:             "child_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+		DependsOn: []models.RuleDependency{
+			{RuleUID: parentRule.UID, Equal: []string{"cluster"}},
+		},
+	}
+
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil, nil)
+	getState := func(cluster string) *state.State {
+		for _, s := range st.GetStatesForRuleUID(childRule.OrgID, childRule.UID) {
+			if s.Labels["cluster"] == cluster {
+				return s
+			}
+		}
+		t.Fatalf("no state for cluster %s", cluster)
+		return nil
+	}
+
+	// the child rule fires in both clusters
+	st.ProcessEvalResults(childRule, eval.Results{
+		{Instance: data.Labels{"cluster": "a"}, State: eval.Alerting, EvaluatedAt: evaluationTime},
+		{Instance: data.Labels{"cluster": "b"}, State: eval.Alerting, EvaluatedAt: evaluationTime},
+	})
+	require.Equal(t, eval.Alerting, getState("a").State)
+	require.Equal(t, eval.Alerting, getState("b").State)
+
+	// the parent rule fires in cluster a only
+	st.ProcessEvalResults(parentRule, eval.Results{
+		{Instance: data.Labels{"cluster": "a"}, State: eval.Alerting, EvaluatedAt: evaluationTime},
+		{Instance: data.Labels{"cluster": "b"}, State: eval.Normal, EvaluatedAt: evaluationTime},
+	})
+
+	next := evaluationTime.Add(10 * time.Second)
+	st.ProcessEvalResults(childRule, eval.Results{
+		{Instance: data.Labels{"cluster": "a"}, State: eval.Alerting, EvaluatedAt: next},
+		{Instance: data.Labels{"cluster": "b"}, State: eval.Alerting, EvaluatedAt: next},
+	})
+	suppressed := getState("a")
+	assert.Equal(t, eval.Suppressed, suppressed.State)
+	assert.True(t, suppressed.Resolved)
+	assert.Equal(t, next, suppressed.EndsAt)
+	assert.Equal(t, eval.Alerting, getState("b").State)
+
+	// the child rule fires again once the parent rule is resolved
+	st.ProcessEvalResults(parentRule, eval.Results{
+		{Instance: data.Labels{"cluster": "a"}, State: eval.Normal, EvaluatedAt: next},
+		{Instance: data.Labels{"cluster": "b"}, State: eval.Normal, EvaluatedAt: next},
+	})
+	last := next.Add(10 * time.Second)
+	st.ProcessEvalResults(childRule, eval.Results{
+		{Instance: data.Labels{"cluster": "a"}, State: eval.Alerting, EvaluatedAt: last},
+		{Instance: data.Labels{"cluster": "b"}, State: eval.Alerting, EvaluatedAt: last},
+	})
+	unsuppressed := getState("a")
+	assert.Equal(t, eval.Alerting, unsuppressed.State)
+	assert.Equal(t, last, unsuppressed.StartsAt)
+}
+
+func TestGetLoadedDimensions(t *testing.T) {
+	evaluationTime := time.Date(2021, 3, 25, 0, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		Labels:          map[string]string{"severity": "critical", "host": "rule_host"},
+		IntervalSeconds: 10,
+	}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil, nil)
+	st.ProcessEvalResults(rule, eval.Results{
+		{Instance: data.Labels{"host": "a", "pod": "a-1", "alertname": "series_name"}, State: eval.Alerting, EvaluatedAt: evaluationTime},
+		{Instance: data.Labels{"host": "b", "pod": "b-1"}, State: eval.Normal, EvaluatedAt: evaluationTime},
+	})
+
+	// The labels of the rule and the state manager take precedence over the labels of the
+	// series, but the loaded dimensions are the labels of the series.
+	require.Equal(t, []data.Labels{{"host": "a", "pod": "a-1", "alertname": "series_name"}}, st.GetLoadedDimensions(rule))
+}
+
+type fakeStateHistoryStore struct {
+	mtx     sync.Mutex
+	batches [][]*models.SaveAlertStateHistoryCommand
+	block   chan struct{}
+}
+
+func (f *fakeStateHistoryStore) SaveAlertStateHistory(cmds ...*models.SaveAlertStateHistoryCommand) error {
+	<-f.block
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.batches = append(f.batches, cmds)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) saved() (batches, cmds int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, b := range f.batches {
+		cmds += len(b)
+	}
+	return len(f.batches), cmds
+}
+
+func (f *fakeStateHistoryStore) ListAlertStateHistory(*models.ListAlertStateHistoryQuery) error {
+	return nil
+}
+
+func (f *fakeStateHistoryStore) DeleteAlertStateHistoryBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestStateHistoryIsSavedAsynchronously(t *testing.T) {
+	evaluationTime := time.Date(2021, 3, 25, 0, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+	}
+	historyStore := &fakeStateHistoryStore{block: make(chan struct{})}
+	st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil, historyStore)
+
+	// The evaluations are not blocked by the store.
+	for i := 0; i < 3; i++ {
+		var results eval.Results
+		for _, host := range []string{"a", "b"} {
+			s := eval.Alerting
+			if i%2 == 1 {
+				s = eval.Normal
+			}
+			results = append(results, eval.Result{Instance: data.Labels{"host": host}, State: s, EvaluatedAt: evaluationTime.Add(time.Duration(i) * time.Minute)})
+		}
+		st.ProcessEvalResults(rule, results)
+	}
+
+	close(historyStore.block)
+	st.Close()
+	require.Eventually(t, func() bool {
+		_, cmds := historyStore.saved()
+		return cmds == 6
+	}, time.Second, 10*time.Millisecond)
+	batches, _ := historyStore.saved()
+	require.Less(t, batches, 6, "the changes of state should be saved in batches")
+}
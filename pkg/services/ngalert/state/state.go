@@ -22,7 +22,11 @@ type State struct {
 	LastSentAt         time.Time
 	Annotations        map[string]string
 	Labels             data.Labels
-	Error              error
+	// ResultLabels are the labels of the evaluation result, without the labels of the rule.
+	ResultLabels data.Labels
+	Error        error
+	// TemplateError is the error of the last expansion of the templates of the labels and annotations, if any.
+	TemplateError error
 }
 
 type Evaluation struct {
@@ -112,12 +116,25 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 	}
 }
 
-func (a *State) NeedsSending(resendDelay time.Duration) bool {
-	if a.State != eval.Alerting && a.State != eval.Normal {
-		return false
+// resultSuppressed is applied after the result of the evaluation. The instance is suppressed
+// when it would be Pending or Alerting but a firing instance of a rule it depends on matches it.
+// The instance ends, so that it is resolved in the Alertmanager if it was Alerting, and
+// starts over once it is no longer suppressed.
+func (a *State) resultSuppressed(result eval.Result, parents []firingDependency) {
+	if a.State != eval.Alerting && a.State != eval.Pending {
+		return
 	}
+	if !isSuppressed(a.Labels, parents) {
+		return
+	}
+	a.StartsAt = result.EvaluatedAt
+	a.EndsAt = result.EvaluatedAt
+	a.State = eval.Suppressed
+}
 
-	if a.State == eval.Normal && !a.Resolved {
+func (a *State) NeedsSending(resendDelay time.Duration) bool {
+	// resolved alerts are sent when the instance becomes Normal or Suppressed
+	if a.State != eval.Alerting && !a.Resolved {
 		return false
 	}
 	// if LastSentAt is before or equal to LastEvaluationTime + resendDelay, send again
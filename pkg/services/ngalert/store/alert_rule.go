@@ -17,6 +17,7 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/prometheus/common/model"
 )
 
 // AlertRuleMaxTitleLength is the maximum length of the alert rule title
@@ -29,11 +30,25 @@ type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// CreateWithUIDs creates the rules whose UID is not found with this UID instead of failing.
+	// It is used by provisioning, where the UIDs of the rules are set in the files.
+	CreateWithUIDs bool
+}
+
+// SetAlertRulePausedCmd pauses or resumes the evaluation of an alert rule.
+type SetAlertRulePausedCmd struct {
+	OrgID  int64
+	UID    string
+	Paused bool
+	// Until is the end of the pause, the rule is paused until it is resumed if it is zero.
+	Until time.Time
 }
 
 type UpsertRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// CreateWithUID creates the rule with the UID of New if no rule has this UID.
+	CreateWithUID bool
 }
 
 // Store is the interface for persisting alert rules and instances
@@ -52,6 +67,7 @@ type RuleStore interface {
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 	UpsertAlertRules([]UpsertRule) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	SetAlertRulePaused(SetAlertRulePausedCmd) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -192,23 +208,28 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 			if r.Existing == nil && r.New.UID != "" {
 				// check by UID
 				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
+				switch {
+				case err == nil:
+					r.Existing = existingAlertRule
+				case errors.Is(err, ngmodels.ErrAlertRuleNotFound) && r.CreateWithUID:
+					// the rule is created with its UID
+				case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+					return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
+				default:
 					return err
 				}
-				r.Existing = existingAlertRule
 			}
 
 			var parentVersion int64
 			switch r.Existing {
 			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+				if r.New.UID == "" {
+					uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
+					if err != nil {
+						return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+					}
+					r.New.UID = uid
 				}
-				r.New.UID = uid
 
 				if r.New.IntervalSeconds == 0 {
 					r.New.IntervalSeconds = st.DefaultIntervalSeconds
@@ -264,6 +285,10 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 					r.New.NoDataState = r.Existing.NoDataState
 				}
 
+				// the pause is not part of the definition of the rule
+				r.New.IsPaused = r.Existing.IsPaused
+				r.New.PausedUntil = r.Existing.PausedUntil
+
 				if err := st.validateAlertRule(r.New); err != nil {
 					return err
 				}
@@ -295,6 +320,9 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 				NoDataState:      r.New.NoDataState,
 				ExecErrState:     r.New.ExecErrState,
 				For:              r.New.For,
+				Record:           r.New.Record,
+				DependsOn:        r.New.DependsOn,
+				Evaluation:       r.New.Evaluation,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
 			})
@@ -421,7 +449,7 @@ func (st DBstore) GetNamespaceByTitle(namespace string, orgID int64, user *model
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, interval_seconds, version, is_paused, paused_until FROM alert_rule"
 		if err := sess.SQL(q).Find(&alerts); err != nil {
 			return err
 		}
@@ -431,6 +459,25 @@ func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery
 	})
 }
 
+// SetAlertRulePaused pauses or resumes the evaluation of an alert rule, without changing its version.
+func (st DBstore) SetAlertRulePaused(cmd SetAlertRulePausedCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		rule, err := getAlertRuleByUID(sess, cmd.UID, cmd.OrgID)
+		if err != nil {
+			return err
+		}
+		rule.IsPaused = cmd.Paused
+		rule.PausedUntil = time.Time{}
+		if cmd.Paused {
+			rule.PausedUntil = cmd.Until
+		}
+		if _, err := sess.ID(rule.ID).Cols("is_paused", "paused_until").Update(rule); err != nil {
+			return fmt.Errorf("failed to update rule %s: %w", rule.UID, err)
+		}
+		return nil
+	})
+}
+
 // GenerateNewAlertRuleUID generates a unique UID for a rule.
 // This is set as a variable so that the tests can override it.
 // The ruleTitle is only used by the mocked functions.
@@ -479,6 +526,28 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: no organisation is found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.IsRecordingRule() && !model.IsValidMetricName(model.LabelValue(alertRule.Record)) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record)
+	}
+
+	for _, d := range alertRule.DependsOn {
+		if d.RuleUID == "" {
+			return fmt.Errorf("%w: dependency without rule UID", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if d.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	if e := alertRule.Evaluation; e != nil {
+		if e.Timeout < 0 || e.MaxAttempts < 0 || (e.RetryBackoff != nil && *e.RetryBackoff < 0) {
+			return fmt.Errorf("%w: evaluation settings cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if time.Duration(e.Timeout) > time.Duration(alertRule.IntervalSeconds)*time.Second {
+			return fmt.Errorf("%w: evaluation timeout cannot be longer than the interval of the rule", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
 	return nil
 }
 
@@ -518,6 +587,9 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 				RuleGroup:       ruleGroup,
 				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
 				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				Record:          r.GrafanaManagedAlert.Record,
+				DependsOn:       r.GrafanaManagedAlert.DependsOn,
+				Evaluation:      r.GrafanaManagedAlert.Evaluation,
 			}
 
 			if r.ApiRuleNode != nil {
@@ -527,7 +599,8 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 			}
 
 			upsertRule := UpsertRule{
-				New: new,
+				New:           new,
+				CreateWithUID: cmd.CreateWithUIDs,
 			}
 
 			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
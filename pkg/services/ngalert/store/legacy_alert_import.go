@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+)
+
+// LegacyAlertImportStore imports the legacy dashboard alerts into unified alerting.
+type LegacyAlertImportStore interface {
+	ImportDashboardAlerts(ctx context.Context, cmd ualert.ImportCommand) (*ualert.ImportReport, error)
+}
+
+// ImportDashboardAlerts imports the selected legacy dashboard alerts of an organisation.
+func (st DBstore) ImportDashboardAlerts(ctx context.Context, cmd ualert.ImportCommand) (*ualert.ImportReport, error) {
+	return st.SQLStore.ImportDashboardAlerts(ctx, cmd)
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// NotificationDeliveryStore is the database interface used to record and query
+// the deliveries of notifications by the integrations of contact points.
+type NotificationDeliveryStore interface {
+	SaveNotificationDelivery(cmd *models.SaveNotificationDeliveryCommand) error
+	ListNotificationDeliveries(cmd *models.ListNotificationDeliveriesQuery) error
+	DeleteNotificationDeliveriesBefore(olderThan time.Time) (int64, error)
+}
+
+// SaveNotificationDelivery is a handler for recording the delivery of a notification.
+func (st DBstore) SaveNotificationDelivery(cmd *models.SaveNotificationDeliveryCommand) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		fingerprints, err := cmd.Fingerprints.ToDB()
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec(`INSERT INTO alert_notification_delivery
+			(org_id, receiver, integration, integration_name, integration_uid, fingerprints, status, status_code, error, duration_ms, sent_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			cmd.OrgID, cmd.Receiver, cmd.Integration, cmd.IntegrationName, cmd.IntegrationUID, string(fingerprints),
+			cmd.Status, cmd.StatusCode, cmd.Error, cmd.Duration.Milliseconds(), cmd.SentAt.Unix())
+		return err
+	})
+}
+
+// ListNotificationDeliveries is a handler for retrieving the deliveries of notifications
+// of an organisation, most recent first.
+func (st DBstore) ListNotificationDeliveries(cmd *models.ListNotificationDeliveriesQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		deliveries := make([]*models.NotificationDelivery, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_delivery WHERE org_id = ?", cmd.OrgID)
+
+		if cmd.Receiver != "" {
+			addToQuery(" AND receiver = ?", cmd.Receiver)
+		}
+
+		if cmd.Integration != "" {
+			addToQuery(" AND integration = ?", cmd.Integration)
+		}
+
+		if cmd.Status != "" {
+			addToQuery(" AND status = ?", cmd.Status)
+		}
+
+		if !cmd.From.IsZero() {
+			addToQuery(" AND sent_at >= ?", cmd.From.Unix())
+		}
+
+		if !cmd.To.IsZero() {
+			addToQuery(" AND sent_at <= ?", cmd.To.Unix())
+		}
+
+		if cmd.Fingerprint != "" {
+			// Fingerprints are hexadecimal, so a fingerprint with LIKE wildcards or quotes
+			// matches no delivery.
+			if strings.ContainsAny(cmd.Fingerprint, `%_\"`) {
+				cmd.Result = deliveries
+				return nil
+			}
+			// The fingerprints are stored as a JSON array of strings.
+			addToQuery(" AND fingerprints "+st.SQLStore.Dialect.LikeStr()+" ?", `%"`+cmd.Fingerprint+`"%`)
+		}
+
+		addToQuery(" ORDER BY sent_at DESC, id DESC")
+
+		if cmd.Limit > 0 {
+			addToQuery(st.SQLStore.Dialect.Limit(int64(cmd.Limit)))
+		}
+
+		if err := sess.SQL(s.String(), params...).Find(&deliveries); err != nil {
+			return err
+		}
+
+		cmd.Result = deliveries
+		return nil
+	})
+}
+
+// DeleteNotificationDeliveriesBefore deletes the deliveries recorded before the given time
+// and returns the number of deleted entries.
+func (st DBstore) DeleteNotificationDeliveriesBefore(olderThan time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE sent_at < ?", olderThan.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,98 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationDeliveryOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	start := time.Unix(1000, 0)
+	deliveries := []models.SaveNotificationDeliveryCommand{
+		{
+			Receiver:     "team-a",
+			Integration:  "slack",
+			Fingerprints: models.AlertFingerprints{"f1", "f2"},
+			Status:       models.NotificationDeliverySuccess,
+			StatusCode:   200,
+			Duration:     150 * time.Millisecond,
+			SentAt:       start,
+		},
+		{
+			Receiver:     "team-a",
+			Integration:  "webhook",
+			Fingerprints: models.AlertFingerprints{"f1"},
+			Status:       models.NotificationDeliveryFailure,
+			StatusCode:   503,
+			Error:        "failed to send HTTP request - status code 503",
+			Duration:     time.Second,
+			SentAt:       start.Add(time.Minute),
+		},
+		{
+			Receiver:     "team-b",
+			Integration:  "email",
+			Fingerprints: models.AlertFingerprints{"f3"},
+			Status:       models.NotificationDeliverySuccess,
+			SentAt:       start.Add(2 * time.Minute),
+		},
+	}
+	for _, cmd := range deliveries {
+		cmd.OrgID = 1
+		require.NoError(t, dbstore.SaveNotificationDelivery(&cmd))
+	}
+
+	t.Run("can list the deliveries of an organisation, most recent first", func(t *testing.T) {
+		q := &models.ListNotificationDeliveriesQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, "email", q.Result[0].Integration)
+		require.Equal(t, models.AlertFingerprints{"f1", "f2"}, q.Result[2].Fingerprints)
+		require.Equal(t, 200, q.Result[2].StatusCode)
+		require.Equal(t, int64(150), q.Result[2].DurationMs)
+		require.Equal(t, start.Unix(), q.Result[2].SentAt.Unix())
+	})
+
+	t.Run("can filter the deliveries", func(t *testing.T) {
+		q := &models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", Status: models.NotificationDeliveryFailure}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, 503, q.Result[0].StatusCode)
+
+		q = &models.ListNotificationDeliveriesQuery{OrgID: 1, Fingerprint: "f1", Limit: 1}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "webhook", q.Result[0].Integration)
+
+		q = &models.ListNotificationDeliveriesQuery{OrgID: 1, Fingerprint: "f2"}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "slack", q.Result[0].Integration)
+
+		q = &models.ListNotificationDeliveriesQuery{OrgID: 1, Fingerprint: "f_"}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 0)
+
+		q = &models.ListNotificationDeliveriesQuery{OrgID: 2}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 0)
+	})
+
+	t.Run("can delete old deliveries", func(t *testing.T) {
+		affected, err := dbstore.DeleteNotificationDeliveriesBefore(start.Add(90 * time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), affected)
+
+		q := &models.ListNotificationDeliveriesQuery{OrgID: 1}
+		require.NoError(t, dbstore.ListNotificationDeliveries(q))
+		require.Len(t, q.Result, 1)
+	})
+}
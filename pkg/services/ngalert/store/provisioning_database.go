@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ProvisioningStore keeps track of the alerting objects created from provisioning files.
+type ProvisioningStore interface {
+	GetAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType, objectKey string) (*models.AlertProvisioning, error)
+	ListAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType) ([]*models.AlertProvisioning, error)
+	SaveAlertProvisioning(p *models.AlertProvisioning) error
+	DeleteAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType, objectKey string) error
+}
+
+// GetAlertProvisioning returns how an alerting object is provisioned, or nil if it is not provisioned.
+func (st DBstore) GetAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType, objectKey string) (*models.AlertProvisioning, error) {
+	var result *models.AlertProvisioning
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		p := &models.AlertProvisioning{}
+		ok, err := sess.Where("org_id = ? AND object_type = ? AND object_key = ?", orgID, objectType, objectKey).Get(p)
+		if err != nil || !ok {
+			return err
+		}
+		result = p
+		return nil
+	})
+	return result, err
+}
+
+// ListAlertProvisioning returns the provisioned objects of the given type. If orgID is 0,
+// the objects of every organisation are returned, and if objectType is empty, those of every type.
+func (st DBstore) ListAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType) ([]*models.AlertProvisioning, error) {
+	result := make([]*models.AlertProvisioning, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := sess.Table("alert_provisioning")
+		if orgID != 0 {
+			q = q.Where("org_id = ?", orgID)
+		}
+		if objectType != "" {
+			q = q.Where("object_type = ?", objectType)
+		}
+		return q.Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+// SaveAlertProvisioning records that an alerting object is provisioned, or updates the record if it exists.
+func (st DBstore) SaveAlertProvisioning(p *models.AlertProvisioning) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		p.Updated = time.Now().Unix()
+		existing := &models.AlertProvisioning{}
+		ok, err := sess.Where("org_id = ? AND object_type = ? AND object_key = ?", p.OrgID, p.ObjectType, p.ObjectKey).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_, err = sess.Insert(p)
+			return err
+		}
+		p.ID = existing.ID
+		_, err = sess.ID(p.ID).AllCols().Update(p)
+		return err
+	})
+}
+
+// DeleteAlertProvisioning deletes the record of a provisioned alerting object.
+func (st DBstore) DeleteAlertProvisioning(orgID int64, objectType models.ProvisionedObjectType, objectKey string) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_provisioning WHERE org_id = ? AND object_type = ? AND object_key = ?", orgID, objectType, objectKey)
+		return err
+	})
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// SchedulerInstanceStore is the database interface used by the schedulers of a Grafana
+// cluster to advertise that they are alive, so the alert rules can be sharded among them.
+type SchedulerInstanceStore interface {
+	HeartbeatSchedulerInstance(instanceID string, now time.Time) error
+	ListSchedulerInstances(since time.Time) ([]string, error)
+	DeleteSchedulerInstancesBefore(olderThan time.Time) error
+	DeleteSchedulerInstance(instanceID string) error
+}
+
+// HeartbeatSchedulerInstance records that the scheduler instance is alive at the given time.
+func (st DBstore) HeartbeatSchedulerInstance(instanceID string, now time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE alert_scheduler_instance SET last_heartbeat = ? WHERE instance_id = ?", now.Unix(), instanceID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil || affected > 0 {
+			return err
+		}
+		_, err = sess.Exec("INSERT INTO alert_scheduler_instance (instance_id, last_heartbeat) VALUES (?, ?)", instanceID, now.Unix())
+		return err
+	})
+}
+
+// ListSchedulerInstances returns the IDs of the scheduler instances whose last heartbeat
+// is not older than the given time, sorted by ID.
+func (st DBstore) ListSchedulerInstances(since time.Time) ([]string, error) {
+	instances := make([]string, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT instance_id FROM alert_scheduler_instance WHERE last_heartbeat >= ? ORDER BY instance_id", since.Unix()).Find(&instances)
+	})
+	return instances, err
+}
+
+// DeleteSchedulerInstancesBefore deletes the scheduler instances whose last heartbeat is older than the given time.
+func (st DBstore) DeleteSchedulerInstancesBefore(olderThan time.Time) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_instance WHERE last_heartbeat < ?", olderThan.Unix())
+		return err
+	})
+}
+
+// DeleteSchedulerInstance deletes a scheduler instance, e.g. when it shuts down.
+func (st DBstore) DeleteSchedulerInstance(instanceID string) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_instance WHERE instance_id = ?", instanceID)
+		return err
+	})
+}
@@ -0,0 +1,36 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerInstanceOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	start := time.Unix(1000, 0)
+
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("b", start))
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("a", start))
+	require.NoError(t, dbstore.HeartbeatSchedulerInstance("a", start.Add(time.Minute)))
+
+	instances, err := dbstore.ListSchedulerInstances(start)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, instances)
+
+	instances, err = dbstore.ListSchedulerInstances(start.Add(30 * time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, instances)
+
+	require.NoError(t, dbstore.DeleteSchedulerInstancesBefore(start.Add(30*time.Second)))
+	require.NoError(t, dbstore.DeleteSchedulerInstance("a"))
+	instances, err = dbstore.ListSchedulerInstances(time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Empty(t, instances)
+}
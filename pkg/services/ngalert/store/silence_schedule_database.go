@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SilenceScheduleStore is the database interface of the recurring silences.
+type SilenceScheduleStore interface {
+	GetSilenceSchedule(orgID int64, uid string) (*models.SilenceSchedule, error)
+	ListSilenceSchedules(orgID int64) ([]*models.SilenceSchedule, error)
+	SaveSilenceSchedule(s *models.SilenceSchedule) error
+	DeleteSilenceSchedule(orgID int64, uid string) error
+}
+
+// GetSilenceSchedule returns the silence schedule with the given UID, or models.ErrSilenceScheduleNotFound.
+func (st DBstore) GetSilenceSchedule(orgID int64, uid string) (*models.SilenceSchedule, error) {
+	var result *models.SilenceSchedule
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		s := &models.SilenceSchedule{}
+		ok, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(s)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrSilenceScheduleNotFound
+		}
+		result = s
+		return nil
+	})
+	return result, err
+}
+
+// ListSilenceSchedules returns the silence schedules of the organisation, or of every organisation if orgID is 0.
+func (st DBstore) ListSilenceSchedules(orgID int64) ([]*models.SilenceSchedule, error) {
+	result := make([]*models.SilenceSchedule, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := sess.Table("alert_silence_schedule")
+		if orgID != 0 {
+			q = q.Where("org_id = ?", orgID)
+		}
+		return q.Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+// SaveSilenceSchedule creates the silence schedule, with a new UID if it has none, or updates it if it exists.
+func (st DBstore) SaveSilenceSchedule(s *models.SilenceSchedule) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		now := time.Now().Unix()
+		s.Updated = now
+		if s.UID == "" {
+			s.UID = util.GenerateShortUID()
+		}
+		existing := &models.SilenceSchedule{}
+		ok, err := sess.Where("org_id = ? AND uid = ?", s.OrgID, s.UID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.Created = now
+			_, err = sess.Insert(s)
+			return err
+		}
+		s.ID = existing.ID
+		s.Created = existing.Created
+		s.CreatedBy = existing.CreatedBy
+		_, err = sess.ID(s.ID).AllCols().Update(s)
+		return err
+	})
+}
+
+// DeleteSilenceSchedule deletes the silence schedule, or returns models.ErrSilenceScheduleNotFound.
+func (st DBstore) DeleteSilenceSchedule(orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_silence_schedule WHERE org_id = ? AND uid = ?", orgID, uid)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return models.ErrSilenceScheduleNotFound
+		}
+		return nil
+	})
+}
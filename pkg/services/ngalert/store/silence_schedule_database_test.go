@@ -0,0 +1,68 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestSilenceScheduleOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	schedule := &models.SilenceSchedule{
+		OrgID:     1,
+		Comment:   "nightly batch",
+		CreatedBy: "admin",
+		Matchers:  []string{`job="batch"`},
+		Cron:      "0 22 * * *",
+		Duration:  2 * time.Hour,
+		Location:  "Europe/Paris",
+	}
+	require.NoError(t, dbstore.SaveSilenceSchedule(schedule))
+	require.NotEmpty(t, schedule.UID)
+
+	t.Run("can get a silence schedule", func(t *testing.T) {
+		s, err := dbstore.GetSilenceSchedule(1, schedule.UID)
+		require.NoError(t, err)
+		require.Equal(t, []string{`job="batch"`}, s.Matchers)
+		require.Equal(t, 2*time.Hour, s.Duration)
+		require.Equal(t, "Europe/Paris", s.Location)
+
+		_, err = dbstore.GetSilenceSchedule(2, schedule.UID)
+		require.ErrorIs(t, err, models.ErrSilenceScheduleNotFound)
+	})
+
+	t.Run("can update a silence schedule but not its creator", func(t *testing.T) {
+		update := &models.SilenceSchedule{
+			OrgID:    1,
+			UID:      schedule.UID,
+			Matchers: []string{`job="batch"`, `env="prod"`},
+			Cron:     "0 23 * * *",
+			Duration: time.Hour,
+		}
+		require.NoError(t, dbstore.SaveSilenceSchedule(update))
+
+		schedules, err := dbstore.ListSilenceSchedules(1)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		require.Equal(t, "0 23 * * *", schedules[0].Cron)
+		require.Equal(t, "admin", schedules[0].CreatedBy)
+		require.Len(t, schedules[0].Matchers, 2)
+	})
+
+	t.Run("can delete a silence schedule", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSilenceSchedule(1, schedule.UID))
+		require.ErrorIs(t, dbstore.DeleteSilenceSchedule(1, schedule.UID), models.ErrSilenceScheduleNotFound)
+
+		schedules, err := dbstore.ListSilenceSchedules(0)
+		require.NoError(t, err)
+		require.Empty(t, schedules)
+	})
+}
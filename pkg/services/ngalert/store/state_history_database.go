@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore is the database interface used to record and query
+// the changes of state of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(cmds ...*models.SaveAlertStateHistoryCommand) error
+	ListAlertStateHistory(cmd *models.ListAlertStateHistoryQuery) error
+	DeleteAlertStateHistoryBefore(olderThan time.Time) (int64, error)
+}
+
+// SaveAlertStateHistory is a handler for recording changes of the state of alert instances.
+// The changes are saved in a single transaction.
+func (st DBstore) SaveAlertStateHistory(cmds ...*models.SaveAlertStateHistoryCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, cmd := range cmds {
+			labelTupleJSON, labelsHash, err := cmd.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			values, err := cmd.Values.ToDB()
+			if err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec(`INSERT INTO alert_state_history
+				(rule_org_id, rule_uid, labels, labels_hash, previous_state, state, evaluation_values, evaluated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				cmd.RuleOrgID, cmd.RuleUID, labelTupleJSON, labelsHash, cmd.PreviousState, cmd.State, string(values), cmd.EvaluatedAt.Unix()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stateHistoryPageSize is the number of entries read at a time when the history is filtered by
+// labels.
+const stateHistoryPageSize = 1000
+
+// ListAlertStateHistory is a handler for retrieving the changes of state of the alert instances
+// of an organisation, most recent first.
+func (st DBstore) ListAlertStateHistory(cmd *models.ListAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		// Matchers are applied on the decoded labels, so the entries are read in pages until
+		// enough of them match.
+		pageSize := cmd.Limit
+		if len(cmd.Matchers) > 0 && pageSize < stateHistoryPageSize {
+			pageSize = stateHistoryPageSize
+		}
+
+		cmd.Result = make([]*models.AlertStateHistoryEntry, 0)
+		var last *models.AlertStateHistoryEntry
+		for {
+			s := strings.Builder{}
+			params := make([]interface{}, 0)
+
+			addToQuery := func(stmt string, p ...interface{}) {
+				s.WriteString(stmt)
+				params = append(params, p...)
+			}
+
+			addToQuery("SELECT * FROM alert_state_history WHERE rule_org_id = ?", cmd.RuleOrgID)
+
+			if cmd.RuleUID != "" {
+				addToQuery(" AND rule_uid = ?", cmd.RuleUID)
+			}
+
+			if !cmd.From.IsZero() {
+				addToQuery(" AND evaluated_at >= ?", cmd.From.Unix())
+			}
+
+			if !cmd.To.IsZero() {
+				addToQuery(" AND evaluated_at <= ?", cmd.To.Unix())
+			}
+
+			if last != nil {
+				addToQuery(" AND (evaluated_at < ? OR (evaluated_at = ? AND id < ?))", last.EvaluatedAt.Unix(), last.EvaluatedAt.Unix(), last.ID)
+			}
+
+			addToQuery(" ORDER BY evaluated_at DESC, id DESC")
+
+			if pageSize > 0 {
+				addToQuery(st.SQLStore.Dialect.Limit(int64(pageSize)))
+			}
+
+			entries := make([]*models.AlertStateHistoryEntry, 0)
+			if err := sess.SQL(s.String(), params...).Find(&entries); err != nil {
+				return err
+			}
+
+			for _, e := range entries {
+				if !cmd.MatchesLabels(e.Labels) {
+					continue
+				}
+				cmd.Result = append(cmd.Result, e)
+				if cmd.Limit > 0 && len(cmd.Result) == cmd.Limit {
+					return nil
+				}
+			}
+
+			if pageSize == 0 || len(entries) < pageSize {
+				return nil
+			}
+			last = entries[len(entries)-1]
+		}
+	})
+}
+
+// DeleteAlertStateHistoryBefore deletes the changes of state recorded before the given time
+// and returns the number of deleted entries.
+func (st DBstore) DeleteAlertStateHistoryBefore(olderThan time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE evaluated_at < ?", olderThan.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,130 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+	start := time.Unix(1000, 0)
+	value := 42.0
+
+	transitions := []models.SaveAlertStateHistoryCommand{
+		{
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStatePending,
+			Values:        models.StateHistoryValues{"B": &value},
+			EvaluatedAt:   start,
+		},
+		{
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStatePending,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   start.Add(time.Minute),
+		},
+		{
+			Labels:        models.InstanceLabels{"host": "b"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   start.Add(2 * time.Minute),
+		},
+	}
+	for _, cmd := range transitions {
+		cmd.RuleOrgID = alertRule.OrgID
+		cmd.RuleUID = alertRule.UID
+		require.NoError(t, dbstore.SaveAlertStateHistory(&cmd))
+	}
+
+	t.Run("can list the history of a rule, most recent first", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{
+			RuleOrgID: alertRule.OrgID,
+			RuleUID:   alertRule.UID,
+		}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, models.InstanceLabels{"host": "b"}, q.Result[0].Labels)
+		require.Equal(t, models.InstanceStatePending, q.Result[2].State)
+		require.Equal(t, models.InstanceStateNormal, q.Result[2].PreviousState)
+		require.Equal(t, models.StateHistoryValues{"B": &value}, q.Result[2].Values)
+		require.Equal(t, start.Unix(), q.Result[2].EvaluatedAt.Unix())
+	})
+
+	t.Run("can filter the history by labels and time range", func(t *testing.T) {
+		m, err := labels.NewMatcher(labels.MatchEqual, "host", "a")
+		require.NoError(t, err)
+		q := &models.ListAlertStateHistoryQuery{
+			RuleOrgID: alertRule.OrgID,
+			Matchers:  []*labels.Matcher{m},
+			From:      start.Add(30 * time.Second),
+		}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].State)
+	})
+
+	t.Run("can delete old history", func(t *testing.T) {
+		affected, err := dbstore.DeleteAlertStateHistoryBefore(start.Add(90 * time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), affected)
+
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID}
+		require.NoError(t, dbstore.ListAlertStateHistory(q))
+		require.Len(t, q.Result, 1)
+	})
+}
+
+func TestAlertStateHistoryOperations_LimitWithMatchers(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60)
+	start := time.Unix(1000, 0)
+
+	// The matching entry is older than more than a page of entries that do not match.
+	cmds := []*models.SaveAlertStateHistoryCommand{{
+		RuleOrgID:     alertRule.OrgID,
+		RuleUID:       alertRule.UID,
+		Labels:        models.InstanceLabels{"host": "a"},
+		PreviousState: models.InstanceStateNormal,
+		State:         models.InstanceStateFiring,
+		EvaluatedAt:   start,
+	}}
+	for i := 0; i < 1500; i++ {
+		cmds = append(cmds, &models.SaveAlertStateHistoryCommand{
+			RuleOrgID:     alertRule.OrgID,
+			RuleUID:       alertRule.UID,
+			Labels:        models.InstanceLabels{"host": "b"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   start.Add(time.Duration(i%10+1) * time.Second),
+		})
+	}
+	require.NoError(t, dbstore.SaveAlertStateHistory(cmds...))
+
+	m, err := labels.NewMatcher(labels.MatchEqual, "host", "a")
+	require.NoError(t, err)
+	q := &models.ListAlertStateHistoryQuery{
+		RuleOrgID: alertRule.OrgID,
+		Matchers:  []*labels.Matcher{m},
+		Limit:     1,
+	}
+	require.NoError(t, dbstore.ListAlertStateHistory(q))
+	require.Len(t, q.Result, 1)
+	require.Equal(t, start.Unix(), q.Result[0].EvaluatedAt.Unix())
+
+	q = &models.ListAlertStateHistoryQuery{RuleOrgID: alertRule.OrgID, Limit: 1200}
+	require.NoError(t, dbstore.ListAlertStateHistory(q))
+	require.Len(t, q.Result, 1200)
+}
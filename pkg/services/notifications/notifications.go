@@ -101,7 +101,7 @@ func (ns *NotificationService) Run(ctx context.Context) error {
 }
 
 func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.SendWebhookSync) error {
-	return ns.sendWebRequestSync(ctx, &Webhook{
+	webhook := &Webhook{
 		Url:         cmd.Url,
 		User:        cmd.User,
 		Password:    cmd.Password,
@@ -109,7 +109,10 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.
 		HttpMethod:  cmd.HttpMethod,
 		HttpHeader:  cmd.HttpHeader,
 		ContentType: cmd.ContentType,
-	})
+	}
+	err := ns.sendWebRequestSync(ctx, webhook)
+	cmd.StatusCode = webhook.StatusCode
+	return err
 }
 
 func subjectTemplateFunc(obj map[string]interface{}, value string) string {
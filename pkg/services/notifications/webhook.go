@@ -24,6 +24,9 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+
+	// StatusCode is set to the status code of the response.
+	StatusCode int
 }
 
 var netTransport = &http.Transport{
@@ -82,6 +85,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		}
 	}()
 
+	webhook.StatusCode = resp.StatusCode
 	if resp.StatusCode/100 == 2 {
 		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
 		// flushing the body enables the transport to reuse the same connection
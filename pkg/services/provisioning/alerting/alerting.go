@@ -0,0 +1,274 @@
+package alerting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultBaseIntervalSeconds       = 10
+	defaultIntervalSeconds     int64 = 6 * defaultBaseIntervalSeconds
+)
+
+// Provision alert rules, contact points and notification policies.
+func Provision(configDirectory string, cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) error {
+	baseInterval := cfg.AlertingBaseInterval
+	if baseInterval <= 0 {
+		baseInterval = defaultBaseIntervalSeconds
+	}
+
+	logger := log.New("provisioning.alerting")
+	st := &store.DBstore{
+		BaseInterval:           baseInterval * time.Second,
+		DefaultIntervalSeconds: defaultIntervalSeconds,
+		SQLStore:               sqlStore,
+		Logger:                 logger,
+	}
+	ap := newAlertingProvisioner(logger, st)
+	return ap.applyChanges(configDirectory)
+}
+
+// ConfigVersion returns a version of the provisioning files of the directory, which changes
+// when a file is added, removed or modified. It is empty if the directory cannot be read.
+func ConfigVersion(configDirectory string) string {
+	files, err := ioutil.ReadDir(configDirectory)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%s %d %d\n", file.Name(), file.ModTime().UnixNano(), file.Size())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// provisioningStore is the storage used by the AlertingProvisioner.
+type provisioningStore interface {
+	store.ProvisioningStore
+	UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error
+	DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ruleGroup string) ([]string, error)
+	SaveAlertmanagerConfiguration(cmd *ngmodels.SaveAlertmanagerConfigurationCmd) error
+}
+
+// AlertingProvisioner is responsible for provisioning alert rules and Alertmanager configurations.
+type AlertingProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+	store       provisioningStore
+}
+
+func newAlertingProvisioner(log log.Logger, store provisioningStore) AlertingProvisioner {
+	return AlertingProvisioner{
+		log:         log,
+		cfgProvider: &configReader{log: log},
+		store:       store,
+	}
+}
+
+func (ap *AlertingProvisioner) applyChanges(configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		// The provisioned objects are only deleted when the files they come from are read, not
+		// when the directory is missing, for example because of a typo or a missing mount.
+		ap.log.Warn("Alerting provisioning directory does not exist, the provisioned objects are kept", "path", configPath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	provisioned := make(map[provisionedObject]bool)
+	for _, cfg := range configs {
+		if err := ap.apply(cfg, provisioned); err != nil {
+			return err
+		}
+	}
+
+	return ap.deleteOrphans(provisioned)
+}
+
+// provisionedObject identifies an object in the alert_provisioning table.
+type provisionedObject struct {
+	orgID      int64
+	objectType ngmodels.ProvisionedObjectType
+	objectKey  string
+}
+
+func (ap *AlertingProvisioner) apply(cfg *alertingAsConfig, provisioned map[provisionedObject]bool) error {
+	for _, group := range cfg.RuleGroups {
+		if err := resolveOrgID(&group.OrgID, group.OrgName); err != nil {
+			return err
+		}
+		key := ngmodels.RuleGroupProvisioningKey(group.FolderUID, group.Group.Name)
+		provisioned[provisionedObject{group.OrgID, ngmodels.ProvisionedRuleGroup, key}] = true
+
+		if err := ap.provisionRuleGroup(cfg.Filename, group, key); err != nil {
+			return fmt.Errorf("failed to provision rule group %q of folder %q: %w", group.Group.Name, group.FolderUID, err)
+		}
+	}
+
+	for _, amConfig := range cfg.AlertmanagerConfigs {
+		if err := resolveOrgID(&amConfig.OrgID, amConfig.OrgName); err != nil {
+			return err
+		}
+		provisioned[provisionedObject{amConfig.OrgID, ngmodels.ProvisionedAlertmanagerConfig, ""}] = true
+
+		if err := ap.provisionAlertmanagerConfig(cfg.Filename, amConfig); err != nil {
+			return fmt.Errorf("failed to provision the Alertmanager configuration of organisation %d: %w", amConfig.OrgID, err)
+		}
+	}
+
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionRuleGroup(filename string, group *ruleGroupFromConfig, key string) error {
+	unchanged, err := ap.isUnchanged(group.OrgID, ngmodels.ProvisionedRuleGroup, key, group.Checksum)
+	if err != nil || unchanged {
+		return err
+	}
+
+	getFolder := &models.GetDashboardQuery{Uid: group.FolderUID, OrgId: group.OrgID}
+	if err := bus.Dispatch(getFolder); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return models.ErrFolderNotFound
+		}
+		return err
+	}
+	if !getFolder.Result.IsFolder {
+		return models.ErrFolderNotFound
+	}
+
+	ap.log.Info("Provisioning rule group", "org", group.OrgID, "folder", group.FolderUID, "group", group.Group.Name)
+	err = ap.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           group.OrgID,
+		NamespaceUID:    group.FolderUID,
+		RuleGroupConfig: group.Group,
+		CreateWithUIDs:  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ap.store.SaveAlertProvisioning(&ngmodels.AlertProvisioning{
+		OrgID:       group.OrgID,
+		ObjectType:  ngmodels.ProvisionedRuleGroup,
+		ObjectKey:   key,
+		Provisioner: filename,
+		Checksum:    group.Checksum,
+	})
+}
+
+func (ap *AlertingProvisioner) provisionAlertmanagerConfig(filename string, amConfig *alertmanagerConfigFromConfig) error {
+	unchanged, err := ap.isUnchanged(amConfig.OrgID, ngmodels.ProvisionedAlertmanagerConfig, "", amConfig.Checksum)
+	if err != nil || unchanged {
+		return err
+	}
+
+	if err := amConfig.Config.ProcessConfig(); err != nil {
+		return fmt.Errorf("failed to post process Alertmanager configuration: %w", err)
+	}
+
+	raw, err := json.Marshal(&amConfig.Config)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the Alertmanager configuration: %w", err)
+	}
+
+	ap.log.Info("Provisioning Alertmanager configuration", "org", amConfig.OrgID)
+	err = ap.store.SaveAlertmanagerConfiguration(&ngmodels.SaveAlertmanagerConfigurationCmd{
+		AlertmanagerConfiguration: string(raw),
+		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
+		OrgID:                     amConfig.OrgID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ap.store.SaveAlertProvisioning(&ngmodels.AlertProvisioning{
+		OrgID:       amConfig.OrgID,
+		ObjectType:  ngmodels.ProvisionedAlertmanagerConfig,
+		Provisioner: filename,
+		Checksum:    amConfig.Checksum,
+	})
+}
+
+// isUnchanged returns true if the object is already provisioned with the same content.
+func (ap *AlertingProvisioner) isUnchanged(orgID int64, objectType ngmodels.ProvisionedObjectType, key, checksum string) (bool, error) {
+	existing, err := ap.store.GetAlertProvisioning(orgID, objectType, key)
+	if err != nil {
+		return false, err
+	}
+	return existing != nil && existing.Checksum == checksum, nil
+}
+
+// deleteOrphans deletes the rule groups, and resets the Alertmanager configurations, that were
+// provisioned but are not in the provisioning files anymore.
+func (ap *AlertingProvisioner) deleteOrphans(provisioned map[provisionedObject]bool) error {
+	existing, err := ap.store.ListAlertProvisioning(0, "")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range existing {
+		if provisioned[provisionedObject{p.OrgID, p.ObjectType, p.ObjectKey}] {
+			continue
+		}
+
+		switch p.ObjectType {
+		case ngmodels.ProvisionedRuleGroup:
+			namespaceUID, ruleGroup := ngmodels.ParseRuleGroupProvisioningKey(p.ObjectKey)
+			ap.log.Info("Deleting rule group removed from the provisioning files", "org", p.OrgID, "folder", namespaceUID, "group", ruleGroup)
+			_, err := ap.store.DeleteRuleGroupAlertRules(p.OrgID, namespaceUID, ruleGroup)
+			if err != nil && !errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
+				return err
+			}
+		case ngmodels.ProvisionedAlertmanagerConfig:
+			ap.log.Info("Resetting Alertmanager configuration removed from the provisioning files", "org", p.OrgID)
+			err := ap.store.SaveAlertmanagerConfiguration(&ngmodels.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: notifier.DefaultConfiguration(),
+				ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
+				Default:                   true,
+				OrgID:                     p.OrgID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ap.store.DeleteAlertProvisioning(p.OrgID, p.ObjectType, p.ObjectKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveOrgID sets the organisation ID from the organisation name if only the name is set.
+func resolveOrgID(orgID *int64, orgName string) error {
+	if *orgID == 0 && orgName != "" {
+		getOrg := &models.GetOrgByNameQuery{Name: orgName}
+		if err := bus.Dispatch(getOrg); err != nil {
+			return err
+		}
+		*orgID = getOrg.Result.Id
+	}
+	return nil
+}
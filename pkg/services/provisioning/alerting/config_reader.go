@@ -0,0 +1,256 @@
+package alerting
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the alerting provisioning files from %q: %w", path, err)
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseAlertingConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := checkOrgIDAndOrgName(configs); err != nil {
+		return nil, err
+	}
+
+	if err := validateUniqueness(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseAlertingConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *alertingAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToAlertingFromConfig(filename)
+}
+
+// mapToAlertingFromConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingFromConfig(filename string) (*alertingAsConfig, error) {
+	r := &alertingAsConfig{Filename: filename}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for i, group := range cfg.RuleGroups {
+		rules := make([]map[string]interface{}, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			rules = append(rules, rule.Value())
+		}
+		content := map[string]interface{}{
+			"name":     group.Name.Value(),
+			"interval": group.Interval.Value(),
+			"rules":    rules,
+		}
+		raw, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+
+		var ruleGroup apimodels.PostableRuleGroupConfig
+		if err := json.Unmarshal(raw, &ruleGroup); err != nil {
+			return nil, fmt.Errorf("invalid rule group %d: %w", i+1, err)
+		}
+
+		r.RuleGroups = append(r.RuleGroups, &ruleGroupFromConfig{
+			OrgID:     group.OrgID.Value(),
+			OrgName:   group.OrgName.Value(),
+			FolderUID: group.FolderUID.Value(),
+			Group:     ruleGroup,
+			Checksum:  checksum(group.OrgID.Value(), group.OrgName.Value(), group.FolderUID.Value(), raw),
+		})
+	}
+
+	for i, amConfig := range cfg.AlertmanagerConfigs {
+		raw, err := json.Marshal(amConfig.Config.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		var config apimodels.PostableUserConfig
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, fmt.Errorf("invalid Alertmanager configuration %d: %w", i+1, err)
+		}
+
+		r.AlertmanagerConfigs = append(r.AlertmanagerConfigs, &alertmanagerConfigFromConfig{
+			OrgID:    amConfig.OrgID.Value(),
+			OrgName:  amConfig.OrgName.Value(),
+			Config:   config,
+			Checksum: checksum(amConfig.OrgID.Value(), amConfig.OrgName.Value(), "", raw),
+		})
+	}
+
+	return r, nil
+}
+
+// checksum returns the checksum of a provisioned object, so that it is only applied when it changes.
+func checksum(orgID int64, orgName, folderUID string, raw []byte) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\x00%s\x00%s\x00", orgID, orgName, folderUID)
+	_, _ = h.Write(raw)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func validateRequiredFields(configs []*alertingAsConfig) error {
+	for _, cfg := range configs {
+		var errStrings []string
+		for i, group := range cfg.RuleGroups {
+			if group.FolderUID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field folderUid", i+1))
+			}
+			if group.Group.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field name", i+1))
+			}
+			if group.Group.Interval <= 0 {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field interval", i+1))
+			}
+			for j, rule := range group.Group.Rules {
+				if rule.GrafanaManagedAlert == nil {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration is not a Grafana managed rule", j+1, i+1))
+					continue
+				}
+				if rule.GrafanaManagedAlert.UID == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration doesn't contain required field uid", j+1, i+1))
+				}
+				if rule.GrafanaManagedAlert.Title == "" {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %d in configuration doesn't contain required field title", j+1, i+1))
+				}
+			}
+		}
+
+		for i, amConfig := range cfg.AlertmanagerConfigs {
+			if amConfig.Config.AlertmanagerConfig.Route == nil {
+				errStrings = append(errStrings, fmt.Sprintf("Alertmanager configuration %d doesn't contain required field route", i+1))
+			}
+		}
+
+		if len(errStrings) != 0 {
+			return fmt.Errorf("%s: %s", cfg.Filename, strings.Join(errStrings, "\n"))
+		}
+	}
+
+	return nil
+}
+
+func checkOrgIDAndOrgName(configs []*alertingAsConfig) error {
+	check := func(orgID *int64, orgName string) error {
+		if *orgID < 1 {
+			if orgName == "" {
+				*orgID = 1
+			} else {
+				*orgID = 0
+			}
+			return nil
+		}
+		return utils.CheckOrgExists(*orgID)
+	}
+
+	for _, cfg := range configs {
+		for _, group := range cfg.RuleGroups {
+			if err := check(&group.OrgID, group.OrgName); err != nil {
+				return fmt.Errorf("failed to provision rule group %q: %w", group.Group.Name, err)
+			}
+		}
+		for _, amConfig := range cfg.AlertmanagerConfigs {
+			if err := check(&amConfig.OrgID, amConfig.OrgName); err != nil {
+				return fmt.Errorf("failed to provision Alertmanager configuration: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateUniqueness checks that a rule group, a rule UID and the Alertmanager configuration
+// of an organisation are provisioned at most once.
+func validateUniqueness(configs []*alertingAsConfig) error {
+	orgKey := func(orgID int64, orgName string) string {
+		if orgID > 0 {
+			return fmt.Sprint(orgID)
+		}
+		return "name:" + orgName
+	}
+
+	groups := make(map[string]string)
+	ruleUIDs := make(map[string]string)
+	amConfigs := make(map[string]string)
+	for _, cfg := range configs {
+		for _, group := range cfg.RuleGroups {
+			org := orgKey(group.OrgID, group.OrgName)
+			key := org + "/" + group.FolderUID + "/" + group.Group.Name
+			if other, ok := groups[key]; ok {
+				return fmt.Errorf("rule group %q of folder %q is provisioned by both %s and %s", group.Group.Name, group.FolderUID, other, cfg.Filename)
+			}
+			groups[key] = cfg.Filename
+
+			for _, rule := range group.Group.Rules {
+				key := org + "/" + rule.GrafanaManagedAlert.UID
+				if other, ok := ruleUIDs[key]; ok {
+					return fmt.Errorf("rule UID %q is provisioned by both %s and %s", rule.GrafanaManagedAlert.UID, other, cfg.Filename)
+				}
+				ruleUIDs[key] = cfg.Filename
+			}
+		}
+
+		for _, amConfig := range cfg.AlertmanagerConfigs {
+			org := orgKey(amConfig.OrgID, amConfig.OrgName)
+			if other, ok := amConfigs[org]; ok {
+				return fmt.Errorf("the Alertmanager configuration of organisation %s is provisioned by both %s and %s", org, other, cfg.Filename)
+			}
+			amConfigs[org] = cfg.Filename
+		}
+	}
+	return nil
+}
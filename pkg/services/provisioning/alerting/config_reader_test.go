@@ -0,0 +1,188 @@
+package alerting
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+var (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	orgName           = "./testdata/test-configs/org-name"
+	duplicateUIDs     = "./testdata/test-configs/duplicate-uids"
+	noRuleUID         = "./testdata/test-configs/no-rule-uid"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+)
+
+func setupTestEnv(t *testing.T) *sqlstore.SQLStore {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	for i := 1; i < 3; i++ {
+		orgCommand := models.CreateOrgCommand{Name: fmt.Sprintf("Main Org. %v", i)}
+		require.NoError(t, sqlstore.CreateOrg(&orgCommand))
+	}
+
+	_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		IsFolder: true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "provisioned",
+			"title": "Provisioned",
+		}),
+	})
+	require.NoError(t, err)
+
+	return sqlStore
+}
+
+func TestAlertingAsConfig(t *testing.T) {
+	setupTestEnv(t)
+	cfgProvider := &configReader{log: log.New("test logger")}
+
+	t.Run("can read correct properties", func(t *testing.T) {
+		cfg, err := cfgProvider.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].RuleGroups, 1)
+		group := cfg[0].RuleGroups[0]
+		require.Equal(t, int64(1), group.OrgID)
+		require.Equal(t, "provisioned", group.FolderUID)
+		require.Equal(t, "group-1", group.Group.Name)
+		require.Equal(t, model.Duration(time.Minute), group.Group.Interval)
+		require.NotEmpty(t, group.Checksum)
+
+		require.Len(t, group.Group.Rules, 2)
+		rule := group.Group.Rules[0]
+		require.Equal(t, "rule-1", rule.GrafanaManagedAlert.UID)
+		require.Equal(t, "rule 1", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, model.Duration(5*time.Minute), rule.ApiRuleNode.For)
+		require.Equal(t, map[string]string{"team": "backend"}, rule.ApiRuleNode.Labels)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, "-100", rule.GrafanaManagedAlert.Data[0].DatasourceUID)
+		require.Equal(t, "rule-2", group.Group.Rules[1].GrafanaManagedAlert.UID)
+
+		require.Len(t, cfg[0].AlertmanagerConfigs, 1)
+		amConfig := cfg[0].AlertmanagerConfigs[0]
+		require.Equal(t, int64(1), amConfig.OrgID)
+		require.Equal(t, "team-email", amConfig.Config.AlertmanagerConfig.Route.Receiver)
+		require.Len(t, amConfig.Config.AlertmanagerConfig.Receivers, 1)
+	})
+
+	t.Run("keeps the organisation name to be resolved when applied", func(t *testing.T) {
+		cfg, err := cfgProvider.readConfig(orgName)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+		require.Equal(t, int64(0), cfg[0].RuleGroups[0].OrgID)
+		require.Equal(t, "Main Org. 2", cfg[0].RuleGroups[0].OrgName)
+	})
+
+	t.Run("fails if a rule UID is provisioned twice", func(t *testing.T) {
+		_, err := cfgProvider.readConfig(duplicateUIDs)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `rule UID "rule-1" is provisioned by both`)
+	})
+
+	t.Run("fails if a rule has no UID", func(t *testing.T) {
+		_, err := cfgProvider.readConfig(noRuleUID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't contain required field uid")
+	})
+
+	t.Run("fails if the directory cannot be read", func(t *testing.T) {
+		_, err := cfgProvider.readConfig("./testdata/test-configs/does-not-exist")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("empty folder returns no configuration", func(t *testing.T) {
+		cfg, err := cfgProvider.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+}
+
+func TestAlertingProvisioner(t *testing.T) {
+	sqlStore := setupTestEnv(t)
+	st := &store.DBstore{
+		BaseInterval:           10 * time.Second,
+		DefaultIntervalSeconds: 60,
+		SQLStore:               sqlStore,
+		Logger:                 log.New("test logger"),
+	}
+	ap := newAlertingProvisioner(log.New("test logger"), st)
+
+	getRules := func(t *testing.T) map[string]*ngmodels.AlertRule {
+		t.Helper()
+		q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "provisioned", RuleGroup: "group-1"}
+		require.NoError(t, st.GetRuleGroupAlertRules(&q))
+		rules := make(map[string]*ngmodels.AlertRule, len(q.Result))
+		for _, r := range q.Result {
+			rules[r.UID] = r
+		}
+		return rules
+	}
+
+	t.Run("provisions the rule groups and the Alertmanager configuration", func(t *testing.T) {
+		require.NoError(t, ap.applyChanges(correctProperties))
+
+		rules := getRules(t)
+		require.Len(t, rules, 2)
+		require.Contains(t, rules, "rule-1")
+		require.Contains(t, rules, "rule-2")
+		require.Equal(t, int64(60), rules["rule-1"].IntervalSeconds)
+		require.Equal(t, ngmodels.OK, rules["rule-1"].NoDataState)
+		require.Equal(t, int64(1), rules["rule-1"].Version)
+
+		q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: 1}
+		require.NoError(t, st.GetLatestAlertmanagerConfiguration(&q))
+		require.Contains(t, q.Result.AlertmanagerConfiguration, "team-email")
+		require.False(t, q.Result.Default)
+
+		provisioned, err := st.ListAlertProvisioning(1, "")
+		require.NoError(t, err)
+		require.Len(t, provisioned, 2)
+	})
+
+	t.Run("does not update unchanged objects", func(t *testing.T) {
+		require.NoError(t, ap.applyChanges(correctProperties))
+
+		rules := getRules(t)
+		require.Len(t, rules, 2)
+		require.Equal(t, int64(1), rules["rule-1"].Version)
+	})
+
+	t.Run("keeps the objects if the directory does not exist", func(t *testing.T) {
+		require.NoError(t, ap.applyChanges("./testdata/test-configs/does-not-exist"))
+
+		require.Len(t, getRules(t), 2)
+		provisioned, err := st.ListAlertProvisioning(1, "")
+		require.NoError(t, err)
+		require.Len(t, provisioned, 2)
+	})
+
+	t.Run("deletes the objects removed from the files", func(t *testing.T) {
+		require.NoError(t, ap.applyChanges(emptyFolder))
+
+		require.Len(t, getRules(t), 0)
+
+		q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: 1}
+		require.NoError(t, st.GetLatestAlertmanagerConfiguration(&q))
+		require.True(t, q.Result.Default)
+
+		provisioned, err := st.ListAlertProvisioning(0, "")
+		require.NoError(t, err)
+		require.Len(t, provisioned, 0)
+	})
+}
@@ -0,0 +1,58 @@
+package alerting
+
+import (
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// alertingAsConfig is the normalized data object for alerting config data. Any config version should be
+// mappable to this type.
+type alertingAsConfig struct {
+	// Filename is the path of the file the configuration is read from.
+	Filename            string
+	RuleGroups          []*ruleGroupFromConfig
+	AlertmanagerConfigs []*alertmanagerConfigFromConfig
+}
+
+type ruleGroupFromConfig struct {
+	OrgID     int64
+	OrgName   string
+	FolderUID string
+	Group     apimodels.PostableRuleGroupConfig
+	// Checksum identifies the content of the rule group in the file.
+	Checksum string
+}
+
+type alertmanagerConfigFromConfig struct {
+	OrgID   int64
+	OrgName string
+	// Config holds the contact points, the notification policies and the templates of the organisation.
+	Config apimodels.PostableUserConfig
+	// Checksum identifies the content of the configuration in the file.
+	Checksum string
+}
+
+// alertingAsConfigV1 is the mapping for version 1 configs. This is mapped to its normalised version.
+type alertingAsConfigV1 struct {
+	APIVersion          values.Int64Value                 `json:"apiVersion" yaml:"apiVersion"`
+	RuleGroups          []*ruleGroupFromConfigV1          `json:"groups" yaml:"groups"`
+	AlertmanagerConfigs []*alertmanagerConfigFromConfigV1 `json:"alertmanagerConfigs" yaml:"alertmanagerConfigs"`
+}
+
+// ruleGroupFromConfigV1 is a rule group. Its rules use the format of the rules of the ruler API.
+type ruleGroupFromConfigV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	OrgName   values.StringValue `json:"orgName" yaml:"orgName"`
+	FolderUID values.StringValue `json:"folderUid" yaml:"folderUid"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	Interval  values.StringValue `json:"interval" yaml:"interval"`
+	Rules     []values.JSONValue `json:"rules" yaml:"rules"`
+}
+
+// alertmanagerConfigFromConfigV1 is the Alertmanager configuration of an organisation. Its config
+// uses the format of the Alertmanager configuration API.
+type alertmanagerConfigFromConfigV1 struct {
+	OrgID   values.Int64Value  `json:"orgId" yaml:"orgId"`
+	OrgName values.StringValue `json:"orgName" yaml:"orgName"`
+	Config  values.JSONValue   `json:"config" yaml:"config"`
+}
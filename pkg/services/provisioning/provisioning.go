@@ -4,10 +4,12 @@ import (
 	"context"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
@@ -17,6 +19,9 @@ import (
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
+// alertingPollInterval is how often the alerting provisioning files are checked for changes.
+var alertingPollInterval = 10 * time.Second
+
 func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginManager plugifaces.Manager) (
 	*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
@@ -28,6 +33,7 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginManager
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 	return s, nil
 }
@@ -39,6 +45,7 @@ type ProvisioningService interface {
 	ProvisionPlugins() error
 	ProvisionNotifications() error
 	ProvisionDashboards() error
+	ProvisionAlerting() error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -51,6 +58,7 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 }
 
@@ -81,7 +89,14 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers      func(string) error
 	provisionDatasources    func(string) error
 	provisionPlugins        func(string, plugifaces.Manager) error
+	provisionAlerting       func(string, *setting.Cfg, *sqlstore.SQLStore) error
 	mutex                   sync.Mutex
+
+	// alertingMutex serializes the provisioning of alerting, which can be triggered by a change
+	// of the files or through the admin API at the same time.
+	alertingMutex sync.Mutex
+	// alertingVersion is the version of the alerting provisioning files last provisioned.
+	alertingVersion string
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners() error {
@@ -110,6 +125,13 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		return err
 	}
 
+	// Alert rules are provisioned after the dashboards, as they are stored in folders.
+	if err := ps.ProvisionAlerting(); err != nil {
+		ps.log.Error("Failed to provision alerting", "error", err)
+		return err
+	}
+	go ps.pollAlertingChanges(ctx)
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -150,6 +172,50 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications() error {
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAlerting() error {
+	if !ps.Cfg.IsNgAlertEnabled() {
+		return nil
+	}
+
+	ps.alertingMutex.Lock()
+	defer ps.alertingMutex.Unlock()
+
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	// The version is recorded even if provisioning fails, so that the files are not
+	// provisioned again before they change.
+	ps.alertingVersion = alerting.ConfigVersion(alertingPath)
+	err := ps.provisionAlerting(alertingPath, ps.Cfg, ps.SQLStore)
+	return errutil.Wrap("Alerting provisioning error", err)
+}
+
+// pollAlertingChanges provisions alerting again when its provisioning files change, until ctx is done.
+func (ps *ProvisioningServiceImpl) pollAlertingChanges(ctx context.Context) {
+	if !ps.Cfg.IsNgAlertEnabled() {
+		return
+	}
+
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	ticker := time.NewTicker(alertingPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ps.alertingMutex.Lock()
+			changed := alerting.ConfigVersion(alertingPath) != ps.alertingVersion
+			ps.alertingMutex.Unlock()
+			if !changed {
+				continue
+			}
+			ps.log.Info("Alerting provisioning files changed, provisioning alerting")
+			if err := ps.ProvisionAlerting(); err != nil {
+				ps.log.Error("Failed to provision alerting", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(dashboardPath, ps.SQLStore)
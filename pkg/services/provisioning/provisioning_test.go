@@ -3,13 +3,18 @@ package provisioning
 import (
 	"context"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	dboards "github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProvisioningServiceImpl(t *testing.T) {
@@ -64,6 +69,57 @@ func TestProvisioningServiceImpl(t *testing.T) {
 	})
 }
 
+func TestProvisioningServiceImpl_Alerting(t *testing.T) {
+	setupAlerting := func(t *testing.T, provision func() error) (*serviceTestStruct, string) {
+		serviceTest := setup()
+		serviceTest.service.Cfg.FeatureToggles = map[string]bool{"ngalert": true}
+		serviceTest.service.Cfg.ProvisioningPath = t.TempDir()
+		alertingPath := filepath.Join(serviceTest.service.Cfg.ProvisioningPath, "alerting")
+		require.NoError(t, os.Mkdir(alertingPath, 0750))
+		serviceTest.service.provisionAlerting = func(string, *setting.Cfg, *sqlstore.SQLStore) error {
+			return provision()
+		}
+		return serviceTest, alertingPath
+	}
+
+	t.Run("Failed alerting provisioning stops the service", func(t *testing.T) {
+		serviceTest, _ := setupAlerting(t, func() error {
+			return errors.New("Test error")
+		})
+		serviceTest.startService()
+		serviceTest.waitForStop()
+
+		assert.False(t, serviceTest.serviceRunning, "Service should not be running")
+		assert.EqualError(t, serviceTest.serviceError, "Alerting provisioning error: Test error")
+	})
+
+	t.Run("Alerting is provisioned again when the files change", func(t *testing.T) {
+		defer func(interval time.Duration) { alertingPollInterval = interval }(alertingPollInterval)
+		alertingPollInterval = 10 * time.Millisecond
+
+		provisioned := make(chan struct{}, 10)
+		serviceTest, alertingPath := setupAlerting(t, func() error {
+			provisioned <- struct{}{}
+			return nil
+		})
+		serviceTest.startService()
+		defer serviceTest.cancel()
+		serviceTest.waitForPollChanges()
+		require.Len(t, provisioned, 1, "Alerting should have been provisioned on start")
+		<-provisioned
+
+		time.Sleep(5 * alertingPollInterval)
+		require.Len(t, provisioned, 0, "Alerting should not be provisioned again while the files do not change")
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(alertingPath, "rules.yaml"), []byte("apiVersion: 1"), 0600))
+		select {
+		case <-provisioned:
+		case <-time.After(time.Second):
+			t.Fatal("Alerting should have been provisioned again after the files changed")
+		}
+	})
+}
+
 type serviceTestStruct struct {
 	waitForPollChanges func()
 	waitForStop        func()
@@ -0,0 +1,38 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// errImportDryRun rolls back the transaction of a dry run import.
+var errImportDryRun = errors.New("dry run")
+
+// ImportDashboardAlerts imports the selected legacy dashboard alerts of an organisation into unified
+// alerting, with the same translation as the migration to unified alerting. A dry run does the import
+// and rolls it back, so that its report tells exactly what the import would change.
+func (ss *SQLStore) ImportDashboardAlerts(ctx context.Context, cmd ualert.ImportCommand) (*ualert.ImportReport, error) {
+	var report *ualert.ImportReport
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		mg := migrator.NewMigrator(ss.engine, ss.Cfg)
+		mg.Logger = log.New("sqlstore.dashboard-alert-import")
+
+		var err error
+		report, err = ualert.ImportDashAlerts(sess.Session, mg, cmd)
+		if err != nil {
+			return err
+		}
+		if cmd.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
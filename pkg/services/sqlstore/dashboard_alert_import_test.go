@@ -0,0 +1,180 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+)
+
+func TestImportDashboardAlerts(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	ctx := context.Background()
+
+	folder := insertTestDashboard(t, sqlStore, "folder", 1, 0, true)
+	dash := insertTestDashboard(t, sqlStore, "dashboard with alerts", 1, folder.Id, false)
+	other := insertTestDashboard(t, sqlStore, "other dashboard with alerts", 1, 0, false)
+
+	require.NoError(t, CreateAlertNotificationCommand(&models.CreateAlertNotificationCommand{
+		Uid:      "email",
+		Name:     "email",
+		Type:     "email",
+		OrgId:    1,
+		Settings: simplejson.NewFromAny(map[string]interface{}{"addresses": "ops@example.com"}),
+	}))
+
+	alertSettings := func(t *testing.T, queryParams string) *simplejson.Json {
+		t.Helper()
+		settings, err := simplejson.NewJson([]byte(`{
+			"conditions": [{
+				"evaluator": {"params": [3], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ` + queryParams + `, "datasourceId": 1, "model": {"refId": "A"}},
+				"reducer": {"type": "avg"}
+			}],
+			"noDataState": "no_data",
+			"executionErrorState": "alerting",
+			"notifications": [{"uid": "email"}]
+		}`))
+		require.NoError(t, err)
+		return settings
+	}
+	require.NoError(t, SaveAlerts(&models.SaveAlertsCommand{
+		DashboardId: dash.Id,
+		OrgId:       1,
+		UserId:      1,
+		Alerts: []*models.Alert{
+			{PanelId: 1, DashboardId: dash.Id, OrgId: 1, Name: "valid", Settings: alertSettings(t, `["A", "5m", "now"]`), Frequency: 60},
+			{PanelId: 2, DashboardId: dash.Id, OrgId: 1, Name: "invalid", Settings: alertSettings(t, `["A", "5m"]`), Frequency: 60},
+		},
+	}))
+	otherAlert := &models.Alert{PanelId: 1, DashboardId: other.Id, OrgId: 1, Name: "other", Settings: alertSettings(t, `["A", "5m", "now"]`), Frequency: 60}
+	require.NoError(t, SaveAlerts(&models.SaveAlertsCommand{
+		DashboardId: other.Id,
+		OrgId:       1,
+		UserId:      1,
+		Alerts:      []*models.Alert{otherAlert},
+	}))
+	require.NoError(t, PauseAlert(&models.PauseAlertCommand{OrgId: 1, AlertIds: []int64{otherAlert.Id}, Paused: true}))
+
+	count := func(t *testing.T, table string) int64 {
+		t.Helper()
+		var n int64
+		require.NoError(t, sqlStore.WithDbSession(ctx, func(sess *DBSession) error {
+			var err error
+			n, err = sess.Table(table).Count()
+			return err
+		}))
+		return n
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		report, err := sqlStore.ImportDashboardAlerts(ctx, ualert.ImportCommand{OrgID: 1, FolderUIDs: []string{folder.Uid}, DryRun: true})
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Len(t, report.Rules, 2)
+		require.Equal(t, 1, report.Count(ualert.ImportStatusCreated))
+		require.Equal(t, 1, report.Count(ualert.ImportStatusFailed))
+		require.Len(t, report.CreatedContactPoints, 2)
+		require.Equal(t, int64(0), count(t, "alert_rule"))
+		require.Equal(t, int64(0), count(t, "alert_configuration"))
+	})
+
+	var ruleUID string
+	t.Run("import of a folder", func(t *testing.T) {
+		report, err := sqlStore.ImportDashboardAlerts(ctx, ualert.ImportCommand{OrgID: 1, FolderUIDs: []string{folder.Uid}})
+		require.NoError(t, err)
+		require.Len(t, report.Rules, 2)
+		for _, r := range report.Rules {
+			switch r.AlertName {
+			case "valid":
+				require.Equal(t, ualert.ImportStatusCreated, r.Status)
+				require.Equal(t, folder.Uid, r.FolderUID)
+				require.NotEmpty(t, r.Receiver)
+				ruleUID = r.RuleUID
+			case "invalid":
+				require.Equal(t, ualert.ImportStatusFailed, r.Status)
+				require.Contains(t, r.Error, "unexpected number of query parameters")
+			default:
+				t.Fatalf("unexpected alert %s", r.AlertName)
+			}
+		}
+		require.Equal(t, int64(1), count(t, "alert_rule"))
+		require.Equal(t, int64(1), count(t, "alert_rule_version"))
+		require.Equal(t, int64(1), count(t, "alert_configuration"))
+	})
+
+	t.Run("import is idempotent", func(t *testing.T) {
+		report, err := sqlStore.ImportDashboardAlerts(ctx, ualert.ImportCommand{OrgID: 1, DashboardUIDs: []string{dash.Uid}})
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Count(ualert.ImportStatusSkipped))
+		require.Equal(t, 1, report.Count(ualert.ImportStatusFailed))
+		for _, r := range report.Rules {
+			if r.Status == ualert.ImportStatusSkipped {
+				require.Equal(t, ruleUID, r.RuleUID)
+			}
+		}
+		require.Empty(t, report.CreatedContactPoints)
+		require.Equal(t, int64(1), count(t, "alert_rule"))
+		require.Equal(t, int64(1), count(t, "alert_configuration"))
+	})
+
+	t.Run("import merges the Alertmanager configuration", func(t *testing.T) {
+		report, err := sqlStore.ImportDashboardAlerts(ctx, ualert.ImportCommand{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Count(ualert.ImportStatusCreated))
+		require.Equal(t, 1, report.Count(ualert.ImportStatusSkipped))
+		for _, r := range report.Rules {
+			require.Equal(t, r.AlertName == "other", r.Paused)
+		}
+		require.Equal(t, []string{ualert.GENERAL_FOLDER}, report.CreatedFolders)
+		// The receiver of the channel exists already.
+		require.Empty(t, report.CreatedContactPoints)
+		require.Equal(t, int64(2), count(t, "alert_rule"))
+		require.Equal(t, int64(2), count(t, "alert_configuration"))
+
+		latest := ualert.AlertConfiguration{}
+		require.NoError(t, sqlStore.WithDbSession(ctx, func(sess *DBSession) error {
+			_, err := sess.Desc("id").Limit(1).Get(&latest)
+			return err
+		}))
+		var cfg struct {
+			AlertmanagerConfig struct {
+				Route struct {
+					Routes []json.RawMessage `json:"routes"`
+				} `json:"route"`
+				Receivers []json.RawMessage `json:"receivers"`
+			} `json:"alertmanager_config"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(latest.AlertmanagerConfiguration), &cfg))
+		require.Len(t, cfg.AlertmanagerConfig.Route.Routes, 2)
+		require.Len(t, cfg.AlertmanagerConfig.Receivers, 2)
+	})
+
+	t.Run("rules of paused alerts are imported paused", func(t *testing.T) {
+		var rules []struct {
+			Title    string
+			IsPaused bool
+		}
+		require.NoError(t, sqlStore.WithDbSession(ctx, func(sess *DBSession) error {
+			return sess.SQL("SELECT title, is_paused FROM alert_rule").Find(&rules)
+		}))
+		require.Len(t, rules, 2)
+		for _, r := range rules {
+			require.Equal(t, r.Title == "other", r.IsPaused, r.Title)
+		}
+	})
+
+	t.Run("unknown dashboard", func(t *testing.T) {
+		_, err := sqlStore.ImportDashboardAlerts(ctx, ualert.ImportCommand{OrgID: 1, DashboardUIDs: []string{"unknown"}})
+		require.ErrorIs(t, err, ualert.ErrImportTargetNotFound)
+	})
+}
@@ -2,9 +2,9 @@ package migrations
 
 import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 
-// For now disable migration. For now we are using local cache as storage to evaluate ideas.
-// This will be turned on soon though.
 func addLiveChannelMigrations(mg *migrator.Migrator) {
+	// For now disable live message migration. For now we are using local cache as storage to evaluate ideas.
+	// This will be turned on soon though.
 	//liveMessage := migrator.Table{
 	//	Name: "live_message",
 	//	Columns: []*migrator.Column{
@@ -21,4 +21,99 @@ func addLiveChannelMigrations(mg *migrator.Migrator) {
 	//
 	//mg.AddMigration("create live message table", migrator.NewAddTableMigration(liveMessage))
 	//mg.AddMigration("add index live_message.org_id_channel_unique", migrator.NewAddIndexMigration(liveMessage, liveMessage.Indices[0]))
+
+	liveChannelRule := migrator.Table{
+		Name: "live_channel_rule",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "channel", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "channel"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live channel rule table", migrator.NewAddTableMigration(liveChannelRule))
+	mg.AddMigration("add index live_channel_rule.org_id_channel_unique", migrator.NewAddIndexMigration(liveChannelRule, liveChannelRule.Indices[0]))
+}
+
+// addLiveHAMigrations adds the tables of the database HA engine of Grafana Live, which
+// shares messages, presence and managed stream frames between Grafana instances.
+func addLiveHAMigrations(mg *migrator.Migrator) {
+	liveHAMessage := migrator.Table{
+		Name: "live_ha_message",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "payload", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create live ha message table", migrator.NewAddTableMigration(liveHAMessage))
+	mg.AddMigration("add index live_ha_message.created", migrator.NewAddIndexMigration(liveHAMessage, liveHAMessage.Indices[0]))
+
+	liveHAPresence := migrator.Table{
+		Name: "live_ha_presence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "channel", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "client_id", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "info", Type: migrator.DB_Text, Nullable: false},
+			{Name: "expires", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"channel", "client_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create live ha presence table", migrator.NewAddTableMigration(liveHAPresence))
+	mg.AddMigration("add index live_ha_presence.channel_client_id_unique", migrator.NewAddIndexMigration(liveHAPresence, liveHAPresence.Indices[0]))
+	mg.AddMigration("add index live_ha_presence.expires", migrator.NewAddIndexMigration(liveHAPresence, liveHAPresence.Indices[1]))
+
+	liveHAFrame := migrator.Table{
+		Name: "live_ha_frame",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "channel", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "frame_schema", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "frame", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "channel"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"updated"}},
+		},
+	}
+
+	mg.AddMigration("create live ha frame table", migrator.NewAddTableMigration(liveHAFrame))
+	mg.AddMigration("add index live_ha_frame.org_id_channel_unique", migrator.NewAddIndexMigration(liveHAFrame, liveHAFrame.Indices[0]))
+	mg.AddMigration("add index live_ha_frame.updated", migrator.NewAddIndexMigration(liveHAFrame, liveHAFrame.Indices[1]))
+
+	liveHAFrameHistory := migrator.Table{
+		Name: "live_ha_frame_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "channel", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "frame", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "channel"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create live ha frame history table", migrator.NewAddTableMigration(liveHAFrameHistory))
+	mg.AddMigration("add index live_ha_frame_history.org_id_channel", migrator.NewAddIndexMigration(liveHAFrameHistory, liveHAFrameHistory.Indices[0]))
+	mg.AddMigration("add index live_ha_frame_history.created", migrator.NewAddIndexMigration(liveHAFrameHistory, liveHAFrameHistory.Indices[1]))
 }
@@ -53,6 +53,7 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	}
 	ualert.RerunDashAlertMigration(mg)
 	addKVStoreMigrations(mg)
+	addLiveHAMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
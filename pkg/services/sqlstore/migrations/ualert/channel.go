@@ -191,9 +191,12 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 			}
 		}
 
-		if ruleUid == "default_route" {
+		switch {
+		case ruleUid == "default_route":
 			receiverName = "autogen-contact-point-default"
-		} else {
+		case m.receiverName != nil:
+			receiverName = m.receiverName(chanKey)
+		default:
 			m.lastReceiverID++
 			receiverName = fmt.Sprintf("autogen-contact-point-%d", m.lastReceiverID)
 		}
@@ -365,8 +368,11 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 	return settings, ss
 }
 
+// RuleUIDLabel is the label of the migrated alert rules that routes and silences their alerts.
+const RuleUIDLabel = "rule_uid"
+
 func getLabelForRouteMatching(ruleUID string) (string, string) {
-	return "rule_uid", ruleUID
+	return RuleUIDLabel, ruleUID
 }
 
 func extractChannelIDs(d dashAlert) (channelUids []interface{}) {
@@ -0,0 +1,513 @@
+package ualert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// ErrImportTargetNotFound is returned when a dashboard or folder selected for an import does not exist.
+var ErrImportTargetNotFound = errors.New("dashboard or folder not found")
+
+const (
+	// ImportStatusCreated is the status of the alerts imported as new alert rules.
+	ImportStatusCreated = "created"
+	// ImportStatusSkipped is the status of the alerts already imported, or migrated, to unified alerting.
+	ImportStatusSkipped = "skipped"
+	// ImportStatusFailed is the status of the alerts that cannot be translated.
+	ImportStatusFailed = "failed"
+)
+
+// ImportCommand selects the legacy dashboard alerts of an organisation to import into unified alerting.
+type ImportCommand struct {
+	OrgID int64
+	// DashboardUIDs and FolderUIDs select the dashboards whose alerts are imported.
+	// Every dashboard of the organisation is selected if both are empty.
+	DashboardUIDs []string
+	FolderUIDs    []string
+	// DryRun makes the import report what it would do, without changing anything.
+	DryRun bool
+}
+
+// ImportReport is the outcome of an import of legacy dashboard alerts, or what it would be for a dry run.
+type ImportReport struct {
+	OrgID  int64          `json:"orgId"`
+	DryRun bool           `json:"dryRun"`
+	Rules  []ImportedRule `json:"rules"`
+	// CreatedFolders are the titles of the folders created for the imported alert rules.
+	CreatedFolders []string `json:"createdFolders"`
+	// CreatedContactPoints are the names of the contact points created for the notification
+	// channels of the imported alerts.
+	CreatedContactPoints []string `json:"createdContactPoints"`
+}
+
+// Count returns the number of alerts with the given status.
+func (r *ImportReport) Count(status string) int {
+	n := 0
+	for _, rule := range r.Rules {
+		if rule.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// ImportedRule is the outcome of the import of a legacy dashboard alert.
+type ImportedRule struct {
+	AlertID      int64  `json:"alertId"`
+	AlertName    string `json:"alertName"`
+	DashboardUID string `json:"dashboardUid"`
+	PanelID      int64  `json:"panelId"`
+	Status       string `json:"status"`
+	RuleUID      string `json:"ruleUid,omitempty"`
+	FolderUID    string `json:"folderUid,omitempty"`
+	// Receiver is the contact point of the notification channels of the alert.
+	Receiver string `json:"receiver,omitempty"`
+	// Paused is true if the legacy alert is paused. The alert rule is then imported
+	// paused, so that it is not evaluated until it is resumed.
+	Paused bool   `json:"paused,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportDashAlerts imports the selected legacy dashboard alerts of an organisation into unified
+// alerting, with the same translation as the migration. Unlike the migration, it can run several
+// times: the alerts already imported are skipped, and the receivers and routes of the imported
+// alerts are added to the existing Alertmanager configuration of the organisation.
+// It should be called from inside a transaction.
+func ImportDashAlerts(sess *xorm.Session, mg *migrator.Migrator, cmd ImportCommand) (*ImportReport, error) {
+	m := &migration{
+		sess:                      sess,
+		mg:                        mg,
+		seenChannelUIDs:           make(map[string]struct{}),
+		migratedChannelsPerOrg:    make(map[int64]map[*notificationChannel]struct{}),
+		portedChannelGroupsPerOrg: make(map[int64]map[string]string),
+		receiverName:              importedReceiverName,
+	}
+	return m.importDashAlerts(cmd)
+}
+
+func (m *migration) importDashAlerts(cmd ImportCommand) (*ImportReport, error) {
+	report := &ImportReport{
+		OrgID:                cmd.OrgID,
+		DryRun:               cmd.DryRun,
+		Rules:                []ImportedRule{},
+		CreatedFolders:       []string{},
+		CreatedContactPoints: []string{},
+	}
+
+	selected, err := m.selectDashboards(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	dashAlerts, err := m.slurpDashAlerts()
+	if err != nil {
+		return nil, err
+	}
+
+	// [orgID, dataSourceId] -> UID
+	dsIDMap, err := m.slurpDSIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	// alertID -> ruleUID
+	imported, err := m.slurpImportedAlertIDs(cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	allChannelsPerOrg, defaultChannelsPerOrg, err := m.getNotificationChannelMap()
+	if err != nil {
+		return nil, err
+	}
+	allChannels, defaultChannels := allChannelsPerOrg[cmd.OrgID], defaultChannelsPerOrg[cmd.OrgID]
+
+	var (
+		receivers []*PostableApiReceiver
+		routes    []*Route
+	)
+	for _, da := range dashAlerts {
+		dash, ok := selected[da.DashboardId]
+		if da.OrgId != cmd.OrgID || !ok {
+			continue
+		}
+		da.DashboardUID = dash.Uid
+
+		result := ImportedRule{
+			AlertID:      da.Id,
+			AlertName:    da.Name,
+			DashboardUID: da.DashboardUID,
+			PanelID:      da.PanelId,
+			Paused:       da.State == "paused",
+		}
+		if ruleUID, ok := imported[da.Id]; ok {
+			result.Status = ImportStatusSkipped
+			result.RuleUID = ruleUID
+			report.Rules = append(report.Rules, result)
+			continue
+		}
+
+		// Translate the alert before creating any folder for it.
+		rule, err := m.translateDashAlert(da, dsIDMap)
+		if err != nil {
+			result.Status = ImportStatusFailed
+			result.Error = err.Error()
+			report.Rules = append(report.Rules, result)
+			continue
+		}
+
+		folder, err := m.importFolder(dash, da, report)
+		if err != nil {
+			return nil, MigrationError{Err: err, AlertId: da.Id}
+		}
+		rule.NamespaceUID = folder.Uid
+
+		if err := m.insertImportedRule(rule, result.Paused); err != nil {
+			return nil, MigrationError{Err: err, AlertId: da.Id}
+		}
+
+		if allChannels != nil {
+			recv, route, err := m.makeReceiverAndRoute(rule.UID, cmd.OrgID, extractChannelIDs(da), defaultChannels, allChannels)
+			if err != nil {
+				return nil, err
+			}
+			if recv != nil {
+				receivers = append(receivers, recv)
+			}
+			if route != nil {
+				routes = append(routes, route)
+				result.Receiver = route.Receiver
+			}
+		}
+
+		result.Status = ImportStatusCreated
+		result.RuleUID = rule.UID
+		result.FolderUID = folder.Uid
+		report.Rules = append(report.Rules, result)
+	}
+
+	created, err := m.writeImportedAlertmanagerConfig(cmd.OrgID, receivers, routes, allChannels, defaultChannels)
+	if err != nil {
+		return nil, err
+	}
+	report.CreatedContactPoints = append(report.CreatedContactPoints, created...)
+
+	return report, nil
+}
+
+// selectDashboards returns the dashboards selected by the command, by ID.
+func (m *migration) selectDashboards(cmd ImportCommand) (map[int64]dashboard, error) {
+	dashboards := []dashboard{}
+	err := m.sess.Cols("id", "uid", "org_id", "folder_id", "is_folder", "has_acl").Where("org_id = ?", cmd.OrgID).Find(&dashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]dashboard, len(dashboards))
+	for _, d := range dashboards {
+		byUID[d.Uid] = d
+	}
+
+	selected := make(map[int64]dashboard)
+	if len(cmd.DashboardUIDs) == 0 && len(cmd.FolderUIDs) == 0 {
+		for _, d := range dashboards {
+			if !d.IsFolder {
+				selected[d.Id] = d
+			}
+		}
+		return selected, nil
+	}
+
+	for _, uid := range cmd.DashboardUIDs {
+		d, ok := byUID[uid]
+		if !ok || d.IsFolder {
+			return nil, fmt.Errorf("%w: dashboard %q", ErrImportTargetNotFound, uid)
+		}
+		selected[d.Id] = d
+	}
+	for _, uid := range cmd.FolderUIDs {
+		f, ok := byUID[uid]
+		if !ok || !f.IsFolder {
+			return nil, fmt.Errorf("%w: folder %q", ErrImportTargetNotFound, uid)
+		}
+		for _, d := range dashboards {
+			if d.FolderId == f.Id && !d.IsFolder {
+				selected[d.Id] = d
+			}
+		}
+	}
+	return selected, nil
+}
+
+// slurpImportedAlertIDs returns a map of alertID -> ruleUID of the legacy alerts
+// of the organisation that are already imported, or migrated, to unified alerting.
+func (m *migration) slurpImportedAlertIDs(orgID int64) (map[int64]string, error) {
+	rules := []struct {
+		UID         string            `xorm:"uid"`
+		Annotations map[string]string `xorm:"annotations"`
+	}{}
+	if err := m.sess.SQL(`SELECT uid, annotations FROM alert_rule WHERE org_id = ?`, orgID).Find(&rules); err != nil {
+		return nil, err
+	}
+
+	imported := make(map[int64]string, len(rules))
+	for _, r := range rules {
+		id, err := strconv.ParseInt(r.Annotations["__alertId__"], 10, 64)
+		if err != nil {
+			// The rule is not made from a legacy alert.
+			continue
+		}
+		imported[id] = r.UID
+	}
+	return imported, nil
+}
+
+// translateDashAlert translates the legacy alert to an alert rule without folder.
+func (m *migration) translateDashAlert(da dashAlert, dsIDMap dsUIDLookup) (*alertRule, error) {
+	newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
+	if err != nil {
+		return nil, err
+	}
+	return m.makeAlertRule(*newCond, da, "")
+}
+
+// importFolder returns the folder of the rule of the imported alert, as the migration does, except that
+// the folders created by previous imports are reused.
+func (m *migration) importFolder(dash dashboard, da dashAlert, report *ImportReport) (*dashboard, error) {
+	var (
+		folder  *dashboard
+		created bool
+		err     error
+	)
+	switch {
+	case dash.HasAcl:
+		// create folder and assign the permissions of the dashboard (included default and inherited)
+		folder, created, err = m.getOrCreateFolder(dash.OrgId, fmt.Sprintf(DASHBOARD_FOLDER, getMigrationString(da)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create folder: %w", err)
+		}
+		if created {
+			permissions, err := m.getACL(dash.OrgId, dash.Id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dash.Id, dash.OrgId, err)
+			}
+			if err := m.setACL(folder.OrgId, folder.Id, permissions); err != nil {
+				return nil, fmt.Errorf("failed to set folder %d under organisation %d permissions: %w", folder.Id, folder.OrgId, err)
+			}
+		}
+	case dash.FolderId > 0:
+		// link the new rule to the existing folder
+		f, err := m.getFolder(dash, da)
+		if err != nil {
+			return nil, err
+		}
+		folder = &f
+	default:
+		folder, created, err = m.getOrCreateFolder(dash.OrgId, GENERAL_FOLDER)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get or create general folder under organisation %d: %w", dash.OrgId, err)
+		}
+	}
+
+	if folder.Uid == "" {
+		return nil, fmt.Errorf("empty folder identifier")
+	}
+	if created {
+		report.CreatedFolders = append(report.CreatedFolders, folder.Title)
+	}
+	return folder, nil
+}
+
+// insertImportedRule inserts the rule and its first version. The title is made unique in the folder
+// beforehand, since a failed insert aborts the transaction with some databases.
+func (m *migration) insertImportedRule(rule *alertRule, paused bool) error {
+	exists, err := m.sess.Table("alert_rule").Where("org_id = ? AND namespace_uid = ? AND title = ?", rule.OrgID, rule.NamespaceUID, rule.Title).Exist()
+	if err != nil {
+		return err
+	}
+	if exists {
+		rule.Title += fmt.Sprintf(" %v", rule.UID)
+		rule.RuleGroup += fmt.Sprintf(" %v", rule.UID)
+	}
+
+	if _, err := m.sess.Insert(rule); err != nil {
+		return err
+	}
+
+	// create entry in alert_rule_version
+	if _, err := m.sess.Insert(rule.makeVersion()); err != nil {
+		return err
+	}
+
+	if paused {
+		// is_paused is not part of alertRule, which is shared with the migration that never pauses rules.
+		_, err = m.sess.Exec("UPDATE alert_rule SET is_paused = ? WHERE org_id = ? AND uid = ?", true, rule.OrgID, rule.UID)
+	}
+	return err
+}
+
+// writeImportedAlertmanagerConfig saves a new version of the Alertmanager configuration of the
+// organisation, with the receivers and routes of the imported alerts. It returns the names of the
+// receivers added to the configuration.
+func (m *migration) writeImportedAlertmanagerConfig(orgID int64, receivers []*PostableApiReceiver, routes []*Route, allChannels map[interface{}]*notificationChannel, defaultChannels []*notificationChannel) ([]string, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	latest := AlertConfiguration{}
+	has, err := m.sess.Where("org_id = ?", orgID).Desc("id").Limit(1).Get(&latest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !has {
+		// Unified alerting has never run for the organisation, the configuration is made as the migration does.
+		amConfigs := make(amConfigsPerOrg, 1)
+		err := m.addDefaultChannels(amConfigs, channelsPerOrg{orgID: allChannels}, defaultChannelsPerOrg{orgID: defaultChannels})
+		if err != nil {
+			return nil, err
+		}
+		amConfig := amConfigs[orgID]
+		amConfig.AlertmanagerConfig.Receivers = append(amConfig.AlertmanagerConfig.Receivers, receivers...)
+		amConfig.AlertmanagerConfig.Route.Routes = append(amConfig.AlertmanagerConfig.Route.Routes, routes...)
+		if err := m.writeAlertmanagerConfig(orgID, amConfig, allChannels); err != nil {
+			return nil, err
+		}
+		return receiverNames(amConfig.AlertmanagerConfig.Receivers), nil
+	}
+
+	existing, err := existingReceiverNames(latest.AlertmanagerConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	// The receivers are named after their channels, the existing ones come from previous imports.
+	newReceivers := make([]*PostableApiReceiver, 0, len(receivers))
+	for _, r := range receivers {
+		if _, ok := existing[r.Name]; !ok {
+			newReceivers = append(newReceivers, r)
+		}
+	}
+
+	amConfig := &PostableUserConfig{AlertmanagerConfig: PostableApiAlertingConfig{Receivers: newReceivers}}
+	if err := amConfig.EncryptSecureSettings(); err != nil {
+		return nil, err
+	}
+	rawAmConfig, err := mergeAlertmanagerConfig(latest.AlertmanagerConfiguration, newReceivers, routes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = m.sess.Insert(AlertConfiguration{
+		AlertmanagerConfiguration: rawAmConfig,
+		ConfigurationVersion:      latest.ConfigurationVersion,
+		OrgID:                     orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receiverNames(newReceivers), nil
+}
+
+// importedReceiverName names the receivers after the channels they are made of,
+// so that an import reuses the receivers created by the previous ones.
+func importedReceiverName(chanKey string) string {
+	h := sha256.Sum256([]byte(chanKey))
+	return "autogen-contact-point-" + hex.EncodeToString(h[:])[:10]
+}
+
+func receiverNames(receivers []*PostableApiReceiver) []string {
+	names := make([]string, 0, len(receivers))
+	for _, r := range receivers {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// existingReceiverNames returns the names of the receivers of a raw Alertmanager configuration.
+func existingReceiverNames(raw string) (map[string]struct{}, error) {
+	var cfg struct {
+		AlertmanagerConfig struct {
+			Receivers []struct {
+				Name string `json:"name"`
+			} `json:"receivers"`
+		} `json:"alertmanager_config"`
+	}
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Alertmanager configuration: %w", err)
+	}
+	names := make(map[string]struct{}, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		names[r.Name] = struct{}{}
+	}
+	return names, nil
+}
+
+// mergeAlertmanagerConfig adds receivers and routes to a raw Alertmanager configuration. The rest of
+// the configuration is kept as it is, since this snapshot of the configuration types lacks most fields.
+func mergeAlertmanagerConfig(raw string, receivers []*PostableApiReceiver, routes []*Route) (string, error) {
+	var cfg, amCfg, route map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse Alertmanager configuration: %w", err)
+	}
+	if err := json.Unmarshal(cfg["alertmanager_config"], &amCfg); err != nil || amCfg == nil {
+		return "", fmt.Errorf("failed to parse Alertmanager configuration: no alertmanager_config")
+	}
+	if err := json.Unmarshal(amCfg["route"], &route); err != nil || route == nil {
+		return "", fmt.Errorf("failed to parse Alertmanager configuration: no root route")
+	}
+
+	var existingRoutes, existingReceivers []json.RawMessage
+	if r, ok := route["routes"]; ok {
+		if err := json.Unmarshal(r, &existingRoutes); err != nil {
+			return "", fmt.Errorf("failed to parse Alertmanager configuration routes: %w", err)
+		}
+	}
+	if r, ok := amCfg["receivers"]; ok {
+		if err := json.Unmarshal(r, &existingReceivers); err != nil {
+			return "", fmt.Errorf("failed to parse Alertmanager configuration receivers: %w", err)
+		}
+	}
+
+	// The routes of the imported alerts go first, so that no existing route catches their alerts.
+	newRoutes := make([]interface{}, 0, len(routes)+len(existingRoutes))
+	for _, r := range routes {
+		newRoutes = append(newRoutes, r)
+	}
+	for _, r := range existingRoutes {
+		newRoutes = append(newRoutes, r)
+	}
+	newReceivers := make([]interface{}, 0, len(existingReceivers)+len(receivers))
+	for _, r := range existingReceivers {
+		newReceivers = append(newReceivers, r)
+	}
+	for _, r := range receivers {
+		newReceivers = append(newReceivers, r)
+	}
+
+	var err error
+	if route["routes"], err = json.Marshal(newRoutes); err != nil {
+		return "", err
+	}
+	if amCfg["route"], err = json.Marshal(route); err != nil {
+		return "", err
+	}
+	if amCfg["receivers"], err = json.Marshal(newReceivers); err != nil {
+		return "", err
+	}
+	if cfg["alertmanager_config"], err = json.Marshal(amCfg); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
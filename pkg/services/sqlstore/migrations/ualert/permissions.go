@@ -43,22 +43,29 @@ type dashboardAcl struct {
 // getOrCreateGeneralFolder returns the general folder under the specific organisation
 // If the general folder does not exist it creates it.
 func (m *migration) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
+	folder, _, err := m.getOrCreateFolder(orgID, GENERAL_FOLDER)
+	return folder, err
+}
+
+// getOrCreateFolder returns the folder with the given title under the specific organisation,
+// and creates it if it does not exist. The returned bool is true if the folder is created.
+func (m *migration) getOrCreateFolder(orgID int64, title string) (*dashboard, bool, error) {
 	// there is a unique constraint on org_id, folder_id, title
 	// there are no nested folders so the parent folder id is always 0
-	dashboard := dashboard{OrgId: orgID, FolderId: 0, Title: GENERAL_FOLDER}
+	dashboard := dashboard{OrgId: orgID, FolderId: 0, Title: title}
 	has, err := m.sess.Get(&dashboard)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	} else if !has {
 		// create folder
-		result, err := m.createFolder(orgID, GENERAL_FOLDER)
+		result, err := m.createFolder(orgID, title)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
-		return result, nil
+		return result, true, nil
 	}
-	return &dashboard, nil
+	return &dashboard, false, nil
 }
 
 // returns the folder of the given dashboard (if exists)
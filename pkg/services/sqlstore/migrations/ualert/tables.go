@@ -22,6 +22,21 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Admin Configuration
 	AddAlertAdminConfigMigrations(mg)
+
+	// Create alert_state_history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create alert_scheduler_instance table
+	AddAlertSchedulerInstanceMigrations(mg)
+
+	// Create alert_provisioning table
+	AddAlertProvisioningMigrations(mg)
+
+	// Create alert_notification_delivery table
+	AddAlertNotificationDeliveryMigrations(mg)
+
+	// Create alert_silence_schedule table
+	AddAlertSilenceScheduleMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -204,6 +219,19 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 	mg.AddMigration("add index in alert_rule on org_id, namespase_uid and title columns", migrator.NewAddIndexMigration(alertRule, &migrator.Index{
 		Cols: []string{"org_id", "namespace_uid", "title"}, Type: migrator.UniqueIndex,
 	}))
+
+	// add record column, the metric name of recording rules
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: true}))
+
+	// add depends_on column
+	mg.AddMigration("add column depends_on to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "depends_on", Type: migrator.DB_Text, Nullable: true}))
+
+	// add evaluation column, the evaluation settings of the rule
+	mg.AddMigration("add column evaluation to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "evaluation", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_paused and paused_until columns, they are not versioned as pausing a rule does not change it
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+	mg.AddMigration("add column paused_until to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "paused_until", Type: migrator.DB_DateTime, Nullable: true}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -247,6 +275,15 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add record column
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: true}))
+
+	// add depends_on column
+	mg.AddMigration("add column depends_on to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "depends_on", Type: migrator.DB_Text, Nullable: true}))
+
+	// add evaluation column
+	mg.AddMigration("add column evaluation to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "evaluation", Type: migrator.DB_Text, Nullable: true}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -297,3 +334,122 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create_ngalert_configuration_table", migrator.NewAddTableMigration(adminConfiguration))
 	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
 }
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "evaluation_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"rule_org_id", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on rule_org_id and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
+
+func AddAlertSchedulerInstanceMigrations(mg *migrator.Migrator) {
+	schedulerInstance := migrator.Table{
+		Name: "alert_scheduler_instance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "instance_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "last_heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"instance_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_instance table", migrator.NewAddTableMigration(schedulerInstance))
+	mg.AddMigration("add unique index in alert_scheduler_instance on instance_id column", migrator.NewAddIndexMigration(schedulerInstance, schedulerInstance.Indices[0]))
+}
+
+func AddAlertProvisioningMigrations(mg *migrator.Migrator) {
+	alertProvisioning := migrator.Table{
+		Name: "alert_provisioning",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "object_type", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "object_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "provisioner", Type: migrator.DB_Text, Nullable: false},
+			{Name: "checksum", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "object_type", "object_key"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_provisioning table", migrator.NewAddTableMigration(alertProvisioning))
+	mg.AddMigration("add unique index in alert_provisioning on org_id, object_type and object_key columns", migrator.NewAddIndexMigration(alertProvisioning, alertProvisioning.Indices[0]))
+}
+
+func AddAlertNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	notificationDelivery := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "fingerprints", Type: migrator.DB_Text, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(notificationDelivery))
+	mg.AddMigration("add index in alert_notification_delivery on org_id and sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[0]))
+	mg.AddMigration("add index in alert_notification_delivery on sent_at column", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[1]))
+}
+
+func AddAlertSilenceScheduleMigrations(mg *migrator.Migrator) {
+	silenceSchedule := migrator.Table{
+		Name: "alert_silence_schedule",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "cron", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "time_intervals", Type: migrator.DB_Text, Nullable: true},
+			{Name: "location", Type: migrator.DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_silence_schedule table", migrator.NewAddTableMigration(silenceSchedule))
+	mg.AddMigration("add unique index in alert_silence_schedule on org_id and uid columns", migrator.NewAddIndexMigration(silenceSchedule, silenceSchedule.Indices[0]))
+}
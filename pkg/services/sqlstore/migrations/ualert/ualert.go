@@ -153,6 +153,9 @@ type migration struct {
 	silences                  []*pb.MeshSilence
 	portedChannelGroupsPerOrg map[int64]map[string]string // Org -> Channel group key -> receiver name.
 	lastReceiverID            int                         // For the auto generated receivers.
+	// receiverName names the receiver of a new group of channels, by channel group key.
+	// If it is nil, the receivers are numbered with lastReceiverID.
+	receiverName func(chanKey string) string
 }
 
 func (m *migration) SQL(dialect migrator.Dialect) string {
@@ -147,6 +147,12 @@ func (ss *SQLStore) Sync() error {
 	return ss.engine.Sync2()
 }
 
+// ConnectionString returns the connection string of the database. It allows opening
+// connections outside of the pool, e.g. to listen to PostgreSQL notifications.
+func (ss *SQLStore) ConnectionString() string {
+	return ss.engine.DataSourceName()
+}
+
 // Reset resets database state.
 // If default org and user creation is enabled, it will be ensured they exist in the database.
 func (ss *SQLStore) Reset() error {
@@ -401,6 +401,12 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryMaxFrames is a maximum number of recent frames kept per managed
+	// stream channel. 0 disables history.
+	LiveHistoryMaxFrames int
+	// LiveHistoryMaxAge is how long frames of managed stream channels are kept
+	// in history.
+	LiveHistoryMaxAge time.Duration
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -417,6 +423,25 @@ type Cfg struct {
 
 	// Unified Alerting
 	AdminConfigPollInterval time.Duration
+	StateHistoryMaxAge      time.Duration
+	NotificationLogMaxAge   time.Duration
+
+	// Recording rules
+	RecordingRulesOutput              string
+	RecordingRulesRemoteWriteURL      string
+	RecordingRulesRemoteWriteUser     string
+	RecordingRulesRemoteWritePassword string
+
+	// Sharding of the alert rules among the instances of a cluster
+	HAShardingEnabled  bool
+	HAInstanceID       string
+	HAHeartbeatTimeout time.Duration
+
+	// Evaluation of the alert rules, alert rules can override these settings
+	EvaluationTimeout       time.Duration
+	EvaluationMaxAttempts   int64
+	EvaluationRetryBackoff  time.Duration
+	EvaluationSkipIfRunning bool
 }
 
 // IsLiveConfigEnabled returns true if live should be able to save configs to SQL tables
@@ -1386,6 +1411,59 @@ func (cfg *Cfg) readUnifiedAlertingSettings(iniFile *ini.File) error {
 	ua := iniFile.Section("unified_alerting")
 	s := ua.Key("admin_config_poll_interval_seconds").MustInt(60)
 	cfg.AdminConfigPollInterval = time.Second * time.Duration(s)
+
+	maxAge, err := gtime.ParseDuration(valueAsString(ua, "state_history_max_age", "30d"))
+	if err != nil {
+		return fmt.Errorf("invalid value for state_history_max_age: %w", err)
+	}
+	cfg.StateHistoryMaxAge = maxAge
+
+	notificationLogMaxAge, err := gtime.ParseDuration(valueAsString(ua, "notification_log_max_age", "7d"))
+	if err != nil {
+		return fmt.Errorf("invalid value for notification_log_max_age: %w", err)
+	}
+	cfg.NotificationLogMaxAge = notificationLogMaxAge
+
+	cfg.RecordingRulesOutput = valueAsString(ua, "recording_rules_output", "live")
+	if cfg.RecordingRulesOutput != "live" && cfg.RecordingRulesOutput != "remote_write" {
+		return fmt.Errorf("invalid value for recording_rules_output: %q, expected live or remote_write", cfg.RecordingRulesOutput)
+	}
+	cfg.RecordingRulesRemoteWriteURL = valueAsString(ua, "recording_rules_remote_write_url", "")
+	if cfg.RecordingRulesOutput == "remote_write" && cfg.RecordingRulesRemoteWriteURL == "" {
+		return errors.New("recording_rules_remote_write_url is required when recording_rules_output is remote_write")
+	}
+	cfg.RecordingRulesRemoteWriteUser = valueAsString(ua, "recording_rules_remote_write_user", "")
+	cfg.RecordingRulesRemoteWritePassword = valueAsString(ua, "recording_rules_remote_write_password", "")
+
+	cfg.HAShardingEnabled = ua.Key("ha_sharding_enabled").MustBool(false)
+	cfg.HAInstanceID = valueAsString(ua, "ha_instance_id", InstanceName)
+	heartbeatTimeout, err := gtime.ParseDuration(valueAsString(ua, "ha_heartbeat_timeout", "1m"))
+	if err != nil {
+		return fmt.Errorf("invalid value for ha_heartbeat_timeout: %w", err)
+	}
+	if cfg.HAShardingEnabled && heartbeatTimeout <= 0 {
+		return errors.New("ha_heartbeat_timeout must be greater than 0 when ha_sharding_enabled is true")
+	}
+	cfg.HAHeartbeatTimeout = heartbeatTimeout
+
+	evaluationTimeout, err := gtime.ParseDuration(valueAsString(ua, "evaluation_timeout", "30s"))
+	if err != nil {
+		return fmt.Errorf("invalid value for evaluation_timeout: %w", err)
+	}
+	if evaluationTimeout <= 0 {
+		return errors.New("evaluation_timeout must be greater than 0")
+	}
+	cfg.EvaluationTimeout = evaluationTimeout
+	cfg.EvaluationMaxAttempts = ua.Key("max_attempts").MustInt64(3)
+	if cfg.EvaluationMaxAttempts < 1 {
+		return errors.New("max_attempts must be at least 1")
+	}
+	retryBackoff, err := gtime.ParseDuration(valueAsString(ua, "retry_backoff", "1s"))
+	if err != nil {
+		return fmt.Errorf("invalid value for retry_backoff: %w", err)
+	}
+	cfg.EvaluationRetryBackoff = retryBackoff
+	cfg.EvaluationSkipIfRunning = ua.Key("skip_if_running").MustBool(false)
 	return nil
 }
 
@@ -1523,7 +1601,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngine = section.Key("ha_engine").MustString("")
 	switch cfg.LiveHAEngine {
-	case "", "redis":
+	case "", "redis", "database":
 	default:
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
@@ -1543,5 +1621,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveHistoryMaxFrames = section.Key("history_max_frames").MustInt(100)
+	if cfg.LiveHistoryMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_max_frames", cfg.LiveHistoryMaxFrames)
+	}
+	cfg.LiveHistoryMaxAge = section.Key("history_max_age").MustDuration(5 * time.Minute)
+	if cfg.LiveHistoryMaxAge <= 0 {
+		return fmt.Errorf("unexpected value %s for [live] history_max_age", cfg.LiveHistoryMaxAge)
+	}
 	return nil
 }
@@ -93,6 +93,7 @@ export const alertStateToState: Record<PromAlertingRuleState | GrafanaAlertState
   [GrafanaAlertState.NoData]: 'info',
   [GrafanaAlertState.Normal]: 'good',
   [GrafanaAlertState.Pending]: 'warning',
+  [GrafanaAlertState.Suppressed]: 'info',
 };
 
 export function getFirstActiveAt(promRule: AlertingRule) {
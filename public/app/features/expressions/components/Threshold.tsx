@@ -0,0 +1,105 @@
+import React, { FC, FormEvent } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+import { EvalFunction } from '../../alerting/state/alertDef';
+import { ExpressionQuery, ThresholdEvaluator } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
+  { value: EvalFunction.IsAbove, label: 'Is above' },
+  { value: EvalFunction.IsBelow, label: 'Is below' },
+  { value: EvalFunction.IsWithinRange, label: 'Is within range' },
+  { value: EvalFunction.IsOutsideRange, label: 'Is outside range' },
+];
+
+const defaultEvaluator: ThresholdEvaluator = { params: [0, 0], type: EvalFunction.IsAbove };
+
+export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const evaluator = query.evaluator ?? defaultEvaluator;
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onRecoveryToggle = (event: FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, recoveryEvaluator: event.currentTarget.checked ? { ...evaluator } : undefined });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <EvaluatorRow
+        label="Firing"
+        labelWidth={labelWidth}
+        evaluator={evaluator}
+        onChange={(value) => onChange({ ...query, evaluator: value })}
+      />
+      <InlineFieldRow>
+        <InlineField
+          label="Recovery"
+          labelWidth={labelWidth}
+          tooltip="Use a separate threshold to stop firing, so values close to the firing threshold do not flap"
+        >
+          <InlineSwitch value={Boolean(query.recoveryEvaluator)} onChange={onRecoveryToggle} />
+        </InlineField>
+      </InlineFieldRow>
+      {query.recoveryEvaluator && (
+        <EvaluatorRow
+          label="Stop firing"
+          labelWidth={labelWidth}
+          evaluator={query.recoveryEvaluator}
+          onChange={(value) => onChange({ ...query, recoveryEvaluator: value })}
+        />
+      )}
+    </>
+  );
+};
+
+interface EvaluatorRowProps {
+  label: string;
+  labelWidth: number;
+  evaluator: ThresholdEvaluator;
+  onChange: (evaluator: ThresholdEvaluator) => void;
+}
+
+const EvaluatorRow: FC<EvaluatorRowProps> = ({ label, labelWidth, evaluator, onChange }) => {
+  const isRange = evaluator.type === EvalFunction.IsWithinRange || evaluator.type === EvalFunction.IsOutsideRange;
+
+  const onTypeChange = (value: SelectableValue<EvalFunction>) => {
+    onChange({ ...evaluator, type: value.value! });
+  };
+
+  const onParamChange = (event: FormEvent<HTMLInputElement>, index: number) => {
+    const params = [...evaluator.params];
+    params[index] = parseFloat(event.currentTarget.value);
+    onChange({ ...evaluator, params });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label={label} labelWidth={labelWidth}>
+        <Select
+          menuShouldPortal
+          options={thresholdFunctions}
+          value={thresholdFunctions.find((f) => f.value === evaluator.type)}
+          onChange={onTypeChange}
+          width={20}
+        />
+      </InlineField>
+      <Input type="number" width={10} onChange={(event) => onParamChange(event, 0)} value={evaluator.params[0]} />
+      {isRange && (
+        <Input type="number" width={10} onChange={(event) => onParamChange(event, 1)} value={evaluator.params[1]} />
+      )}
+    </InlineFieldRow>
+  );
+};
@@ -6,6 +6,7 @@ export enum ExpressionQueryType {
   reduce = 'reduce',
   resample = 'resample',
   classic = 'classic_conditions',
+  threshold = 'threshold',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -13,6 +14,7 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
   { value: ExpressionQueryType.reduce, label: 'Reduce' },
   { value: ExpressionQueryType.resample, label: 'Resample' },
   { value: ExpressionQueryType.classic, label: 'Classic condition' },
+  { value: ExpressionQueryType.threshold, label: 'Threshold' },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -21,6 +23,37 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of all values' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile value' },
+];
+
+export enum ReducerMode {
+  Strict = '', // backend API wants an empty string to support "strict" mode
+  DropNonNumbers = 'dropNN',
+  ReplaceNonNumbers = 'replaceNN',
+}
+
+export const reducerMode: Array<SelectableValue<ReducerMode>> = [
+  {
+    value: ReducerMode.Strict,
+    label: 'Strict',
+    description: 'Result can be NaN if series contains non-numeric data',
+  },
+  {
+    value: ReducerMode.DropNonNumbers,
+    label: 'Drop Non-numeric Values',
+    description: 'Drop NaN and null values from input series before reducing',
+  },
+  {
+    value: ReducerMode.ReplaceNonNumbers,
+    label: 'Replace Non-numeric Values',
+    description: 'Replace NaN and null values with a constant value before reducing',
+  },
 ];
 
 export const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -48,6 +81,19 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  settings?: ExpressionQuerySettings;
+  evaluator?: ThresholdEvaluator;
+  recoveryEvaluator?: ThresholdEvaluator;
+}
+
+export interface ThresholdEvaluator {
+  params: number[];
+  type: EvalFunction;
+}
+
+export interface ExpressionQuerySettings {
+  mode?: ReducerMode;
+  replaceWithValue?: number;
 }
 export interface ClassicCondition {
   evaluator: {
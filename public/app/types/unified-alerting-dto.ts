@@ -17,6 +17,7 @@ export enum GrafanaAlertState {
   Pending = 'Pending',
   NoData = 'NoData',
   Error = 'Error',
+  Suppressed = 'Suppressed',
 }
 
 export enum PromRuleType {
@@ -31,6 +32,8 @@ interface PromRuleDTOBase {
   evaluationTime?: number;
   lastEvaluation?: string;
   lastError?: string;
+  paused?: boolean;
+  pausedUntil?: string;
 }
 
 export interface PromAlertingRuleDTO extends PromRuleDTOBase {
@@ -126,6 +129,8 @@ export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   uid: string;
   namespace_uid: string;
   namespace_id: number;
+  is_paused?: boolean;
+  paused_until?: string;
 }
 
 export interface RulerGrafanaRuleDTO {